@@ -0,0 +1,138 @@
+// Command schedparse runs a fitness schedule selector profile against a saved
+// HTML page and prints the events it extracts, for checking a profile before
+// pointing FITNESS_PROFILE_FILE at it.
+//
+//	go run ./cmd/schedparse -profile profile.json -date 2024-01-14 page.html
+package main
+
+import (
+    "encoding/json"
+    "errors"
+    "flag"
+    "fmt"
+    "io"
+    "os"
+    "strings"
+    "text/tabwriter"
+    "time"
+
+    "github.com/sjsu-badminton/badminton-discord-bot/internal/classify"
+    "github.com/sjsu-badminton/badminton-discord-bot/internal/scrape"
+    "github.com/sjsu-badminton/badminton-discord-bot/internal/store"
+)
+
+func main() {
+    if err := run(os.Args[1:], os.Stdin, os.Stdout); err != nil {
+        fmt.Fprintln(os.Stderr, "schedparse:", err)
+        os.Exit(1)
+    }
+}
+
+func run(args []string, stdin io.Reader, stdout io.Writer) error {
+    fs := flag.NewFlagSet("schedparse", flag.ContinueOnError)
+    profilePath := fs.String("profile", "", "selector profile JSON file (default: built-in profile)")
+    rulesPath := fs.String("rules", "", "event classification rules JSON file (default: built-in rules)")
+    all := fs.Bool("all", false, "print every entry, not only badminton events; entries the rules reject have no tags")
+    tz := fs.String("tz", "America/Los_Angeles", "time zone of the schedule")
+    date := fs.String("date", "", "date the page was saved, YYYY-MM-DD, for dates shown without a year (default: today)")
+    pageURL := fs.String("url", "https://fitness.sjsu.edu/Facility/GetSchedule", "URL the page was saved from, for relative links")
+    asJSON := fs.Bool("json", false, "print events as JSON")
+    fs.Usage = func() {
+        fmt.Fprintln(fs.Output(), "usage: schedparse [flags] page.html   (- reads stdin)")
+        fs.PrintDefaults()
+    }
+    if err := fs.Parse(args); err != nil {
+        if errors.Is(err, flag.ErrHelp) {
+            return nil
+        }
+        return err
+    }
+    if fs.NArg() != 1 {
+        fs.Usage()
+        return fmt.Errorf("expected one HTML file, got %d arguments", fs.NArg())
+    }
+
+    loc, err := time.LoadLocation(*tz)
+    if err != nil {
+        return fmt.Errorf("invalid -tz: %w", err)
+    }
+
+    now := time.Now().In(loc)
+    if *date != "" {
+        if now, err = time.ParseInLocation("2006-01-02", *date, loc); err != nil {
+            return fmt.Errorf("invalid -date: %w", err)
+        }
+    }
+
+    profile, err := scrape.LoadProfile(*profilePath)
+    if err != nil {
+        return err
+    }
+
+    rules, err := classify.Load(*rulesPath)
+    if err != nil {
+        return err
+    }
+    parseRules := rules
+    if *all {
+        if parseRules, err = classify.New(classify.Config{Include: classify.Match{Patterns: []string{"."}}}); err != nil {
+            return err
+        }
+    }
+
+    page := stdin
+    if name := fs.Arg(0); name != "-" {
+        f, err := os.Open(name)
+        if err != nil {
+            return err
+        }
+        defer f.Close()
+        page = f
+    }
+
+    events, err := scrape.ParseHTMLSchedule(page, scrape.HTMLOptions{
+        Profile:  profile,
+        Rules:    parseRules,
+        Location: loc,
+        Now:      now,
+        PageURL:  *pageURL,
+    })
+    if err != nil {
+        return err
+    }
+    if *all {
+        for i, e := range events {
+            events[i].Tags, _ = rules.Classify(e.Title, e.Location)
+        }
+    }
+
+    if *asJSON {
+        enc := json.NewEncoder(stdout)
+        enc.SetIndent("", "  ")
+        return enc.Encode(events)
+    }
+    return printEvents(stdout, events)
+}
+
+// printEvents writes events as an aligned table
+func printEvents(w io.Writer, events []store.Event) error {
+    if len(events) == 0 {
+        _, err := fmt.Fprintln(w, "No events found.")
+        return err
+    }
+
+    tw := tabwriter.NewWriter(w, 0, 0, 2, ' ', 0)
+    fmt.Fprintln(tw, "DATE\tTIME\tTITLE\tLOCATION\tTAGS\tREGISTER")
+    for _, e := range events {
+        fmt.Fprintf(tw, "%s\t%s - %s\t%s\t%s\t%s\t%s\n",
+            e.Start.Format("Mon Jan 2 2006"),
+            e.Start.Format("3:04 PM"),
+            e.End.Format("3:04 PM"),
+            e.Title,
+            e.Location,
+            strings.Join(e.Tags, ", "),
+            e.RegisterURL)
+    }
+    fmt.Fprintf(tw, "\n%d events\n", len(events))
+    return tw.Flush()
+}
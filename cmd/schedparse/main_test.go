@@ -0,0 +1,126 @@
+package main
+
+import (
+    "bytes"
+    "encoding/json"
+    "os"
+    "path/filepath"
+    "strings"
+    "testing"
+)
+
+const fixture = "../../internal/scrape/testdata/fitness_custom_markup.html"
+
+// fields collapses each output line's column padding to single spaces
+func fields(out string) []string {
+    var lines []string
+    for _, line := range strings.Split(out, "\n") {
+        lines = append(lines, strings.Join(strings.Fields(line), " "))
+    }
+    return lines
+}
+
+func TestRun(t *testing.T) {
+    profile := filepath.Join(t.TempDir(), "profile.json")
+    err := os.WriteFile(profile, []byte(`{
+        "rows": ["li.program"],
+        "title": ".program-name",
+        "location": ".where",
+        "time": ".when",
+        "link": "a.signup",
+        "dayHeader": ".day-label",
+        "defaultLocation": "SRAC"
+    }`), 0o644)
+    if err != nil {
+        t.Fatal(err)
+    }
+
+    page, err := os.ReadFile(fixture)
+    if err != nil {
+        t.Fatalf("Failed to read fixture: %v", err)
+    }
+
+    dropIn := "Mon Jan 15 2024 6:00 PM - 8:00 PM Badminton Drop-In SRAC Court 3 badminton, open play https://fitness.sjsu.edu/Program/Register/4411"
+    tournament := "Wed Jan 17 2024 10:00 AM - 2:00 PM Badminton Tournament SRAC badminton, tournament https://register.example.edu/events/77"
+
+    testCases := []struct {
+        name  string
+        args  []string
+        stdin string
+        want  []string
+    }{
+        {
+            name: "built-in profile",
+            args: []string{"-date", "2024-01-14", fixture},
+            want: []string{"No events found."},
+        },
+        {
+            name: "profile file",
+            args: []string{"-profile", profile, "-date", "2024-01-14", fixture},
+            want: []string{"DATE TIME TITLE LOCATION TAGS REGISTER", dropIn, tournament, "2 events"},
+        },
+        {
+            name:  "all entries from stdin",
+            args:  []string{"-all", "-profile", profile, "-date", "2024-01-14", "-"},
+            stdin: string(page),
+            want:  []string{dropIn, "Mon Jan 15 2024 8:00 PM - 10:00 PM Basketball Court Reservation SRAC Court 1", tournament, "3 events"},
+        },
+    }
+
+    for _, tc := range testCases {
+        t.Run(tc.name, func(t *testing.T) {
+            var out bytes.Buffer
+            if err := run(tc.args, strings.NewReader(tc.stdin), &out); err != nil {
+                t.Fatalf("run(%q) error: %v", tc.args, err)
+            }
+
+            lines := fields(out.String())
+            for _, want := range tc.want {
+                found := false
+                for _, line := range lines {
+                    if line == want {
+                        found = true
+                        break
+                    }
+                }
+                if !found {
+                    t.Errorf("Expected line %q in output:\n%s", want, out.String())
+                }
+            }
+        })
+    }
+}
+
+func TestRunJSON(t *testing.T) {
+    var out bytes.Buffer
+    if err := run([]string{"-json", "-date", "2024-01-14", fixture}, strings.NewReader(""), &out); err != nil {
+        t.Fatalf("Unexpected error: %v", err)
+    }
+    var events []json.RawMessage
+    if err := json.Unmarshal(out.Bytes(), &events); err != nil || len(events) != 0 {
+        t.Errorf("Expected an empty JSON list with the built-in profile, got %q (%v)", out.String(), err)
+    }
+}
+
+func TestRunErrors(t *testing.T) {
+    testCases := []struct {
+        name    string
+        args    []string
+        wantErr string
+    }{
+        {name: "no page", args: nil, wantErr: "expected one HTML file"},
+        {name: "missing profile", args: []string{"-profile", filepath.Join(t.TempDir(), "missing.json"), fixture}, wantErr: "reading schedule profile"},
+        {name: "bad date", args: []string{"-date", "1/14", fixture}, wantErr: "invalid -date"},
+        {name: "missing page", args: []string{"missing.html"}, wantErr: "missing.html"},
+    }
+
+    for _, tc := range testCases {
+        t.Run(tc.name, func(t *testing.T) {
+            var out bytes.Buffer
+            err := run(tc.args, strings.NewReader(""), &out)
+            if err == nil || !strings.Contains(err.Error(), tc.wantErr) {
+                t.Errorf("run(%q) error = %v, want one containing %q", tc.args, err, tc.wantErr)
+            }
+        })
+    }
+}
@@ -0,0 +1,236 @@
+// Package classify decides which fitness schedule entries are badminton
+// events and tags them by kind, using rules loaded from a JSON file.
+package classify
+
+import (
+    "bytes"
+    _ "embed"
+    "encoding/json"
+    "errors"
+    "fmt"
+    "os"
+    "regexp"
+    "strings"
+)
+
+// TagBadminton is the first tag of every event the rules accept
+const TagBadminton = "badminton"
+
+//go:embed default_rules.json
+var defaultRules []byte
+
+// Config is the JSON rules file
+type Config struct {
+    // Include must match an entry's title or location for it to count
+    Include Match `json:"include"`
+    // Exclude rejects entries whose title it matches, even when Include
+    // matched. Locations are filtered by Locations instead, so a badminton
+    // session in a room named after another sport is kept.
+    Exclude   Match          `json:"exclude"`
+    Locations LocationFilter `json:"locations"`
+    // Tags are added, in order, to accepted events whose title matches
+    Tags []TagRule `json:"tags"`
+}
+
+// Match lists keywords and regular expressions, either of which may match.
+// Keywords are case-insensitive and match at the start of a word, so
+// "lesson" matches "Lessons" but "ball" doesn't match "Basketball".
+type Match struct {
+    Keywords []string `json:"keywords"`
+    Patterns []string `json:"patterns"`
+}
+
+// LocationFilter limits events by case-insensitive substrings of their
+// location. Entries without a location pass the include list.
+type LocationFilter struct {
+    Include []string `json:"include"`
+    Exclude []string `json:"exclude"`
+}
+
+// TagRule assigns Tag to events whose title matches
+type TagRule struct {
+    Tag string `json:"tag"`
+    Match
+}
+
+// Rules is a compiled rules file
+type Rules struct {
+    include    matcher
+    exclude    matcher
+    locInclude []string
+    locExclude []string
+    tags       []tagMatcher
+}
+
+type matcher []*regexp.Regexp
+
+type tagMatcher struct {
+    tag string
+    m   matcher
+}
+
+// Default returns the built-in rules
+func Default() *Rules {
+    r, err := Parse(defaultRules)
+    if err != nil {
+        panic(fmt.Sprintf("invalid built-in classification rules: %v", err))
+    }
+    return r
+}
+
+// Load reads rules from a JSON file, or returns the built-in rules when
+// path is empty
+func Load(path string) (*Rules, error) {
+    if path == "" {
+        return Default(), nil
+    }
+
+    data, err := os.ReadFile(path)
+    if err != nil {
+        return nil, fmt.Errorf("reading classification rules: %w", err)
+    }
+    r, err := Parse(data)
+    if err != nil {
+        return nil, fmt.Errorf("loading %s: %w", path, err)
+    }
+    return r, nil
+}
+
+// Parse compiles a JSON rules file. Unknown fields are rejected so typos
+// don't silently disable a rule.
+func Parse(data []byte) (*Rules, error) {
+    dec := json.NewDecoder(bytes.NewReader(data))
+    dec.DisallowUnknownFields()
+
+    var cfg Config
+    if err := dec.Decode(&cfg); err != nil {
+        return nil, fmt.Errorf("decoding classification rules: %w", err)
+    }
+    return New(cfg)
+}
+
+// New compiles cfg
+func New(cfg Config) (*Rules, error) {
+    include, err := compile(cfg.Include)
+    if err != nil {
+        return nil, fmt.Errorf("include: %w", err)
+    }
+    if len(include) == 0 {
+        return nil, errors.New("include: at least one keyword or pattern is required")
+    }
+
+    exclude, err := compile(cfg.Exclude)
+    if err != nil {
+        return nil, fmt.Errorf("exclude: %w", err)
+    }
+
+    r := &Rules{
+        include:    include,
+        exclude:    exclude,
+        locInclude: lower(cfg.Locations.Include),
+        locExclude: lower(cfg.Locations.Exclude),
+    }
+
+    for i, t := range cfg.Tags {
+        tag := strings.TrimSpace(t.Tag)
+        if tag == "" {
+            return nil, fmt.Errorf("tags[%d]: tag name is required", i)
+        }
+        m, err := compile(t.Match)
+        if err != nil {
+            return nil, fmt.Errorf("tags[%d] (%s): %w", i, tag, err)
+        }
+        if len(m) == 0 {
+            return nil, fmt.Errorf("tags[%d] (%s): at least one keyword or pattern is required", i, tag)
+        }
+        r.tags = append(r.tags, tagMatcher{tag: tag, m: m})
+    }
+
+    return r, nil
+}
+
+// Classify reports whether an entry is a badminton event and, if it is,
+// returns its tags: TagBadminton followed by every matching tag rule's tag
+func (r *Rules) Classify(title, location string) ([]string, bool) {
+    if !r.Match(title, location) {
+        return nil, false
+    }
+
+    tags := []string{TagBadminton}
+    for _, t := range r.tags {
+        if t.m.match(title) {
+            tags = append(tags, t.tag)
+        }
+    }
+    return tags, true
+}
+
+// Match reports whether an entry is a badminton event
+func (r *Rules) Match(title, location string) bool {
+    if !r.include.match(title) && !r.include.match(location) {
+        return false
+    }
+    if r.exclude.match(title) {
+        return false
+    }
+
+    loc := strings.ToLower(location)
+    if containsAny(loc, r.locExclude) {
+        return false
+    }
+    if len(r.locInclude) > 0 && loc != "" && !containsAny(loc, r.locInclude) {
+        return false
+    }
+    return true
+}
+
+func (m matcher) match(s string) bool {
+    if s == "" {
+        return false
+    }
+    for _, re := range m {
+        if re.MatchString(s) {
+            return true
+        }
+    }
+    return false
+}
+
+// compile turns keywords and patterns into case-insensitive expressions
+func compile(m Match) (matcher, error) {
+    var out matcher
+    for _, kw := range m.Keywords {
+        kw = strings.TrimSpace(kw)
+        if kw == "" {
+            continue
+        }
+        out = append(out, regexp.MustCompile(`(?i)\b`+regexp.QuoteMeta(kw)))
+    }
+    for _, p := range m.Patterns {
+        re, err := regexp.Compile("(?i)" + p)
+        if err != nil {
+            return nil, fmt.Errorf("pattern %q: %w", p, err)
+        }
+        out = append(out, re)
+    }
+    return out, nil
+}
+
+func lower(ss []string) []string {
+    var out []string
+    for _, s := range ss {
+        if s = strings.ToLower(strings.TrimSpace(s)); s != "" {
+            out = append(out, s)
+        }
+    }
+    return out
+}
+
+func containsAny(s string, subs []string) bool {
+    for _, sub := range subs {
+        if strings.Contains(s, sub) {
+            return true
+        }
+    }
+    return false
+}
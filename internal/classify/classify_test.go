@@ -0,0 +1,138 @@
+package classify
+
+import (
+    "os"
+    "path/filepath"
+    "reflect"
+    "strings"
+    "testing"
+)
+
+func TestDefaultClassify(t *testing.T) {
+    rules := Default()
+
+    testCases := []struct {
+        name     string
+        title    string
+        location string
+        wantOK   bool
+        wantTags []string
+    }{
+        {name: "plain badminton", title: "Badminton", location: "SRAC Gym", wantOK: true, wantTags: []string{"badminton"}},
+        {name: "open play", title: "Badminton Open Play", location: "Event Center", wantOK: true, wantTags: []string{"badminton", "open play"}},
+        {name: "club practice", title: "Badminton Club Practice", wantOK: true, wantTags: []string{"badminton", "club"}},
+        {name: "badminton tournament", title: "Spring Badminton Tournament", wantOK: true, wantTags: []string{"badminton", "tournament"}},
+        {name: "lessons", title: "Beginner Badminton Lessons", wantOK: true, wantTags: []string{"badminton", "lessons"}},
+        {name: "classes", title: "Badminton Classes", wantOK: true, wantTags: []string{"badminton", "lessons"}},
+        {name: "class is not classic", title: "Classic Badminton Doubles Tournament", wantOK: true, wantTags: []string{"badminton", "tournament"}},
+        {name: "shuttlecock", title: "Shuttlecock Social", wantOK: true, wantTags: []string{"badminton"}},
+        {name: "several tags", title: "Badminton Club Tournament", wantOK: true, wantTags: []string{"badminton", "club", "tournament"}},
+        {name: "case insensitive", title: "BADMINTON DROP-IN", wantOK: true, wantTags: []string{"badminton", "open play"}},
+        {name: "location only", title: "Open Gym", location: "Badminton Courts", wantOK: true, wantTags: []string{"badminton", "open play"}},
+        {name: "basketball court", title: "Basketball Court Reservation", location: "SRAC Court 1"},
+        {name: "tennis tournament", title: "Tennis Tournament", location: "Tennis Courts"},
+        {name: "singles and doubles", title: "Pickleball Singles & Doubles"},
+        {name: "excluded location", title: "Shuttle Run Drills", location: "Aquatic Center"},
+        {name: "shuttle bus", title: "Shuttle Bus to Event Center"},
+        {name: "excluded keyword only in location", title: "Badminton", location: "Volleyball Court", wantOK: true, wantTags: []string{"badminton"}},
+        {name: "table tennis", title: "Table Tennis Club", location: "Badminton Courts"},
+        {name: "keyword inside word", title: "Intramural Team Tryouts", location: "Gym"},
+        {name: "empty", title: "", location: ""},
+    }
+
+    for _, tc := range testCases {
+        t.Run(tc.name, func(t *testing.T) {
+            tags, ok := rules.Classify(tc.title, tc.location)
+            if ok != tc.wantOK {
+                t.Fatalf("Classify(%q, %q) ok = %v, want %v", tc.title, tc.location, ok, tc.wantOK)
+            }
+            if !reflect.DeepEqual(tags, tc.wantTags) {
+                t.Errorf("Classify(%q, %q) tags = %q, want %q", tc.title, tc.location, tags, tc.wantTags)
+            }
+        })
+    }
+}
+
+func TestCustomRules(t *testing.T) {
+    rules, err := Parse([]byte(`{
+        "include": {"keywords": ["badminton"], "patterns": ["^bmt\\b"]},
+        "exclude": {"patterns": ["cancel+ed"]},
+        "locations": {"include": ["srac", "event center"]},
+        "tags": [{"tag": "social", "patterns": ["social|mixer"]}]
+    }`))
+    if err != nil {
+        t.Fatalf("Unexpected error: %v", err)
+    }
+
+    testCases := []struct {
+        title    string
+        location string
+        wantOK   bool
+        wantTags []string
+    }{
+        {title: "BMT night", location: "SRAC Gym", wantOK: true, wantTags: []string{"badminton"}},
+        {title: "Badminton Social", location: "Event Center", wantOK: true, wantTags: []string{"badminton", "social"}},
+        {title: "Badminton Mixer", wantOK: true, wantTags: []string{"badminton", "social"}},
+        {title: "Badminton", location: "Spartan Complex"},
+        {title: "Badminton (Cancelled)", location: "SRAC Gym"},
+        {title: "Submit BMT forms", location: "SRAC Gym"},
+    }
+
+    for _, tc := range testCases {
+        tags, ok := rules.Classify(tc.title, tc.location)
+        if ok != tc.wantOK || !reflect.DeepEqual(tags, tc.wantTags) {
+            t.Errorf("Classify(%q, %q) = %q, %v; want %q, %v", tc.title, tc.location, tags, ok, tc.wantTags, tc.wantOK)
+        }
+    }
+}
+
+func TestParseErrors(t *testing.T) {
+    testCases := []struct {
+        name    string
+        json    string
+        wantErr string
+    }{
+        {name: "not JSON", json: `include: badminton`, wantErr: "decoding"},
+        {name: "unknown field", json: `{"include": {"keyword": ["badminton"]}}`, wantErr: "unknown field"},
+        {name: "no include rules", json: `{"exclude": {"keywords": ["tennis"]}}`, wantErr: "include: at least one"},
+        {name: "bad include pattern", json: `{"include": {"patterns": ["(badminton"]}}`, wantErr: "include: pattern"},
+        {name: "bad exclude pattern", json: `{"include": {"keywords": ["badminton"]}, "exclude": {"patterns": ["[a-"]}}`, wantErr: "exclude: pattern"},
+        {name: "unnamed tag", json: `{"include": {"keywords": ["badminton"]}, "tags": [{"keywords": ["club"]}]}`, wantErr: "tags[0]: tag name"},
+        {name: "empty tag", json: `{"include": {"keywords": ["badminton"]}, "tags": [{"tag": "club"}]}`, wantErr: "tags[0] (club): at least one"},
+    }
+
+    for _, tc := range testCases {
+        t.Run(tc.name, func(t *testing.T) {
+            _, err := Parse([]byte(tc.json))
+            if err == nil || !strings.Contains(err.Error(), tc.wantErr) {
+                t.Errorf("Parse() error = %v, want one containing %q", err, tc.wantErr)
+            }
+        })
+    }
+}
+
+func TestLoad(t *testing.T) {
+    rules, err := Load("")
+    if err != nil {
+        t.Fatalf("Load(\"\") error: %v", err)
+    }
+    if !rules.Match("Badminton", "") {
+        t.Error("Load(\"\") should return the built-in rules")
+    }
+
+    path := filepath.Join(t.TempDir(), "rules.json")
+    if err := os.WriteFile(path, []byte(`{"include": {"keywords": ["squash"]}}`), 0o644); err != nil {
+        t.Fatal(err)
+    }
+    rules, err = Load(path)
+    if err != nil {
+        t.Fatalf("Load(%q) error: %v", path, err)
+    }
+    if !rules.Match("Squash Ladder", "") || rules.Match("Badminton", "") {
+        t.Error("Load should use the rules from the file")
+    }
+
+    if _, err := Load(filepath.Join(t.TempDir(), "missing.json")); err == nil {
+        t.Error("Expected an error for a missing file")
+    }
+}
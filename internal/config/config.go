@@ -2,35 +2,173 @@ package config
 
 import (
     "errors"
+    "fmt"
+    "net/url"
     "os"
+    "strconv"
+    "strings"
+    "time"
 )
 
 type Config struct {
-    Token      string
-    AppID      string
-    GuildID    string
-    TZ         string
-    MacGymURL  string
-    FitnessURL string
-    CronMacGym string
-    CronEvents string
-    AlertChan  string
+    Token        string
+    AppID        string
+    GuildID      string
+    TZ           string
+    MacGymURL    string
+    FitnessURL   string
+    UserAgent    string
+    CronMacGym   string
+    CronEvents   string
+    CronRemind   string
+    AlertChan    string
+    AnnounceChan string
+    AnnounceRole string
+    AdminChan    string
+    StoreBackend string
+    StorePath    string
+
+    CronDigestDaily  string
+    CronDigestWeekly string
+
+    HistoryRetention time.Duration
+    EventRetention   time.Duration
+    AlertCooldown    time.Duration
+    MacGymStaleAfter time.Duration
+    EventsStaleAfter time.Duration
+    SourceAlertAfter time.Duration
+    MacGymTimeout    time.Duration
+    FitnessTimeout   time.Duration
+
+    BreakerCooldown    time.Duration
+    BreakerMaxCooldown time.Duration
+
+    OutboxWorkers     int
+    OutboxMaxAttempts int
+    BreakerThreshold  int
+
+    // MacGymLocationIDs are the occupancy locations, by ID or name, counted
+    // as badminton courts
+    MacGymLocationIDs []string
+
+    // EventRulesPath is a JSON file of event classification rules; empty
+    // uses the built-in rules
+    EventRulesPath string
+    // FitnessProfilePath is a JSON file of fitness schedule HTML selectors;
+    // empty uses the built-in profile
+    FitnessProfilePath string
+
+    // FitnessWeeks is how many weeks of the fitness schedule are fetched, in
+    // pages of FitnessPageDays; 0 fetches FitnessURL's default view
+    FitnessWeeks      int
+    FitnessPageDays   int
+    FitnessMethod     string
+    FitnessStartParam string
+    FitnessEndParam   string
+    FitnessDateFormat string
+    // FitnessParams are sent with every schedule page, e.g. a facility ID
+    FitnessParams url.Values
+    // FitnessTokenURL is fetched first for cookies and an anti-forgery token
+    FitnessTokenURL   string
+    FitnessTokenField string
 }
 
 func get(k, def string) string { if v := os.Getenv(k); v != "" { return v }; return def }
 
+func getDuration(k string, def time.Duration) (time.Duration, error) {
+    v := os.Getenv(k)
+    if v == "" { return def, nil }
+    d, err := time.ParseDuration(v)
+    if err != nil { return 0, fmt.Errorf("invalid %s: %w", k, err) }
+    return d, nil
+}
+
+// getPositiveDuration is getDuration for settings where zero or less makes
+// no sense, such as how long data is kept
+func getPositiveDuration(k string, def time.Duration) (time.Duration, error) {
+    d, err := getDuration(k, def)
+    if err != nil { return 0, err }
+    if d <= 0 { return 0, fmt.Errorf("invalid %s: must be positive", k) }
+    return d, nil
+}
+
+func getInt(k string, def int) (int, error) {
+    v := os.Getenv(k)
+    if v == "" { return def, nil }
+    n, err := strconv.Atoi(v)
+    if err != nil || n < 1 { return 0, fmt.Errorf("invalid %s: must be a positive integer", k) }
+    return n, nil
+}
+
+func getCount(k string, def int) (int, error) {
+    v := os.Getenv(k)
+    if v == "" { return def, nil }
+    n, err := strconv.Atoi(v)
+    if err != nil || n < 0 { return 0, fmt.Errorf("invalid %s: must be zero or a positive integer", k) }
+    return n, nil
+}
+
+// getList splits a comma-separated variable, dropping empty entries
+func getList(k string) []string {
+    var out []string
+    for _, v := range strings.Split(os.Getenv(k), ",") {
+        if v = strings.TrimSpace(v); v != "" { out = append(out, v) }
+    }
+    return out
+}
+
 func Load() (Config, error) {
     c := Config{
-        Token:      os.Getenv("DISCORD_BOT_TOKEN"),
-        AppID:      get("DISCORD_APP_ID", ""),
-        GuildID:    get("DISCORD_GUILD_ID", ""),
-        TZ:         get("TIMEZONE", "America/Los_Angeles"),
-        MacGymURL:  get("MACGYM_URL", "https://www.connect2mycloud.com/Widgets/Data/locationCount?type=circle&key=92833ff9-2797-43ed-98ab-8730784a147f&loc_status=false"),
-        FitnessURL: get("FITNESS_URL", "https://fitness.sjsu.edu/Facility/GetSchedule"),
-        CronMacGym: get("REFRESH_MACGYM_CRON", "@every 2m"),
-        CronEvents: get("REFRESH_EVENTS_CRON", "@every 30m"),
-        AlertChan:  get("ALERT_CHANNEL_ID", ""),
+        Token:        os.Getenv("DISCORD_BOT_TOKEN"),
+        AppID:        get("DISCORD_APP_ID", ""),
+        GuildID:      get("DISCORD_GUILD_ID", ""),
+        TZ:           get("TIMEZONE", "America/Los_Angeles"),
+        MacGymURL:    get("MACGYM_URL", "https://www.connect2mycloud.com/Widgets/Data/locationCount?type=circle&key=92833ff9-2797-43ed-98ab-8730784a147f&loc_status=false"),
+        FitnessURL:   get("FITNESS_URL", "https://fitness.sjsu.edu/Facility/GetSchedule"),
+        UserAgent:    get("HTTP_USER_AGENT", ""),
+        CronMacGym:   get("REFRESH_MACGYM_CRON", "@every 2m"),
+        CronEvents:   get("REFRESH_EVENTS_CRON", "@every 30m"),
+        CronRemind:   get("REMINDERS_CRON", "@every 1m"),
+        AlertChan:    get("ALERT_CHANNEL_ID", ""),
+        AnnounceChan: get("ANNOUNCE_CHANNEL_ID", ""),
+        AnnounceRole: get("ANNOUNCE_ROLE_ID", ""),
+        AdminChan:    get("ADMIN_CHANNEL_ID", ""),
+        StoreBackend: get("STORE_BACKEND", "bolt"),
+        StorePath:    get("STORE_PATH", "data/badminton.db"),
+
+        CronDigestDaily:  get("DIGEST_DAILY_CRON", "0 8 * * *"),
+        CronDigestWeekly: get("DIGEST_WEEKLY_CRON", "0 8 * * 1"),
+
+        MacGymLocationIDs: getList("MACGYM_LOCATION_IDS"),
+        EventRulesPath:    get("EVENT_RULES_FILE", ""),
+
+        FitnessProfilePath: get("FITNESS_PROFILE_FILE", ""),
+        FitnessMethod:      strings.ToUpper(get("FITNESS_METHOD", "GET")),
+        FitnessStartParam:  get("FITNESS_START_PARAM", "start"),
+        FitnessEndParam:    get("FITNESS_END_PARAM", "end"),
+        FitnessDateFormat:  get("FITNESS_DATE_FORMAT", "2006-01-02"),
+        FitnessTokenURL:    get("FITNESS_TOKEN_URL", ""),
+        FitnessTokenField:  get("FITNESS_TOKEN_FIELD", "__RequestVerificationToken"),
     }
     if c.Token == "" { return c, errors.New("missing DISCORD_BOT_TOKEN") }
+
+    var err error
+    if c.HistoryRetention, err = getPositiveDuration("HISTORY_RETENTION", 28*24*time.Hour); err != nil { return c, err }
+    if c.EventRetention, err = getPositiveDuration("EVENT_RETENTION", 7*24*time.Hour); err != nil { return c, err }
+    if c.AlertCooldown, err = getDuration("ALERT_COOLDOWN", 5*time.Minute); err != nil { return c, err }
+    if c.MacGymStaleAfter, err = getPositiveDuration("MACGYM_STALE_AFTER", 10*time.Minute); err != nil { return c, err }
+    if c.EventsStaleAfter, err = getPositiveDuration("EVENTS_STALE_AFTER", 2*time.Hour); err != nil { return c, err }
+    if c.SourceAlertAfter, err = getPositiveDuration("SOURCE_ALERT_AFTER", 30*time.Minute); err != nil { return c, err }
+    if c.MacGymTimeout, err = getDuration("MACGYM_TIMEOUT", 30*time.Second); err != nil { return c, err }
+    if c.FitnessTimeout, err = getDuration("FITNESS_TIMEOUT", 60*time.Second); err != nil { return c, err }
+    if c.OutboxWorkers, err = getInt("OUTBOX_WORKERS", 4); err != nil { return c, err }
+    if c.OutboxMaxAttempts, err = getInt("OUTBOX_MAX_ATTEMPTS", 5); err != nil { return c, err }
+    if c.BreakerThreshold, err = getInt("BREAKER_THRESHOLD", 3); err != nil { return c, err }
+    if c.BreakerCooldown, err = getDuration("BREAKER_COOLDOWN", 5*time.Minute); err != nil { return c, err }
+    if c.BreakerMaxCooldown, err = getDuration("BREAKER_MAX_COOLDOWN", time.Hour); err != nil { return c, err }
+    if c.FitnessWeeks, err = getCount("FITNESS_WEEKS", 0); err != nil { return c, err }
+    if c.FitnessPageDays, err = getInt("FITNESS_PAGE_DAYS", 7); err != nil { return c, err }
+    if c.FitnessMethod != "GET" && c.FitnessMethod != "POST" { return c, fmt.Errorf("invalid FITNESS_METHOD: must be GET or POST") }
+    if c.FitnessParams, err = url.ParseQuery(os.Getenv("FITNESS_PARAMS")); err != nil { return c, fmt.Errorf("invalid FITNESS_PARAMS: %w", err) }
     return c, nil
 }
@@ -47,3 +47,73 @@ func TestLoadMissingToken(t *testing.T) {
         t.Error("Expected error for missing token but got none")
     }
 }
+
+func TestLoadLocationIDs(t *testing.T) {
+    t.Setenv("DISCORD_BOT_TOKEN", "test-token")
+    t.Setenv("MACGYM_LOCATION_IDS", " 5640, ,SRAC Court 2 ")
+
+    cfg, err := Load()
+    if err != nil {
+        t.Fatalf("Unexpected error: %v", err)
+    }
+
+    if len(cfg.MacGymLocationIDs) != 2 || cfg.MacGymLocationIDs[0] != "5640" || cfg.MacGymLocationIDs[1] != "SRAC Court 2" {
+        t.Errorf("Expected [5640 SRAC Court 2], got %q", cfg.MacGymLocationIDs)
+    }
+}
+
+func TestLoadFitnessQuery(t *testing.T) {
+    t.Setenv("DISCORD_BOT_TOKEN", "test-token")
+
+    cfg, err := Load()
+    if err != nil {
+        t.Fatalf("Unexpected error: %v", err)
+    }
+    if cfg.FitnessWeeks != 0 || cfg.FitnessPageDays != 7 || cfg.FitnessMethod != "GET" || len(cfg.FitnessParams) != 0 {
+        t.Errorf("Unexpected defaults: weeks %d, page days %d, method %q, params %v",
+            cfg.FitnessWeeks, cfg.FitnessPageDays, cfg.FitnessMethod, cfg.FitnessParams)
+    }
+
+    t.Setenv("FITNESS_WEEKS", "5")
+    t.Setenv("FITNESS_METHOD", "post")
+    t.Setenv("FITNESS_PARAMS", "facilityId=12&view=week")
+    cfg, err = Load()
+    if err != nil {
+        t.Fatalf("Unexpected error: %v", err)
+    }
+    if cfg.FitnessWeeks != 5 || cfg.FitnessMethod != "POST" || cfg.FitnessParams.Get("facilityId") != "12" || cfg.FitnessParams.Get("view") != "week" {
+        t.Errorf("Unexpected query: weeks %d, method %q, params %v", cfg.FitnessWeeks, cfg.FitnessMethod, cfg.FitnessParams)
+    }
+
+    for k, v := range map[string]string{
+        "FITNESS_WEEKS":  "-1",
+        "FITNESS_METHOD": "PUT",
+        "FITNESS_PARAMS": "facilityId=%zz",
+    } {
+        t.Run(k, func(t *testing.T) {
+            t.Setenv(k, v)
+            if _, err := Load(); err == nil {
+                t.Errorf("Expected an error for %s=%s", k, v)
+            }
+        })
+    }
+}
+
+func TestLoadRejectsNonPositiveDurations(t *testing.T) {
+    t.Setenv("DISCORD_BOT_TOKEN", "test-token")
+
+    keys := []string{
+        "HISTORY_RETENTION", "EVENT_RETENTION",
+        "MACGYM_STALE_AFTER", "EVENTS_STALE_AFTER", "SOURCE_ALERT_AFTER",
+    }
+    for _, k := range keys {
+        for _, v := range []string{"0", "-1h"} {
+            t.Run(k+"="+v, func(t *testing.T) {
+                t.Setenv(k, v)
+                if _, err := Load(); err == nil {
+                    t.Errorf("Expected an error for %s=%s", k, v)
+                }
+            })
+        }
+    }
+}
@@ -0,0 +1,142 @@
+package discord
+
+import (
+    "fmt"
+    "log/slog"
+    "time"
+
+    "github.com/bwmarrin/discordgo"
+
+    "github.com/sjsu-badminton/badminton-discord-bot/internal/store"
+    "github.com/sjsu-badminton/badminton-discord-bot/internal/util"
+)
+
+// maxAnnouncedEvents keeps announcement embeds well under Discord's 25 field limit
+const maxAnnouncedEvents = 10
+
+// AnnounceEvents posts newly discovered events to the announcement channel,
+// optionally pinging the announcement role. It implements sched.Notifier.
+func (c *Client) AnnounceEvents(events []store.Event) {
+    if c.cfg.AnnounceChan == "" {
+        slog.Debug("No announcement channel configured, skipping new event announcement", "count", len(events))
+        return
+    }
+
+    msg := newEventsMessage(events, c.cfg.AnnounceRole, util.MustLocation(c.cfg.TZ))
+    err := c.outbox.enqueue(outboundMessage{Kind: kindAnnouncement, ChannelID: c.cfg.AnnounceChan, Msg: msg})
+    if err != nil {
+        slog.Error("Failed to queue new event announcement",
+            "channel", c.cfg.AnnounceChan,
+            "count", len(events),
+            "error", err)
+        return
+    }
+
+    slog.Info("Queued new event announcement", "channel", c.cfg.AnnounceChan, "count", len(events))
+}
+
+// AnnounceMoves posts rescheduled or relocated events to the announcement
+// channel, pinging the announcement role like new events. It implements
+// sched.Notifier.
+func (c *Client) AnnounceMoves(moves []store.Change) {
+    if c.cfg.AnnounceChan == "" {
+        slog.Debug("No announcement channel configured, skipping moved event announcement", "count", len(moves))
+        return
+    }
+
+    msg := movedEventsMessage(moves, c.cfg.AnnounceRole, util.MustLocation(c.cfg.TZ))
+    err := c.outbox.enqueue(outboundMessage{Kind: kindAnnouncement, ChannelID: c.cfg.AnnounceChan, Msg: msg})
+    if err != nil {
+        slog.Error("Failed to queue moved event announcement",
+            "channel", c.cfg.AnnounceChan,
+            "count", len(moves),
+            "error", err)
+        return
+    }
+
+    slog.Info("Queued moved event announcement", "channel", c.cfg.AnnounceChan, "count", len(moves))
+}
+
+// newEventsMessage builds the announcement for new events. Only roleID (if
+// set) may be mentioned so event titles can never ping anyone.
+func newEventsMessage(events []store.Event, roleID string, loc *time.Location) *discordgo.MessageSend {
+    title := "🆕 New Badminton Event Posted"
+    if len(events) > 1 {
+        title = fmt.Sprintf("🆕 %d New Badminton Events Posted", len(events))
+    }
+
+    embed := &discordgo.MessageEmbed{
+        Title: title,
+        Color: 0x0099ff,
+        Footer: &discordgo.MessageEmbedFooter{
+            Text: "SJSU Badminton Bot • Use /badminton events to see the full schedule",
+        },
+    }
+
+    shown := events
+    if len(shown) > maxAnnouncedEvents {
+        shown = shown[:maxAnnouncedEvents]
+        embed.Description = fmt.Sprintf("Showing the first %d.", maxAnnouncedEvents)
+    }
+
+    for _, e := range shown {
+        embed.Fields = append(embed.Fields, &discordgo.MessageEmbedField{
+            Name:  e.Title,
+            Value: fmt.Sprintf("**Time:** %s\n**Location:** %s", eventTimes(e, loc), e.Location),
+        })
+    }
+
+    return announcement(embed, roleID)
+}
+
+// movedEventsMessage builds the announcement for events that moved, showing
+// where and when each was before and is now
+func movedEventsMessage(moves []store.Change, roleID string, loc *time.Location) *discordgo.MessageSend {
+    title := "📅 Badminton Event Moved"
+    if len(moves) > 1 {
+        title = fmt.Sprintf("📅 %d Badminton Events Moved", len(moves))
+    }
+
+    embed := &discordgo.MessageEmbed{
+        Title: title,
+        Color: 0xffa500,
+        Footer: &discordgo.MessageEmbedFooter{
+            Text: "SJSU Badminton Bot • Use /badminton events to see the full schedule",
+        },
+    }
+
+    shown := moves
+    if len(shown) > maxAnnouncedEvents {
+        shown = shown[:maxAnnouncedEvents]
+        embed.Description = fmt.Sprintf("Showing the first %d.", maxAnnouncedEvents)
+    }
+
+    for _, c := range shown {
+        embed.Fields = append(embed.Fields, &discordgo.MessageEmbedField{
+            Name: c.Title,
+            Value: fmt.Sprintf("**Moved from:** %s, %s\n**To:** %s, %s",
+                eventTimes(c.Previous, loc), c.Previous.Location,
+                eventTimes(c.Event, loc), c.Location),
+        })
+    }
+
+    return announcement(embed, roleID)
+}
+
+// eventTimes formats an event's start and end, e.g. "Mon, Jan 15 6:00 PM - 8:00 PM"
+func eventTimes(e store.Event, loc *time.Location) string {
+    return e.Start.In(loc).Format("Mon, Jan 2 3:04 PM") + " - " + e.End.In(loc).Format("3:04 PM")
+}
+
+// announcement wraps an embed in a message that may only mention roleID
+func announcement(embed *discordgo.MessageEmbed, roleID string) *discordgo.MessageSend {
+    msg := &discordgo.MessageSend{
+        Embeds:          []*discordgo.MessageEmbed{embed},
+        AllowedMentions: &discordgo.MessageAllowedMentions{},
+    }
+    if roleID != "" {
+        msg.Content = fmt.Sprintf("<@&%s>", roleID)
+        msg.AllowedMentions.Roles = []string{roleID}
+    }
+    return msg
+}
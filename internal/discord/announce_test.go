@@ -0,0 +1,78 @@
+package discord
+
+import (
+    "testing"
+    "time"
+
+    "github.com/sjsu-badminton/badminton-discord-bot/internal/store"
+)
+
+func TestNewEventsMessage(t *testing.T) {
+    start := time.Date(2024, 1, 15, 18, 0, 0, 0, time.UTC)
+    var events []store.Event
+    for i := 0; i < maxAnnouncedEvents+2; i++ {
+        events = append(events, store.Event{
+            Title:    "Badminton Open Play",
+            Start:    start.AddDate(0, 0, i),
+            End:      start.AddDate(0, 0, i).Add(2 * time.Hour),
+            Location: "Mac Gym",
+        })
+    }
+
+    msg := newEventsMessage(events, "", time.UTC)
+    if msg.Content != "" {
+        t.Errorf("Expected no content without a role, got %q", msg.Content)
+    }
+    if msg.AllowedMentions == nil || len(msg.AllowedMentions.Roles) != 0 || len(msg.AllowedMentions.Parse) != 0 {
+        t.Errorf("Expected all mentions to be suppressed, got %+v", msg.AllowedMentions)
+    }
+    if got := len(msg.Embeds[0].Fields); got != maxAnnouncedEvents {
+        t.Errorf("Expected %d fields, got %d", maxAnnouncedEvents, got)
+    }
+
+    msg = newEventsMessage(events[:1], "123", time.UTC)
+    if msg.Content != "<@&123>" {
+        t.Errorf("Expected role mention, got %q", msg.Content)
+    }
+    if roles := msg.AllowedMentions.Roles; len(roles) != 1 || roles[0] != "123" {
+        t.Errorf("Expected only the announcement role to be mentionable, got %v", roles)
+    }
+    if got := msg.Embeds[0].Fields[0].Value; got != "**Time:** Mon, Jan 15 6:00 PM - 8:00 PM\n**Location:** Mac Gym" {
+        t.Errorf("Unexpected field value %q", got)
+    }
+}
+
+func TestMovedEventsMessage(t *testing.T) {
+    start := time.Date(2024, 1, 15, 18, 0, 0, 0, time.UTC)
+    previous := store.Event{Title: "Badminton Open Play", Start: start, End: start.Add(2 * time.Hour), Location: "Mac Gym"}
+
+    testCases := []struct {
+        name      string
+        event     store.Event
+        wantValue string
+    }{
+        {
+            name:      "rescheduled",
+            event:     store.Event{Title: "Badminton Open Play", Start: start.Add(time.Hour), End: start.Add(3 * time.Hour), Location: "Mac Gym"},
+            wantValue: "**Moved from:** Mon, Jan 15 6:00 PM - 8:00 PM, Mac Gym\n**To:** Mon, Jan 15 7:00 PM - 9:00 PM, Mac Gym",
+        },
+        {
+            name:      "new room",
+            event:     store.Event{Title: "Badminton Open Play", Start: start, End: start.Add(2 * time.Hour), Location: "Event Center"},
+            wantValue: "**Moved from:** Mon, Jan 15 6:00 PM - 8:00 PM, Mac Gym\n**To:** Mon, Jan 15 6:00 PM - 8:00 PM, Event Center",
+        },
+    }
+
+    for _, tc := range testCases {
+        t.Run(tc.name, func(t *testing.T) {
+            msg := movedEventsMessage([]store.Change{{Event: tc.event, Previous: previous}}, "123", time.UTC)
+            if msg.Content != "<@&123>" || len(msg.AllowedMentions.Roles) != 1 {
+                t.Errorf("Expected only the announcement role to be mentioned, got %q %+v", msg.Content, msg.AllowedMentions)
+            }
+            field := msg.Embeds[0].Fields[0]
+            if field.Name != "Badminton Open Play" || field.Value != tc.wantValue {
+                t.Errorf("Unexpected field %q: %q", field.Name, field.Value)
+            }
+        })
+    }
+}
@@ -10,13 +10,15 @@ import (
     "github.com/sjsu-badminton/badminton-discord-bot/internal/config"
     "github.com/sjsu-badminton/badminton-discord-bot/internal/sched"
     "github.com/sjsu-badminton/badminton-discord-bot/internal/store"
+    "github.com/sjsu-badminton/badminton-discord-bot/internal/util"
 )
 
 type Client struct {
-    cfg   config.Config
-    sess  *discordgo.Session
-    store *store.MemoryStore
-    cron  *sched.Cron
+    cfg    config.Config
+    sess   *discordgo.Session
+    store  store.Store
+    cron   *sched.Cron
+    outbox *outbox
 }
 
 func NewClient(ctx context.Context, cfg config.Config) (*Client, error) {
@@ -25,15 +27,29 @@ func NewClient(ctx context.Context, cfg config.Config) (*Client, error) {
         return nil, fmt.Errorf("creating Discord session: %w", err)
     }
     
-    // Set intents
-    s.Identify.Intents = discordgo.IntentsGuildMessages | discordgo.IntentsDirectMessages
+    // Set intents; MessageContent is privileged and must also be enabled in the
+    // developer portal for prefix commands to see message text
+    s.Identify.Intents = discordgo.IntentsGuildMessages | discordgo.IntentsDirectMessages | discordgo.IntentsMessageContent
+    
+    st, err := store.Open(store.Options{
+        Backend:          cfg.StoreBackend,
+        Path:             cfg.StorePath,
+        HistoryRetention: cfg.HistoryRetention,
+        AlertCooldown:    cfg.AlertCooldown,
+        Location:         util.MustLocation(cfg.TZ),
+    })
+    if err != nil {
+        return nil, fmt.Errorf("opening %s store: %w", cfg.StoreBackend, err)
+    }
     
     c := &Client{
         cfg:   cfg,
         sess:  s,
-        store: store.NewMemoryStore(),
+        store: st,
     }
     
+    c.outbox = newOutbox(s, cfg.OutboxWorkers, cfg.OutboxMaxAttempts, c.handleUndeliverable)
+    c.store.SetNotifier(c)
     c.attachHandlers()
     
     slog.Info("Discord client created successfully")
@@ -51,7 +67,12 @@ func (c *Client) Start(ctx context.Context) error {
         return fmt.Errorf("registering commands: %w", err)
     }
     
-    c.cron = sched.Start(ctx, c.cfg, c.store)
+    cron, err := sched.Start(ctx, c.cfg, c.store, c)
+    if err != nil {
+        return fmt.Errorf("starting scheduler: %w", err)
+    }
+    c.cron = cron
+    c.outbox.start(ctx)
     
     slog.Info("Bot started successfully", 
         "guildID", c.cfg.GuildID,
@@ -67,27 +88,80 @@ func (c *Client) Stop() {
         c.cron.Stop()
     }
     
+    // Deliver whatever is still queued before the session closes
+    if c.outbox != nil {
+        c.outbox.stop()
+        logOutboxStats(c.outbox.Stats())
+    }
+    
     if c.sess != nil {
         c.sess.Close()
     }
     
+    if c.store != nil {
+        if err := c.store.Close(); err != nil {
+            slog.Error("Failed to close store", "error", err)
+        }
+    }
+    
     slog.Info("Bot stopped")
 }
 
-// SendAlert sends an alert to a user or channel
+// SendAlert queues an alert for a user, posted in the alert channel if one
+// is configured and sent by DM otherwise
 func (c *Client) SendAlert(userID string, message string) error {
     if c.cfg.AlertChan != "" {
-        // Send to alert channel
-        _, err := c.sess.ChannelMessageSend(c.cfg.AlertChan, fmt.Sprintf("<@%s> %s", userID, message))
-        return err
-    } else {
-        // Send DM
-        channel, err := c.sess.UserChannelCreate(userID)
-        if err != nil {
-            return fmt.Errorf("creating DM channel: %w", err)
-        }
-        
-        _, err = c.sess.ChannelMessageSend(channel.ID, message)
-        return err
+        // Ping only the subscriber
+        return c.outbox.enqueue(outboundMessage{
+            Kind:      kindAlert,
+            ChannelID: c.cfg.AlertChan,
+            UserID:    userID,
+            Msg: &discordgo.MessageSend{
+                Content: fmt.Sprintf("<@%s> %s", userID, message),
+                AllowedMentions: &discordgo.MessageAllowedMentions{
+                    Users: []string{userID},
+                },
+            },
+        })
+    }
+    return c.sendDM(kindAlert, userID, &discordgo.MessageSend{Content: message})
+}
+
+// sendDM queues a direct message to a user
+func (c *Client) sendDM(kind, userID string, msg *discordgo.MessageSend) error {
+    return c.outbox.enqueue(outboundMessage{Kind: kind, UserID: userID, Msg: msg})
+}
+
+// NotifyThreshold queues a threshold alert for the subscriber, or for the
+// guild's alert channel for role subscriptions. It implements store.Notifier;
+// queueing never blocks, so the store is never held up by Discord.
+func (c *Client) NotifyThreshold(a store.ThresholdAlert) {
+    if a.RoleID != "" {
+        c.sendRoleAlert(a)
+        return
+    }
+
+    if err := c.SendAlert(a.UserID, thresholdMessage(a)); err != nil {
+        slog.Error("Failed to queue threshold alert", 
+            "userID", a.UserID,
+            "threshold", a.Threshold,
+            "direction", a.Direction,
+            "error", err)
+    }
+}
+
+// thresholdMessage describes a threshold crossing in the direction the
+// subscriber asked for
+func thresholdMessage(a store.ThresholdAlert) string {
+    whose := "your alert"
+    if a.RoleID != "" {
+        whose = "role alert"
+    }
+
+    if a.Direction == store.Below {
+        return fmt.Sprintf("🏸 Courts are freeing up! Mac Gym occupancy is now %d/%d (%s: fewer than %d in use).",
+            a.Snapshot.InUse, a.Snapshot.Capacity, whose, a.Threshold)
     }
+    return fmt.Sprintf("🏸 Mac Gym occupancy is now %d/%d (%s threshold: %d).",
+        a.Snapshot.InUse, a.Snapshot.Capacity, whose, a.Threshold)
 }
@@ -3,15 +3,44 @@ package discord
 import (
     "fmt"
     "log/slog"
+    "strings"
+    "time"
 
     "github.com/bwmarrin/discordgo"
+
+    "github.com/sjsu-badminton/badminton-discord-bot/internal/store"
 )
 
 func (c *Client) registerCommands() error {
     cmds := []*discordgo.ApplicationCommand{
         {
             Name:        "macgym",
-            Description: "Show current Mac Gym badminton occupancy",
+            Description: "Mac Gym badminton occupancy",
+            Options: []*discordgo.ApplicationCommandOption{
+                {
+                    Type:        discordgo.ApplicationCommandOptionSubCommand,
+                    Name:        "status",
+                    Description: "Show current Mac Gym badminton occupancy",
+                },
+                {
+                    Type:        discordgo.ApplicationCommandOptionSubCommand,
+                    Name:        "history",
+                    Description: "Summarize recent Mac Gym occupancy",
+                    Options: []*discordgo.ApplicationCommandOption{
+                        {
+                            Type:        discordgo.ApplicationCommandOptionInteger,
+                            Name:        "hours",
+                            Description: "Number of hours to look back (default: 6, max: 168)",
+                            Required:    false,
+                        },
+                    },
+                },
+                {
+                    Type:        discordgo.ApplicationCommandOptionSubCommand,
+                    Name:        "forecast",
+                    Description: "Expected Mac Gym occupancy over the next few hours",
+                },
+            },
         },
         {
             Name:        "badminton",
@@ -32,22 +61,234 @@ func (c *Client) registerCommands() error {
                 },
             },
         },
+        {
+            Name:        "besttime",
+            Description: "Recommend the quietest times to play based on past occupancy",
+            Options: []*discordgo.ApplicationCommandOption{
+                {
+                    Type:        discordgo.ApplicationCommandOptionString,
+                    Name:        "day",
+                    Description: "Day to plan for (default: next 24 hours)",
+                    Required:    false,
+                    Choices:     dayChoices(),
+                },
+            },
+        },
+        {
+            Name:        "facility",
+            Description: "Show occupancy for every tracked facility side by side",
+            Options: []*discordgo.ApplicationCommandOption{
+                {
+                    Type:        discordgo.ApplicationCommandOptionString,
+                    Name:        "name",
+                    Description: "Facility to show, e.g. MAC Gym or SRAC (default: all)",
+                    Required:    false,
+                },
+            },
+        },
         {
             Name:        "subscribe",
             Description: "Subscribe to badminton alerts",
             Options: []*discordgo.ApplicationCommandOption{
                 {
-                    Type:        discordgo.ApplicationCommandOptionInteger,
-                    Name:        "threshold",
-                    Description: "Alert when occupancy reaches this level (default: 0)",
+                    Type:        discordgo.ApplicationCommandOptionSubCommand,
+                    Name:        "occupancy",
+                    Description: "Get alerted when Mac Gym occupancy crosses a threshold",
+                    Options: []*discordgo.ApplicationCommandOption{
+                        {
+                            Type:        discordgo.ApplicationCommandOptionInteger,
+                            Name:        "threshold",
+                            Description: "Number of courts in use to alert at (default: 0, no occupancy alert)",
+                            Required:    false,
+                        },
+                        {
+                            Type:        discordgo.ApplicationCommandOptionString,
+                            Name:        "direction",
+                            Description: "Alert when occupancy rises to the threshold or drops below it (default: above)",
+                            Required:    false,
+                            Choices: []*discordgo.ApplicationCommandOptionChoice{
+                                {Name: "Rises to threshold or more (getting busy)", Value: string(store.Above)},
+                                {Name: "Drops below threshold (courts freeing up)", Value: string(store.Below)},
+                            },
+                        },
+                        {
+                            Type:        discordgo.ApplicationCommandOptionString,
+                            Name:        "days",
+                            Description: "Only alert on these days, e.g. weekdays, weekends or mon,wed,fri (default: every day)",
+                            Required:    false,
+                        },
+                        {
+                            Type:        discordgo.ApplicationCommandOptionString,
+                            Name:        "hours",
+                            Description: "Only alert during these hours, e.g. 17:00-22:00 (default: all day)",
+                            Required:    false,
+                        },
+                    },
+                },
+                {
+                    Type:        discordgo.ApplicationCommandOptionSubCommand,
+                    Name:        "digest",
+                    Description: "Get a scheduled DM summarizing upcoming events and recent occupancy",
+                    Options: []*discordgo.ApplicationCommandOption{
+                        {
+                            Type:        discordgo.ApplicationCommandOptionString,
+                            Name:        "frequency",
+                            Description: "How often to send the digest",
+                            Required:    true,
+                            Choices: []*discordgo.ApplicationCommandOptionChoice{
+                                {Name: "Daily", Value: string(store.DigestDaily)},
+                                {Name: "Weekly", Value: string(store.DigestWeekly)},
+                                {Name: "Off", Value: "off"},
+                            },
+                        },
+                    },
+                },
+            },
+        },
+        {
+            Name:        "subscriptions",
+            Description: "Show your alert, quiet hours and reminder settings",
+        },
+        {
+            Name:        "quiethours",
+            Description: "Mute occupancy alerts during a daily time range",
+            Options: []*discordgo.ApplicationCommandOption{
+                {
+                    Type:        discordgo.ApplicationCommandOptionString,
+                    Name:        "hours",
+                    Description: "Range to mute, e.g. 22:00-08:00, or off to clear (default: show current)",
                     Required:    false,
                 },
             },
         },
+        {
+            Name:        "reminders",
+            Description: "Get a DM before badminton events start",
+            Options: []*discordgo.ApplicationCommandOption{
+                {
+                    Type:        discordgo.ApplicationCommandOptionSubCommand,
+                    Name:        "on",
+                    Description: "Turn on event reminders",
+                    Options: []*discordgo.ApplicationCommandOption{
+                        {
+                            Type:        discordgo.ApplicationCommandOptionInteger,
+                            Name:        "minutes",
+                            Description: "How long before the event to remind you (default: 30)",
+                            Required:    false,
+                            Choices: []*discordgo.ApplicationCommandOptionChoice{
+                                {Name: "15 minutes", Value: 15},
+                                {Name: "30 minutes", Value: 30},
+                                {Name: "1 hour", Value: 60},
+                                {Name: "2 hours", Value: 120},
+                            },
+                        },
+                    },
+                },
+                {
+                    Type:        discordgo.ApplicationCommandOptionSubCommand,
+                    Name:        "off",
+                    Description: "Turn off event reminders",
+                },
+                {
+                    Type:        discordgo.ApplicationCommandOptionSubCommand,
+                    Name:        "status",
+                    Description: "Show your reminder setting",
+                },
+            },
+        },
         {
             Name:        "unsubscribe",
             Description: "Unsubscribe from badminton alerts",
         },
+        {
+            Name:                     "rolealert",
+            Description:              "Ping a role when Mac Gym occupancy crosses a threshold",
+            DefaultMemberPermissions: &guildAdminPermissions,
+            DMPermission:             &dmDisabled,
+            Options: []*discordgo.ApplicationCommandOption{
+                {
+                    Type:        discordgo.ApplicationCommandOptionSubCommand,
+                    Name:        "set",
+                    Description: "Add or change a role's occupancy alert",
+                    Options: []*discordgo.ApplicationCommandOption{
+                        {
+                            Type:        discordgo.ApplicationCommandOptionRole,
+                            Name:        "role",
+                            Description: "Role to ping",
+                            Required:    true,
+                        },
+                        {
+                            Type:        discordgo.ApplicationCommandOptionInteger,
+                            Name:        "threshold",
+                            Description: "Number of courts in use to alert at",
+                            Required:    true,
+                            MinValue:    &minRoleThreshold,
+                        },
+                        {
+                            Type:        discordgo.ApplicationCommandOptionString,
+                            Name:        "direction",
+                            Description: "Alert when occupancy rises to the threshold or drops below it (default: above)",
+                            Required:    false,
+                            Choices: []*discordgo.ApplicationCommandOptionChoice{
+                                {Name: "Rises to threshold or more (getting busy)", Value: string(store.Above)},
+                                {Name: "Drops below threshold (courts freeing up)", Value: string(store.Below)},
+                            },
+                        },
+                        {
+                            Type:        discordgo.ApplicationCommandOptionString,
+                            Name:        "days",
+                            Description: "Only alert on these days, e.g. weekdays, weekends or mon,wed,fri (default: every day)",
+                            Required:    false,
+                        },
+                        {
+                            Type:        discordgo.ApplicationCommandOptionString,
+                            Name:        "hours",
+                            Description: "Only alert during these hours, e.g. 17:00-22:00 (default: all day)",
+                            Required:    false,
+                        },
+                    },
+                },
+                {
+                    Type:        discordgo.ApplicationCommandOptionSubCommand,
+                    Name:        "remove",
+                    Description: "Remove a role's occupancy alert",
+                    Options: []*discordgo.ApplicationCommandOption{
+                        {
+                            Type:        discordgo.ApplicationCommandOptionRole,
+                            Name:        "role",
+                            Description: "Role to stop pinging",
+                            Required:    true,
+                        },
+                    },
+                },
+                {
+                    Type:        discordgo.ApplicationCommandOptionSubCommand,
+                    Name:        "list",
+                    Description: "Show this server's role alerts and alert channel",
+                },
+            },
+        },
+        {
+            Name:                     "alertchannel",
+            Description:              "Set the channel role alerts are posted in",
+            DefaultMemberPermissions: &guildAdminPermissions,
+            DMPermission:             &dmDisabled,
+            Options: []*discordgo.ApplicationCommandOption{
+                {
+                    Type:         discordgo.ApplicationCommandOptionChannel,
+                    Name:         "channel",
+                    Description:  "Channel to post role alerts in (default: clear)",
+                    Required:     false,
+                    ChannelTypes: []discordgo.ChannelType{discordgo.ChannelTypeGuildText},
+                },
+            },
+        },
+        {
+            Name:                     "status",
+            Description:              "Show data source health, circuit breakers and message delivery",
+            DefaultMemberPermissions: &guildAdminPermissions,
+            DMPermission:             &dmDisabled,
+        },
     }
 
     for _, cmd := range cmds {
@@ -74,6 +315,21 @@ func (c *Client) registerCommands() error {
     return nil
 }
 
+// dayChoices lists today, tomorrow and every weekday as string option choices
+func dayChoices() []*discordgo.ApplicationCommandOptionChoice {
+    choices := []*discordgo.ApplicationCommandOptionChoice{
+        {Name: "Today", Value: "today"},
+        {Name: "Tomorrow", Value: "tomorrow"},
+    }
+    for d := time.Sunday; d <= time.Saturday; d++ {
+        choices = append(choices, &discordgo.ApplicationCommandOptionChoice{
+            Name:  d.String(),
+            Value: strings.ToLower(d.String()),
+        })
+    }
+    return choices
+}
+
 func (c *Client) attachHandlers() {
     c.sess.AddHandler(func(s *discordgo.Session, i *discordgo.InteractionCreate) {
         if i.Type != discordgo.InteractionApplicationCommand {
@@ -83,7 +339,7 @@ func (c *Client) attachHandlers() {
         commandName := i.ApplicationCommandData().Name
         slog.Info("Command received", 
             "command", commandName, 
-            "user", i.Member.User.Username,
+            "user", interactionUsername(i),
             "guild", i.GuildID)
         
         switch commandName {
@@ -91,15 +347,34 @@ func (c *Client) attachHandlers() {
             c.handleMacGym(s, i)
         case "badminton":
             c.handleBadminton(s, i)
+        case "besttime":
+            c.handleBestTime(s, i)
+        case "facility":
+            c.handleFacility(s, i)
         case "subscribe":
             c.handleSubscribe(s, i)
+        case "subscriptions":
+            c.handleSubscriptions(s, i)
+        case "quiethours":
+            c.handleQuietHours(s, i)
+        case "reminders":
+            c.handleReminders(s, i)
         case "unsubscribe":
             c.handleUnsubscribe(s, i)
+        case "rolealert":
+            c.handleRoleAlert(s, i)
+        case "alertchannel":
+            c.handleAlertChannel(s, i)
+        case "status":
+            c.handleStatus(s, i)
         default:
             c.ephemeral(s, i, "Unknown command: "+commandName)
         }
     })
     
+    // Prefix ("!") commands arrive as regular messages
+    c.sess.AddHandler(c.handleMessage)
+    
     // Add ready handler
     c.sess.AddHandler(func(s *discordgo.Session, r *discordgo.Ready) {
         slog.Info("Bot is ready", "user", r.User.Username, "guilds", len(r.Guilds))
@@ -120,28 +395,26 @@ func (c *Client) ephemeral(s *discordgo.Session, i *discordgo.InteractionCreate,
     }
 }
 
-func (c *Client) respond(s *discordgo.Session, i *discordgo.InteractionCreate, content string) {
-    err := s.InteractionRespond(i.Interaction, &discordgo.InteractionResponse{
-        Type: discordgo.InteractionResponseChannelMessageWithSource,
-        Data: &discordgo.InteractionResponseData{
-            Content: content,
-        },
-    })
-    
-    if err != nil {
-        slog.Error("Failed to send response", "error", err)
+// respondReply renders a command reply as an interaction response
+func (c *Client) respondReply(s *discordgo.Session, i *discordgo.InteractionCreate, r reply) {
+    data := &discordgo.InteractionResponseData{
+        Content: r.Content,
+        // Replies may mention roles or users but should never ping them
+        AllowedMentions: &discordgo.MessageAllowedMentions{},
     }
-}
-
-func (c *Client) respondWithEmbed(s *discordgo.Session, i *discordgo.InteractionCreate, embed *discordgo.MessageEmbed) {
+    if r.Embed != nil {
+        data.Embeds = []*discordgo.MessageEmbed{r.Embed}
+    }
+    if r.Ephemeral {
+        data.Flags = discordgo.MessageFlagsEphemeral
+    }
+    
     err := s.InteractionRespond(i.Interaction, &discordgo.InteractionResponse{
         Type: discordgo.InteractionResponseChannelMessageWithSource,
-        Data: &discordgo.InteractionResponseData{
-            Embeds: []*discordgo.MessageEmbed{embed},
-        },
+        Data: data,
     })
     
     if err != nil {
-        slog.Error("Failed to send embed response", "error", err)
+        slog.Error("Failed to send response", "error", err)
     }
 }
@@ -1,9 +1,11 @@
 package discord
 
 import (
-    "strconv"
-    "strings"
     "testing"
+
+    "github.com/bwmarrin/discordgo"
+
+    "github.com/sjsu-badminton/badminton-discord-bot/internal/store"
 )
 
 func TestParsePrefixCommand(t *testing.T) {
@@ -59,21 +61,11 @@ func TestParsePrefixCommand(t *testing.T) {
     
     for _, tc := range testCases {
         t.Run(tc.name, func(t *testing.T) {
-            // Simulate the parsing logic from the handler
-            if len(tc.message) == 0 || tc.message[0] != '!' {
-                t.Error("Message should start with !")
-                return
+            commandName, args, ok := parsePrefixCommand(tc.message)
+            if !ok {
+                t.Fatalf("Expected %q to parse as a prefix command", tc.message)
             }
             
-            parts := strings.Fields(tc.message)
-            if len(parts) == 0 {
-                t.Error("Should have at least one part")
-                return
-            }
-            
-            commandName := strings.ToLower(parts[0][1:]) // Remove ! prefix
-            args := parts[1:]
-            
             if commandName != tc.expectedCmd {
                 t.Errorf("Expected command '%s', got '%s'", tc.expectedCmd, commandName)
             }
@@ -96,18 +88,23 @@ func TestCommandValidation(t *testing.T) {
     validCommands := []string{
         "macgym",
         "badminton",
+        "besttime",
+        "facility",
         "subscribe",
+        "subscriptions",
+        "quiethours",
+        "reminders",
         "unsubscribe",
+        "rolealert",
+        "alertchannel",
+        "status",
         "help",
     }
     
     for _, cmd := range validCommands {
         t.Run("valid_"+cmd, func(t *testing.T) {
             // Test that the command would be recognized
-            switch cmd {
-            case "macgym", "badminton", "subscribe", "unsubscribe", "help":
-                // These should be handled
-            default:
+            if _, ok := prefixCommands[cmd]; !ok {
                 t.Errorf("Command '%s' should be valid but isn't handled", cmd)
             }
         })
@@ -122,15 +119,7 @@ func TestCommandValidation(t *testing.T) {
     for _, cmd := range invalidCommands {
         t.Run("invalid_"+cmd, func(t *testing.T) {
             // Test that unknown commands would trigger the default case
-            handled := false
-            switch cmd {
-            case "macgym", "badminton", "subscribe", "unsubscribe", "help":
-                handled = true
-            default:
-                // This should be the case for invalid commands
-            }
-            
-            if handled {
+            if _, handled := prefixCommands[cmd]; handled {
                 t.Errorf("Command '%s' should be invalid but is handled", cmd)
             }
         })
@@ -172,12 +161,7 @@ func TestArgumentParsing(t *testing.T) {
     
     for _, tc := range testCases {
         t.Run(tc.name, func(t *testing.T) {
-            threshold := 0
-            if len(tc.args) > 0 {
-                if t, err := strconv.Atoi(tc.args[0]); err == nil && t >= 0 {
-                    threshold = t
-                }
-            }
+            threshold := parseIntArg(tc.args, 0, 0)
             
             if threshold != tc.expected {
                 t.Errorf("Expected threshold %d, got %d", tc.expected, threshold)
@@ -185,3 +169,82 @@ func TestArgumentParsing(t *testing.T) {
         })
     }
 }
+
+func TestParsePrefixCommandRejectsNonCommands(t *testing.T) {
+    for _, msg := range []string{"", "hello !macgym", "!", "!   ", "/macgym"} {
+        if name, _, ok := parsePrefixCommand(msg); ok {
+            t.Errorf("Expected %q not to parse as a command, got %q", msg, name)
+        }
+    }
+}
+
+func TestParseSubscribeArgs(t *testing.T) {
+    testCases := []struct {
+        args      []string
+        dir       store.Direction
+        threshold int
+        active    string
+        ok        bool
+    }{
+        {[]string{}, store.Above, 0, "any time", true},
+        {[]string{"5"}, store.Above, 5, "any time", true},
+        {[]string{"above", "6"}, store.Above, 6, "any time", true},
+        {[]string{"BELOW", "3"}, store.Below, 3, "any time", true},
+        {[]string{"below"}, store.Below, 0, "any time", true},
+        {[]string{"sideways", "3"}, store.Above, 0, "", false},
+        {[]string{"below", "3", "weekdays", "17:00-22:00"}, store.Below, 3, "weekdays, 17:00–22:00", true},
+        {[]string{"6", "5pm-10pm", "mon,wed"}, store.Above, 6, "Mon, Wed, 17:00–22:00", true},
+        {[]string{"6", "someday"}, store.Above, 6, "", false},
+        {[]string{"foo"}, store.Above, 0, "", false},
+        {[]string{"below", "-2"}, store.Below, 0, "", false},
+        {[]string{"5", "mon", "wed"}, store.Above, 5, "Mon, Wed, all day", true},
+        {[]string{"4", "mon", "17:00-22:00", "fri"}, store.Above, 4, "Mon, Fri, 17:00–22:00", true},
+        {[]string{"weekends"}, store.Above, 0, "weekends, all day", true},
+        {[]string{"5", "every", "day"}, store.Above, 5, "any time", true},
+        {[]string{"5", "9am-noon", "5pm-10pm"}, store.Above, 5, "", false},
+        {[]string{"5", "6"}, store.Above, 5, "", false},
+    }
+
+    for _, tc := range testCases {
+        dir, threshold, active, err := parseSubscribeArgs(tc.args)
+        if (err == nil) != tc.ok {
+            t.Errorf("parseSubscribeArgs(%v) error = %v, want ok=%v", tc.args, err, tc.ok)
+            continue
+        }
+        if !tc.ok {
+            continue
+        }
+        if dir != tc.dir || threshold != tc.threshold || active.String() != tc.active {
+            t.Errorf("parseSubscribeArgs(%v) = %s, %d, %q; want %s, %d, %q",
+                tc.args, dir, threshold, active, tc.dir, tc.threshold, tc.active)
+        }
+    }
+}
+
+func TestInteractionUser(t *testing.T) {
+    guildUser := &discordgo.User{ID: "1", Username: "guild-user"}
+    dmUser := &discordgo.User{ID: "2", Username: "dm-user"}
+
+    testCases := []struct {
+        name     string
+        i        *discordgo.Interaction
+        wantID   string
+        wantName string
+    }{
+        {name: "guild", i: &discordgo.Interaction{Member: &discordgo.Member{User: guildUser}}, wantID: "1", wantName: "guild-user"},
+        {name: "DM", i: &discordgo.Interaction{User: dmUser}, wantID: "2", wantName: "dm-user"},
+        {name: "neither", i: &discordgo.Interaction{}},
+    }
+
+    for _, tc := range testCases {
+        t.Run(tc.name, func(t *testing.T) {
+            ic := &discordgo.InteractionCreate{Interaction: tc.i}
+            if got := interactionUserID(ic); got != tc.wantID {
+                t.Errorf("interactionUserID() = %q, want %q", got, tc.wantID)
+            }
+            if got := interactionUsername(ic); got != tc.wantName {
+                t.Errorf("interactionUsername() = %q, want %q", got, tc.wantName)
+            }
+        })
+    }
+}
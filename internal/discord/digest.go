@@ -0,0 +1,93 @@
+package discord
+
+import (
+    "fmt"
+    "log/slog"
+    "strings"
+    "time"
+
+    "github.com/bwmarrin/discordgo"
+
+    "github.com/sjsu-badminton/badminton-discord-bot/internal/sched"
+    "github.com/sjsu-badminton/badminton-discord-bot/internal/store"
+    "github.com/sjsu-badminton/badminton-discord-bot/internal/util"
+)
+
+// maxDigestEvents keeps the events field under Discord's 1024 character limit
+const maxDigestEvents = 8
+
+// SendDigest DMs a digest to a subscriber. It implements sched.Notifier.
+func (c *Client) SendDigest(userID string, d sched.Digest) {
+    embed := digestEmbed(d, util.MustLocation(c.cfg.TZ))
+
+    if err := c.sendDM(kindDigest, userID, &discordgo.MessageSend{Embeds: []*discordgo.MessageEmbed{embed}}); err != nil {
+        slog.Error("Failed to queue digest",
+            "userID", userID,
+            "frequency", d.Frequency,
+            "error", err)
+    }
+}
+
+func digestEmbed(d sched.Digest, loc *time.Location) *discordgo.MessageEmbed {
+    title := "🗞️ Daily Badminton Digest"
+    eventsName := "📅 Events in the Next 24 Hours"
+    occupancyName := fmt.Sprintf("📈 Mac Gym Yesterday (%s)", d.From.In(loc).Format("Mon, Jan 2"))
+    if d.Frequency == store.DigestWeekly {
+        title = "🗞️ Weekly Badminton Digest"
+        eventsName = "📅 Events This Week"
+        occupancyName = fmt.Sprintf("📈 Mac Gym Last Week (%s – %s)",
+            d.From.In(loc).Format("Jan 2"),
+            d.Until.Add(-time.Second).In(loc).Format("Jan 2"))
+    }
+
+    embed := &discordgo.MessageEmbed{
+        Title: title,
+        Color: 0x0099ff,
+        Footer: &discordgo.MessageEmbedFooter{
+            Text: "SJSU Badminton Bot • /subscribe digest off to stop",
+        },
+    }
+
+    embed.Fields = append(embed.Fields,
+        &discordgo.MessageEmbedField{Name: eventsName, Value: digestEvents(d.Events, loc)},
+        &discordgo.MessageEmbedField{Name: occupancyName, Value: digestOccupancy(d)},
+    )
+
+    if len(d.Quietest) > 0 {
+        capacity := d.Occupancy.Capacity
+        embed.Fields = append(embed.Fields,
+            &discordgo.MessageEmbedField{Name: "😌 Quietest Hours", Value: formatWindows(d.Quietest, capacity), Inline: true},
+            &discordgo.MessageEmbedField{Name: "🔥 Busiest Hours", Value: formatWindows(d.Busiest, capacity), Inline: true},
+        )
+    }
+
+    return embed
+}
+
+func digestEvents(events []store.Event, loc *time.Location) string {
+    if len(events) == 0 {
+        return "No badminton events scheduled."
+    }
+
+    var b strings.Builder
+    for i, e := range events {
+        if i == maxDigestEvents {
+            fmt.Fprintf(&b, "…and %d more. Use `/badminton events` to see them all.", len(events)-maxDigestEvents)
+            break
+        }
+        fmt.Fprintf(&b, "**%s** · %s – %s\n",
+            e.Title,
+            e.Start.In(loc).Format("Mon 3:04 PM"),
+            e.End.In(loc).Format("3:04 PM"))
+    }
+    return b.String()
+}
+
+func digestOccupancy(d sched.Digest) string {
+    sum := d.Occupancy
+    if sum.Samples == 0 {
+        return "No occupancy readings were recorded."
+    }
+    return fmt.Sprintf("Min %d · avg %.1f · max %d of %d courts in use (%d readings)",
+        sum.Min, sum.Avg, sum.Max, sum.Capacity, sum.Samples)
+}
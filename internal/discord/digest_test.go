@@ -0,0 +1,52 @@
+package discord
+
+import (
+    "strings"
+    "testing"
+    "time"
+
+    "github.com/sjsu-badminton/badminton-discord-bot/internal/occupancy"
+    "github.com/sjsu-badminton/badminton-discord-bot/internal/sched"
+    "github.com/sjsu-badminton/badminton-discord-bot/internal/store"
+)
+
+func TestDigestEmbed(t *testing.T) {
+    from := time.Date(2024, 1, 8, 0, 0, 0, 0, time.UTC)
+    start := time.Date(2024, 1, 15, 18, 0, 0, 0, time.UTC)
+
+    var events []store.Event
+    for i := 0; i < maxDigestEvents+2; i++ {
+        events = append(events, store.Event{Title: "Open Play", Start: start.AddDate(0, 0, i), End: start.AddDate(0, 0, i).Add(2 * time.Hour)})
+    }
+
+    d := sched.Digest{
+        Frequency: store.DigestWeekly,
+        Events:    events,
+        From:      from,
+        Until:     from.AddDate(0, 0, 7),
+        Occupancy: occupancy.Summary{Samples: 42, Min: 1, Max: 7, Avg: 3.5, Capacity: 8},
+    }
+
+    embed := digestEmbed(d, time.UTC)
+    if embed.Title != "🗞️ Weekly Badminton Digest" {
+        t.Errorf("Unexpected title %q", embed.Title)
+    }
+    if len(embed.Fields) != 2 {
+        t.Fatalf("Expected events and occupancy fields without hourly windows, got %d", len(embed.Fields))
+    }
+    if name := embed.Fields[1].Name; name != "📈 Mac Gym Last Week (Jan 8 – Jan 14)" {
+        t.Errorf("Unexpected occupancy field name %q", name)
+    }
+    if !strings.Contains(embed.Fields[0].Value, "…and 2 more") {
+        t.Errorf("Expected events to be truncated, got %q", embed.Fields[0].Value)
+    }
+    if got := embed.Fields[1].Value; got != "Min 1 · avg 3.5 · max 7 of 8 courts in use (42 readings)" {
+        t.Errorf("Unexpected occupancy summary %q", got)
+    }
+
+    d.Events, d.Occupancy = nil, occupancy.Summary{}
+    embed = digestEmbed(d, time.UTC)
+    if embed.Fields[0].Value != "No badminton events scheduled." || embed.Fields[1].Value != "No occupancy readings were recorded." {
+        t.Errorf("Unexpected empty digest fields: %q, %q", embed.Fields[0].Value, embed.Fields[1].Value)
+    }
+}
@@ -0,0 +1,138 @@
+package discord
+
+import (
+    "fmt"
+    "strings"
+    "time"
+
+    "github.com/bwmarrin/discordgo"
+
+    "github.com/sjsu-badminton/badminton-discord-bot/internal/sched"
+    "github.com/sjsu-badminton/badminton-discord-bot/internal/store"
+)
+
+// maxFacilityFields keeps /facility within Discord's 25 embed fields,
+// leaving room for a stale data warning
+const maxFacilityFields = 24
+
+func (c *Client) handleFacility(s *discordgo.Session, i *discordgo.InteractionCreate) {
+    name := ""
+    if opts := i.ApplicationCommandData().Options; len(opts) > 0 {
+        name = opts[0].StringValue()
+    }
+
+    c.respondReply(s, i, c.facilityReply(name))
+}
+
+// facilityReply shows every tracked facility, or those matching name
+func (c *Client) facilityReply(name string) reply {
+    snap := c.store.GetMac()
+    if len(snap.Facilities) == 0 {
+        return reply{Content: "No facility data yet. Try again in a few minutes.", Ephemeral: true}
+    }
+
+    facilities := matchFacilities(snap.Facilities, name)
+    if len(facilities) == 0 {
+        names := make([]string, 0, len(snap.Facilities))
+        for _, f := range snap.Facilities {
+            names = append(names, f.Name)
+        }
+        return reply{
+            Content:   fmt.Sprintf("❌ No facility matching %q. Tracked facilities: %s.", name, strings.Join(names, ", ")),
+            Ephemeral: true,
+        }
+    }
+
+    warning := staleWarning(snap.RetrievedAt, c.cfg.MacGymStaleAfter, time.Now(), c.sourceStatus(sched.SourceMacGym))
+    return reply{Embed: facilityEmbed(facilities, snap.RetrievedAt, warning)}
+}
+
+// matchFacilities returns the facilities whose ID or name is name, or failing
+// that whose name contains it. An empty name matches every facility.
+func matchFacilities(facilities []store.Facility, name string) []store.Facility {
+    name = strings.TrimSpace(name)
+    if name == "" {
+        return facilities
+    }
+
+    var exact, partial []store.Facility
+    for _, f := range facilities {
+        switch {
+        case strings.EqualFold(f.ID, name), strings.EqualFold(f.Name, name):
+            exact = append(exact, f)
+        case strings.Contains(strings.ToLower(f.Name), strings.ToLower(name)):
+            partial = append(partial, f)
+        }
+    }
+    if len(exact) > 0 {
+        return exact
+    }
+    return partial
+}
+
+// facilityEmbed lays facilities out side by side, marking the ones counted
+// as badminton courts
+func facilityEmbed(facilities []store.Facility, retrievedAt time.Time, warning string) *discordgo.MessageEmbed {
+    embed := &discordgo.MessageEmbed{
+        Title:     "🏢 Facility Occupancy",
+        Color:     0x0099ff,
+        Timestamp: retrievedAt.Format(time.RFC3339),
+        Footer: &discordgo.MessageEmbedFooter{
+            Text: "🏸 marks the badminton courts • SJSU Badminton Bot",
+        },
+    }
+    if len(facilities) == 1 {
+        embed.Title = "🏢 " + facilities[0].Name
+    }
+
+    for i, f := range facilities {
+        if i == maxFacilityFields {
+            embed.Description = fmt.Sprintf("Showing %d of %d facilities; use `/facility <name>` to find the rest.", maxFacilityFields, len(facilities))
+            break
+        }
+
+        name := f.Name
+        if f.Courts {
+            name = "🏸 " + name
+        }
+        embed.Fields = append(embed.Fields, &discordgo.MessageEmbedField{
+            Name:   name,
+            Value:  describeFacility(f),
+            Inline: true,
+        })
+    }
+
+    if warning != "" {
+        embed.Color = staleColor
+        embed.Fields = append(embed.Fields, &discordgo.MessageEmbedField{
+            Name:  "⚠️ Stale Data",
+            Value: warning,
+        })
+    }
+
+    return embed
+}
+
+func describeFacility(f store.Facility) string {
+    var value string
+    switch {
+    case f.Closed:
+        value = "⚫ Closed"
+    case f.Capacity > 0:
+        full := float64(f.Count) / float64(f.Capacity) * 100
+        indicator := "🟢"
+        if full >= 75 {
+            indicator = "🔴"
+        } else if full >= 50 {
+            indicator = "🟠"
+        }
+        value = fmt.Sprintf("%s %d / %d (%.0f%% full)", indicator, f.Count, f.Capacity, full)
+    default:
+        value = fmt.Sprintf("Last count: %d", f.Count)
+    }
+
+    if !f.Updated.IsZero() {
+        value += fmt.Sprintf("\nUpdated <t:%d:R>", f.Updated.Unix())
+    }
+    return value
+}
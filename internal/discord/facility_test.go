@@ -0,0 +1,90 @@
+package discord
+
+import (
+    "strings"
+    "testing"
+    "time"
+
+    "github.com/sjsu-badminton/badminton-discord-bot/internal/store"
+)
+
+func TestMatchFacilities(t *testing.T) {
+    facilities := []store.Facility{
+        {ID: "5634", Name: "MAC Gym"},
+        {ID: "5640", Name: "SRAC Court 1"},
+        {ID: "5641", Name: "SRAC Court 2"},
+        {ID: "5650", Name: "SRAC"},
+    }
+
+    testCases := []struct {
+        name string
+        want []string
+    }{
+        {name: "", want: []string{"MAC Gym", "SRAC Court 1", "SRAC Court 2", "SRAC"}},
+        {name: "mac", want: []string{"MAC Gym"}},
+        {name: "srac", want: []string{"SRAC"}},
+        {name: "court", want: []string{"SRAC Court 1", "SRAC Court 2"}},
+        {name: "5641", want: []string{"SRAC Court 2"}},
+        {name: "pool"},
+    }
+
+    for _, tc := range testCases {
+        t.Run(tc.name, func(t *testing.T) {
+            var got []string
+            for _, f := range matchFacilities(facilities, tc.name) {
+                got = append(got, f.Name)
+            }
+            if strings.Join(got, ",") != strings.Join(tc.want, ",") {
+                t.Errorf("Expected %v, got %v", tc.want, got)
+            }
+        })
+    }
+}
+
+func TestFacilityEmbed(t *testing.T) {
+    updated := time.Date(2024, 1, 15, 12, 0, 0, 0, time.UTC)
+    facilities := []store.Facility{
+        {Name: "MAC Gym", Count: 10, Capacity: 150, Updated: updated, Courts: true},
+        {Name: "Event Center", Count: 100, Capacity: 120},
+        {Name: "Aquatic Center", Closed: true},
+        {Name: "Weight Room", Count: 7},
+    }
+
+    embed := facilityEmbed(facilities, updated, "")
+    if len(embed.Fields) != 4 {
+        t.Fatalf("Expected 4 fields, got %d", len(embed.Fields))
+    }
+
+    want := []struct{ name, value string }{
+        {"🏸 MAC Gym", "🟢 10 / 150 (7% full)\nUpdated <t:1705320000:R>"},
+        {"Event Center", "🔴 100 / 120 (83% full)"},
+        {"Aquatic Center", "⚫ Closed"},
+        {"Weight Room", "Last count: 7"},
+    }
+    for i, w := range want {
+        f := embed.Fields[i]
+        if f.Name != w.name || f.Value != w.value || !f.Inline {
+            t.Errorf("Field %d: expected %q / %q inline, got %q / %q", i, w.name, w.value, f.Name, f.Value)
+        }
+    }
+
+    stale := facilityEmbed(facilities[:1], updated, "Last updated a while ago.")
+    if stale.Title != "🏢 MAC Gym" || stale.Color != staleColor || stale.Fields[len(stale.Fields)-1].Value != "Last updated a while ago." {
+        t.Errorf("Expected a single-facility embed with a stale warning, got %+v", stale)
+    }
+}
+
+func TestFacilityEmbedFieldLimit(t *testing.T) {
+    facilities := make([]store.Facility, 30)
+    for i := range facilities {
+        facilities[i] = store.Facility{Name: "Room", Count: i}
+    }
+
+    embed := facilityEmbed(facilities, time.Now(), "stale")
+    if len(embed.Fields) > 25 {
+        t.Errorf("Expected at most 25 fields, got %d", len(embed.Fields))
+    }
+    if !strings.Contains(embed.Description, "Showing 24 of 30") {
+        t.Errorf("Expected a truncation note, got %q", embed.Description)
+    }
+}
@@ -2,14 +2,187 @@ package discord
 
 import (
     "fmt"
+    "strings"
     "time"
 
     "github.com/bwmarrin/discordgo"
+
+    "github.com/sjsu-badminton/badminton-discord-bot/internal/classify"
+    "github.com/sjsu-badminton/badminton-discord-bot/internal/sched"
+    "github.com/sjsu-badminton/badminton-discord-bot/internal/store"
 )
 
+const (
+    defaultEventDays = 7
+    maxEventDays     = 30
+
+    // defaultViewDays is how far ahead the fitness schedule's default view,
+    // fetched when FITNESS_WEEKS is 0, is assumed to reach
+    defaultViewDays = 7
+)
+
+// reply is a command response that can be rendered either as an interaction
+// response (slash commands) or as a channel message (prefix commands)
+type reply struct {
+    Content   string
+    Embed     *discordgo.MessageEmbed
+    Ephemeral bool
+}
+
 func (c *Client) handleMacGym(s *discordgo.Session, i *discordgo.InteractionCreate) {
+    opts := i.ApplicationCommandData().Options
+
+    if len(opts) > 0 {
+        switch opts[0].Name {
+        case "history":
+            hours := defaultHistoryHours
+            if len(opts[0].Options) > 0 {
+                hours = int(opts[0].Options[0].IntValue())
+            }
+            c.respondReply(s, i, c.macGymHistoryReply(hours))
+            return
+        case "forecast":
+            c.respondReply(s, i, c.macGymForecastReply())
+            return
+        }
+    }
+
+    c.respondReply(s, i, c.macGymReply())
+}
+
+func (c *Client) handleBadminton(s *discordgo.Session, i *discordgo.InteractionCreate) {
+    opts := i.ApplicationCommandData().Options
+    days := defaultEventDays
+
+    // Extract days parameter from subcommand
+    if len(opts) > 0 && len(opts[0].Options) > 0 {
+        if v := opts[0].Options[0].IntValue(); v > 0 {
+            days = int(v)
+        }
+    }
+
+    c.respondReply(s, i, c.eventsReply(days))
+}
+
+func (c *Client) handleBestTime(s *discordgo.Session, i *discordgo.InteractionCreate) {
+    day := ""
+    if opts := i.ApplicationCommandData().Options; len(opts) > 0 {
+        day = opts[0].StringValue()
+    }
+
+    c.respondReply(s, i, c.bestTimeReply(day))
+}
+
+func (c *Client) handleSubscribe(s *discordgo.Session, i *discordgo.InteractionCreate) {
+    opts := i.ApplicationCommandData().Options
+    if len(opts) == 0 {
+        c.ephemeral(s, i, "❌ Choose `/subscribe occupancy` or `/subscribe digest`.")
+        return
+    }
+
+    if opts[0].Name == "digest" {
+        frequency := ""
+        if len(opts[0].Options) > 0 {
+            frequency = opts[0].Options[0].StringValue()
+        }
+        c.respondReply(s, i, c.digestReply(interactionUserID(i), frequency))
+        return
+    }
+
+    threshold := 0
+    dir := store.Above
+    var days, hours string
+
+    // Extract threshold, direction and active window parameters
+    for _, opt := range opts[0].Options {
+        switch opt.Name {
+        case "threshold":
+            threshold = int(opt.IntValue())
+        case "direction":
+            dir = store.Direction(opt.StringValue())
+        case "days":
+            days = opt.StringValue()
+        case "hours":
+            hours = opt.StringValue()
+        }
+    }
+
+    active, err := parseWindow(days, hours)
+    if err != nil {
+        c.ephemeral(s, i, fmt.Sprintf("❌ %v. Use days like `weekdays` or `mon,wed,fri` and hours like `17:00-22:00`.", err))
+        return
+    }
+
+    c.respondReply(s, i, c.subscribeReply(interactionUserID(i), threshold, dir, active))
+}
+
+func (c *Client) handleQuietHours(s *discordgo.Session, i *discordgo.InteractionCreate) {
+    hours := ""
+    if opts := i.ApplicationCommandData().Options; len(opts) > 0 {
+        hours = opts[0].StringValue()
+    }
+
+    c.respondReply(s, i, c.quietHoursReply(interactionUserID(i), hours))
+}
+
+func (c *Client) handleSubscriptions(s *discordgo.Session, i *discordgo.InteractionCreate) {
+    c.respondReply(s, i, c.subscriptionsReply(interactionUserID(i)))
+}
+
+func (c *Client) handleReminders(s *discordgo.Session, i *discordgo.InteractionCreate) {
+    userID := interactionUserID(i)
+    opts := i.ApplicationCommandData().Options
+
+    if len(opts) > 0 {
+        switch opts[0].Name {
+        case "on":
+            minutes := defaultReminderMinutes
+            if len(opts[0].Options) > 0 {
+                minutes = int(opts[0].Options[0].IntValue())
+            }
+            c.respondReply(s, i, c.remindersOnReply(userID, minutes))
+            return
+        case "off":
+            c.respondReply(s, i, c.remindersOffReply(userID))
+            return
+        }
+    }
+
+    c.respondReply(s, i, c.remindersStatusReply(userID))
+}
+
+func (c *Client) handleUnsubscribe(s *discordgo.Session, i *discordgo.InteractionCreate) {
+    c.respondReply(s, i, c.unsubscribeReply(interactionUserID(i)))
+}
+
+// interactionUser returns the invoking user for guild and DM interactions:
+// Member is only set in guilds, User only in DMs
+func interactionUser(i *discordgo.InteractionCreate) *discordgo.User {
+    if i.Member != nil && i.Member.User != nil {
+        return i.Member.User
+    }
+    return i.User
+}
+
+// interactionUserID returns the invoking user's ID, or "" if there is none
+func interactionUserID(i *discordgo.InteractionCreate) string {
+    if u := interactionUser(i); u != nil {
+        return u.ID
+    }
+    return ""
+}
+
+// interactionUsername returns the invoking user's name for logs
+func interactionUsername(i *discordgo.InteractionCreate) string {
+    if u := interactionUser(i); u != nil {
+        return u.Username
+    }
+    return ""
+}
+
+func (c *Client) macGymReply() reply {
     snap := c.store.GetMac()
-    
+
     // Create embed
     embed := &discordgo.MessageEmbed{
         Title:       "🏸 Mac Gym — Badminton Occupancy",
@@ -20,7 +193,7 @@ func (c *Client) handleMacGym(s *discordgo.Session, i *discordgo.InteractionCrea
             Text: "SJSU Badminton Bot",
         },
     }
-    
+
     // Add capacity information if available
     if snap.Capacity > 0 {
         embed.Fields = append(embed.Fields, &discordgo.MessageEmbedField{
@@ -28,7 +201,7 @@ func (c *Client) handleMacGym(s *discordgo.Session, i *discordgo.InteractionCrea
             Value:  fmt.Sprintf("%d / %d", snap.InUse, snap.Capacity),
             Inline: true,
         })
-        
+
         // Add availability percentage
         availability := float64(snap.Capacity-snap.InUse) / float64(snap.Capacity) * 100
         embed.Fields = append(embed.Fields, &discordgo.MessageEmbedField{
@@ -36,7 +209,7 @@ func (c *Client) handleMacGym(s *discordgo.Session, i *discordgo.InteractionCrea
             Value:  fmt.Sprintf("%.1f%%", availability),
             Inline: true,
         })
-        
+
         // Set color based on availability
         if availability > 50 {
             embed.Color = 0x00ff00 // Green
@@ -46,30 +219,51 @@ func (c *Client) handleMacGym(s *discordgo.Session, i *discordgo.InteractionCrea
             embed.Color = 0xff0000 // Red
         }
     }
-    
+
     // Add last updated info
     embed.Fields = append(embed.Fields, &discordgo.MessageEmbedField{
         Name:   "Last Updated",
         Value:  snap.RetrievedAt.Format("Mon, Jan 2 3:04 PM"),
         Inline: true,
     })
-    
-    c.respondWithEmbed(s, i, embed)
+
+    now := time.Now()
+    if warning := staleWarning(snap.RetrievedAt, c.cfg.MacGymStaleAfter, now, c.sourceStatus(sched.SourceMacGym)); warning != "" {
+        embed.Color = staleColor
+        embed.Fields = append(embed.Fields, &discordgo.MessageEmbedField{
+            Name:  "⚠️ Stale Data",
+            Value: warning,
+        })
+    }
+
+    // Add short-term forecast if there's enough data
+    if preds := c.predict(now, time.Hour, 2*time.Hour); len(preds) > 0 {
+        embed.Fields = append(embed.Fields, &discordgo.MessageEmbedField{
+            Name:   "Expected",
+            Value:  expectedSummary(preds, now),
+            Inline: false,
+        })
+    }
+
+    return reply{Embed: embed}
 }
 
-func (c *Client) handleBadminton(s *discordgo.Session, i *discordgo.InteractionCreate) {
-    opts := i.ApplicationCommandData().Options
-    days := 7
-    
-    // Extract days parameter from subcommand
-    if len(opts) > 0 && len(opts[0].Options) > 0 {
-        if v := opts[0].Options[0].IntValue(); v > 0 {
-            days = int(v)
-        }
+func (c *Client) eventsReply(days int) reply {
+    if days > maxEventDays {
+        days = maxEventDays
     }
-    
-    events := c.store.ListUpcoming(time.Now(), days)
-    
+
+    // Past the fetched range, an empty listing would look like no events
+    var note string
+    if fetched := c.scheduleDays(); days > fetched {
+        note = fmt.Sprintf("ℹ️ Only the next %d days of the schedule are fetched, so later events aren't listed.", fetched)
+        days = fetched
+    }
+
+    now := time.Now()
+    events := c.store.ListUpcoming(now, days)
+    warning := staleWarning(c.store.SourceLastSeen(sched.SourceFitness), c.cfg.EventsStaleAfter, now, c.sourceStatus(sched.SourceFitness))
+
     if len(events) == 0 {
         embed := &discordgo.MessageEmbed{
             Title:       "🏸 Upcoming Badminton Events",
@@ -79,10 +273,16 @@ func (c *Client) handleBadminton(s *discordgo.Session, i *discordgo.InteractionC
                 Text: "SJSU Badminton Bot",
             },
         }
-        c.respondWithEmbed(s, i, embed)
-        return
+        if note != "" {
+            embed.Description += "\n\n" + note
+        }
+        if warning != "" {
+            embed.Description += "\n\n⚠️ " + warning
+            embed.Color = staleColor
+        }
+        return reply{Embed: embed}
     }
-    
+
     // Create embed with events
     embed := &discordgo.MessageEmbed{
         Title:       fmt.Sprintf("🏸 Upcoming Badminton Events (%d days)", days),
@@ -92,51 +292,134 @@ func (c *Client) handleBadminton(s *discordgo.Session, i *discordgo.InteractionC
             Text: "SJSU Badminton Bot",
         },
     }
-    
+
     // Add events as fields (Discord limit is 25 fields)
     maxEvents := 10
     if len(events) > maxEvents {
         events = events[:maxEvents]
         embed.Description += fmt.Sprintf(" (showing first %d)", maxEvents)
     }
-    
+    if note != "" {
+        embed.Description += "\n\n" + note
+    }
+    if warning != "" {
+        embed.Description += "\n\n⚠️ " + warning
+        embed.Color = staleColor
+    }
+
     for _, event := range events {
         fieldValue := fmt.Sprintf("**Time:** %s - %s\n**Location:** %s",
             event.Start.Format("Mon, Jan 2 3:04 PM"),
             event.End.Format("3:04 PM"),
             event.Location)
-        
+        if kinds := eventKinds(event.Tags); kinds != "" {
+            fieldValue += "\n**Type:** " + kinds
+        }
+        if event.RegisterURL != "" {
+            fieldValue += fmt.Sprintf("\n[Register](%s)", event.RegisterURL)
+        }
+
         embed.Fields = append(embed.Fields, &discordgo.MessageEmbedField{
             Name:   event.Title,
             Value:  fieldValue,
             Inline: false,
         })
     }
-    
-    c.respondWithEmbed(s, i, embed)
+
+    return reply{Embed: embed}
 }
 
-func (c *Client) handleSubscribe(s *discordgo.Session, i *discordgo.InteractionCreate) {
-    threshold := 0
-    
-    // Extract threshold parameter
-    if len(i.ApplicationCommandData().Options) > 0 {
-        threshold = int(i.ApplicationCommandData().Options[0].IntValue())
-    }
-    
-    c.store.Subscribe(i.Member.User.ID, threshold)
-    
+// scheduleDays returns how many days ahead the fitness schedule is fetched
+func (c *Client) scheduleDays() int {
+    if c.cfg.FitnessWeeks > 0 {
+        return 7 * c.cfg.FitnessWeeks
+    }
+    return defaultViewDays
+}
+
+// eventKinds lists the tags the classification rules gave an event, leaving
+// out the ones every event or every fallback event has
+func eventKinds(tags []string) string {
+    var kinds []string
+    for _, tag := range tags {
+        if tag != classify.TagBadminton && tag != "fallback" {
+            kinds = append(kinds, tag)
+        }
+    }
+    return strings.Join(kinds, ", ")
+}
+
+func (c *Client) subscribeReply(userID string, threshold int, dir store.Direction, active store.Window) reply {
+    if threshold < 0 {
+        return reply{Content: "❌ Threshold can't be negative.", Ephemeral: true}
+    }
+
+    sub := store.NewSubscription(userID, threshold, dir)
+    sub.Active = active
+    c.store.Subscribe(sub)
+
     var message string
-    if threshold > 0 {
-        message = fmt.Sprintf("✅ Subscribed to alerts! You'll be notified when Mac Gym occupancy reaches %d or higher.", threshold)
-    } else {
+    switch {
+    case threshold == 0:
         message = "✅ Subscribed to alerts! You'll be notified about new badminton events and Mac Gym updates."
+    case sub.Direction == store.Below:
+        message = fmt.Sprintf("✅ Subscribed to alerts! You'll be notified when fewer than %d courts are in use at Mac Gym.", threshold)
+    default:
+        message = fmt.Sprintf("✅ Subscribed to alerts! You'll be notified when Mac Gym occupancy reaches %d or higher.", threshold)
+    }
+    if threshold > 0 && !active.IsZero() {
+        message += fmt.Sprintf(" Alerts are only sent %s (%s).", active, c.cfg.TZ)
+    }
+
+    return reply{Content: message, Ephemeral: true}
+}
+
+func (c *Client) unsubscribeReply(userID string) reply {
+    c.store.Unsubscribe(userID)
+    c.store.ClearReminders(userID)
+    c.store.SetDigest(userID, "")
+    return reply{Content: "✅ You have been unsubscribed from all badminton alerts, reminders and digests.", Ephemeral: true}
+}
+
+func (c *Client) badmintonInfoReply() reply {
+    embed := &discordgo.MessageEmbed{
+        Title:       "🏸 SJSU Badminton",
+        Description: "Use `!badminton events [days]` (or `/badminton events`) to list upcoming badminton events.",
+        Color:       0x0099ff,
+        Footer: &discordgo.MessageEmbedFooter{
+            Text: "SJSU Badminton Bot",
+        },
     }
-    
-    c.ephemeral(s, i, message)
+    return reply{Embed: embed}
 }
 
-func (c *Client) handleUnsubscribe(s *discordgo.Session, i *discordgo.InteractionCreate) {
-    c.store.Unsubscribe(i.Member.User.ID)
-    c.ephemeral(s, i, "✅ You have been unsubscribed from all badminton alerts.")
+func (c *Client) helpReply() reply {
+    embed := &discordgo.MessageEmbed{
+        Title:       "🏸 SJSU Badminton Bot — Commands",
+        Description: "Every command is available as a slash command (`/`) or with the `!` prefix.",
+        Color:       0x0099ff,
+        Fields: []*discordgo.MessageEmbedField{
+            {Name: "!macgym", Value: "Current Mac Gym badminton court occupancy"},
+            {Name: "!macgym forecast", Value: "Expected occupancy over the next few hours"},
+            {Name: "!macgym history [hours]", Value: fmt.Sprintf("Min/avg/max occupancy and trend (default: %d hours)", defaultHistoryHours)},
+            {Name: "!badminton events [days]", Value: fmt.Sprintf("Upcoming badminton events (default: %d, max: %d days)", defaultEventDays, maxEventDays)},
+            {Name: "!besttime [day]", Value: "Quietest and busiest hours from past occupancy (today, tomorrow or a weekday)"},
+            {Name: "!facility [name]", Value: "Occupancy of every tracked facility (Mac Gym, SRAC, ...) side by side"},
+            {Name: "!subscribe [above|below] [threshold] [days] [hours]", Value: "Alert me when Mac Gym occupancy rises to the threshold, or drops below it, optionally only on some days and hours"},
+            {Name: "!subscribe digest daily|weekly|off", Value: "Get a scheduled DM with upcoming events and recent occupancy"},
+            {Name: "!subscriptions", Value: "Show your alert, quiet hours and reminder settings"},
+            {Name: "!quiethours [range | off]", Value: "Mute occupancy alerts during a daily range, e.g. `22:00-08:00`"},
+            {Name: "!reminders [on [minutes] | off]", Value: fmt.Sprintf("DM me before events start (default: %d minutes)", defaultReminderMinutes)},
+            {Name: "!unsubscribe", Value: "Stop all badminton alerts and reminders"},
+            {Name: "!rolealert set @role [above|below] <threshold> [days] [hours]", Value: "Ping a role in the alert channel when occupancy crosses the threshold (Manage Server)"},
+            {Name: "!rolealert remove @role | list", Value: "Remove a role alert or list this server's role alerts (Manage Server)"},
+            {Name: "!alertchannel #channel | off", Value: "Choose where this server's role alerts are posted (Manage Server)"},
+            {Name: "!status", Value: "Data source health, circuit breakers and message delivery (Manage Server)"},
+            {Name: "!help", Value: "Show this message"},
+        },
+        Footer: &discordgo.MessageEmbedFooter{
+            Text: "SJSU Badminton Bot",
+        },
+    }
+    return reply{Embed: embed}
 }
@@ -0,0 +1,43 @@
+package discord
+
+import (
+    "strings"
+    "testing"
+    "time"
+
+    "github.com/sjsu-badminton/badminton-discord-bot/internal/config"
+    "github.com/sjsu-badminton/badminton-discord-bot/internal/store"
+)
+
+func TestEventsReplyCapsDaysToFetchedRange(t *testing.T) {
+    testCases := []struct {
+        name      string
+        weeks     int
+        days      int
+        wantTitle string
+        wantNote  string
+    }{
+        {name: "default view", weeks: 0, days: 30, wantTitle: "(7 days)", wantNote: "Only the next 7 days"},
+        {name: "within the default view", weeks: 0, days: 5, wantTitle: "(5 days)"},
+        {name: "ranged fetch", weeks: 5, days: 30, wantTitle: "(30 days)"},
+        {name: "short ranged fetch", weeks: 2, days: 30, wantTitle: "(14 days)", wantNote: "Only the next 14 days"},
+    }
+
+    for _, tc := range testCases {
+        t.Run(tc.name, func(t *testing.T) {
+            st := store.NewMemoryStore()
+            start := time.Now().Add(24 * time.Hour)
+            st.UpsertEvents([]store.Event{{ID: "e1", Title: "Badminton Open Play", Start: start, End: start.Add(2 * time.Hour)}})
+            c := &Client{cfg: config.Config{FitnessWeeks: tc.weeks, EventsStaleAfter: time.Hour}, store: st}
+
+            embed := c.eventsReply(tc.days).Embed
+            if !strings.Contains(embed.Title, tc.wantTitle) {
+                t.Errorf("Expected title with %q, got %q", tc.wantTitle, embed.Title)
+            }
+            hasNote := strings.Contains(embed.Description, "Only the next")
+            if hasNote != (tc.wantNote != "") || !strings.Contains(embed.Description, tc.wantNote) {
+                t.Errorf("Expected note %q, got description %q", tc.wantNote, embed.Description)
+            }
+        })
+    }
+}
@@ -0,0 +1,106 @@
+package discord
+
+import (
+    "fmt"
+    "log/slog"
+    "time"
+
+    "github.com/bwmarrin/discordgo"
+
+    "github.com/sjsu-badminton/badminton-discord-bot/internal/sched"
+)
+
+// staleColor marks embeds showing data that may be out of date
+const staleColor = 0x95a5a6
+
+var sourceNames = map[string]string{
+    sched.SourceMacGym:  "Mac Gym occupancy",
+    sched.SourceFitness: "Fitness schedule",
+}
+
+func sourceName(source string) string {
+    if name, ok := sourceNames[source]; ok {
+        return name
+    }
+    return source
+}
+
+// SourceDown alerts the admin channel that a source has been failing. It
+// implements sched.Notifier.
+func (c *Client) SourceDown(s sched.SourceStatus) {
+    c.sendAdmin(sourceDownMessage(s))
+}
+
+// SourceRecovered tells the admin channel that a failing source works
+// again. It implements sched.Notifier.
+func (c *Client) SourceRecovered(s sched.SourceStatus) {
+    c.sendAdmin(sourceRecoveredMessage(s, time.Now()))
+}
+
+func (c *Client) sendAdmin(content string) {
+    if c.cfg.AdminChan == "" {
+        slog.Warn("No admin channel configured, skipping admin alert", "message", content)
+        return
+    }
+
+    err := c.outbox.enqueue(outboundMessage{
+        Kind:      kindAdmin,
+        ChannelID: c.cfg.AdminChan,
+        Msg: &discordgo.MessageSend{
+            Content:         content,
+            AllowedMentions: &discordgo.MessageAllowedMentions{},
+        },
+    })
+    if err != nil {
+        slog.Error("Failed to queue admin alert", "channel", c.cfg.AdminChan, "error", err)
+    }
+}
+
+func sourceDownMessage(s sched.SourceStatus) string {
+    lastSuccess := "never"
+    if !s.LastSuccess.IsZero() {
+        lastSuccess = fmt.Sprintf("<t:%d:R>", s.LastSuccess.Unix())
+    }
+
+    return fmt.Sprintf("🚨 **%s** updates have been failing since <t:%d:f> (%d attempts in a row, last success %s).\nLast error: `%s`",
+        sourceName(s.Source), s.FailingSince.Unix(), s.ConsecutiveFailures, lastSuccess, truncate(s.LastError, maxStatusError))
+}
+
+func sourceRecoveredMessage(s sched.SourceStatus, now time.Time) string {
+    return fmt.Sprintf("✅ **%s** updates have recovered after %s (%d failed attempts).",
+        sourceName(s.Source), now.Sub(s.FailingSince).Round(time.Minute), s.ConsecutiveFailures)
+}
+
+// sourceStatus returns the scrape health of source, which is unknown until
+// the scheduler has started
+func (c *Client) sourceStatus(source string) sched.SourceStatus {
+    if c.cron == nil {
+        return sched.SourceStatus{Source: source}
+    }
+    status, _ := c.cron.Health().Status(source)
+    return status
+}
+
+// staleWarning explains why data last updated at lastUpdated may be out of
+// date, or returns "" if it's fresh. A successful scrape that found nothing
+// changed confirms the data is still current.
+func staleWarning(lastUpdated time.Time, maxAge time.Duration, now time.Time, status sched.SourceStatus) string {
+    if status.LastSuccess.After(lastUpdated) {
+        lastUpdated = status.LastSuccess
+    }
+
+    var warning string
+    switch {
+    case lastUpdated.IsZero() && status.Failing():
+        warning = "No data has been retrieved yet."
+    case lastUpdated.IsZero(), now.Sub(lastUpdated) <= maxAge:
+        return ""
+    default:
+        warning = fmt.Sprintf("Last updated <t:%d:R>, so this may be out of date.", lastUpdated.Unix())
+    }
+
+    if status.Failing() {
+        warning += fmt.Sprintf(" Updates have failed %d times in a row.", status.ConsecutiveFailures)
+    }
+    return warning
+}
@@ -0,0 +1,81 @@
+package discord
+
+import (
+    "strings"
+    "testing"
+    "time"
+
+    "github.com/sjsu-badminton/badminton-discord-bot/internal/sched"
+)
+
+func TestStaleWarning(t *testing.T) {
+    now := time.Date(2024, 1, 15, 12, 0, 0, 0, time.UTC)
+    failing := sched.SourceStatus{Source: sched.SourceMacGym, ConsecutiveFailures: 3}
+
+    testCases := []struct {
+        name        string
+        lastUpdated time.Time
+        status      sched.SourceStatus
+        want        []string // substrings; none means no warning
+    }{
+        {name: "fresh", lastUpdated: now.Add(-5 * time.Minute)},
+        {name: "fresh while failing", lastUpdated: now.Add(-5 * time.Minute), status: failing},
+        {name: "no data yet", lastUpdated: time.Time{}},
+        {name: "no data and failing", status: failing, want: []string{"No data has been retrieved yet.", "failed 3 times"}},
+        {name: "old data", lastUpdated: now.Add(-time.Hour), want: []string{"<t:1705316400:R>"}},
+        {name: "old data confirmed unchanged", lastUpdated: now.Add(-time.Hour), status: sched.SourceStatus{LastSuccess: now.Add(-time.Minute)}},
+        {name: "old data and failing", lastUpdated: now.Add(-time.Hour), status: failing, want: []string{"<t:1705316400:R>", "failed 3 times"}},
+    }
+
+    for _, tc := range testCases {
+        t.Run(tc.name, func(t *testing.T) {
+            got := staleWarning(tc.lastUpdated, 10*time.Minute, now, tc.status)
+            if len(tc.want) == 0 {
+                if got != "" {
+                    t.Errorf("Expected no warning, got %q", got)
+                }
+                return
+            }
+            for _, want := range tc.want {
+                if !strings.Contains(got, want) {
+                    t.Errorf("Expected %q in warning %q", want, got)
+                }
+            }
+            if !strings.Contains(tc.name, "failing") && strings.Contains(got, "failed") {
+                t.Errorf("Expected no failure count in %q", got)
+            }
+        })
+    }
+}
+
+func TestSourceAdminMessages(t *testing.T) {
+    since := time.Date(2024, 1, 15, 12, 0, 0, 0, time.UTC)
+    s := sched.SourceStatus{
+        Source:              sched.SourceMacGym,
+        FailingSince:        since,
+        ConsecutiveFailures: 16,
+        LastError:           "fetching Mac Gym data: timeout",
+    }
+
+    down := sourceDownMessage(s)
+    for _, want := range []string{"**Mac Gym occupancy**", "<t:1705320000:f>", "16 attempts", "last success never", "`fetching Mac Gym data: timeout`"} {
+        if !strings.Contains(down, want) {
+            t.Errorf("Expected %q in %q", want, down)
+        }
+    }
+
+    s.LastSuccess = since.Add(-2 * time.Minute)
+    if down := sourceDownMessage(s); !strings.Contains(down, "last success <t:1705319880:R>") {
+        t.Errorf("Expected the last success time in %q", down)
+    }
+
+    s.LastError = strings.Repeat("x", maxStatusError+500)
+    if down := sourceDownMessage(s); !strings.Contains(down, "`"+strings.Repeat("x", maxStatusError)+"…`") {
+        t.Errorf("Expected the last error cut to %d bytes in %q", maxStatusError, down)
+    }
+
+    recovered := sourceRecoveredMessage(s, since.Add(32*time.Minute+20*time.Second))
+    if recovered != "✅ **Mac Gym occupancy** updates have recovered after 32m0s (16 failed attempts)." {
+        t.Errorf("Unexpected recovery message %q", recovered)
+    }
+}
@@ -0,0 +1,226 @@
+package discord
+
+import (
+    "fmt"
+    "strings"
+    "time"
+
+    "github.com/bwmarrin/discordgo"
+
+    "github.com/sjsu-badminton/badminton-discord-bot/internal/occupancy"
+    "github.com/sjsu-badminton/badminton-discord-bot/internal/util"
+)
+
+const (
+    defaultHistoryHours = 6
+    maxHistoryHours     = 7 * 24
+)
+
+func (c *Client) macGymHistoryReply(hours int) reply {
+    if hours <= 0 {
+        hours = defaultHistoryHours
+    }
+    if hours > maxHistoryHours {
+        hours = maxHistoryHours
+    }
+
+    now := time.Now()
+    sum := occupancy.Summarize(c.store.History(now.Add(-time.Duration(hours)*time.Hour), now))
+
+    embed := &discordgo.MessageEmbed{
+        Title:       "📈 Mac Gym — Occupancy History",
+        Description: fmt.Sprintf("Last %d hours", hours),
+        Color:       0x0099ff,
+        Footer: &discordgo.MessageEmbedFooter{
+            Text: "SJSU Badminton Bot",
+        },
+    }
+
+    if sum.Samples == 0 {
+        embed.Description = fmt.Sprintf("No occupancy readings recorded in the last %d hours yet.", hours)
+        return reply{Embed: embed}
+    }
+
+    loc := util.MustLocation(c.cfg.TZ)
+    embed.Description = fmt.Sprintf("Last %d hours — %d readings from %s to %s",
+        hours, sum.Samples,
+        sum.From.In(loc).Format("Mon 3:04 PM"),
+        sum.To.In(loc).Format("Mon 3:04 PM"))
+
+    embed.Fields = append(embed.Fields,
+        &discordgo.MessageEmbedField{
+            Name:   "Min",
+            Value:  fmt.Sprintf("%d / %d", sum.Min, sum.Capacity),
+            Inline: true,
+        },
+        &discordgo.MessageEmbedField{
+            Name:   "Average",
+            Value:  fmt.Sprintf("%.1f / %d", sum.Avg, sum.Capacity),
+            Inline: true,
+        },
+        &discordgo.MessageEmbedField{
+            Name:   "Max",
+            Value:  fmt.Sprintf("%d / %d", sum.Max, sum.Capacity),
+            Inline: true,
+        },
+        &discordgo.MessageEmbedField{
+            Name:   "Trend",
+            Value:  fmt.Sprintf("%s %s (%+.1f per hour)", trendEmoji(sum.Trend), sum.Trend, sum.Slope),
+            Inline: false,
+        },
+    )
+
+    return reply{Embed: embed}
+}
+
+func trendEmoji(t occupancy.Trend) string {
+    switch t {
+    case occupancy.TrendRising:
+        return "📈"
+    case occupancy.TrendFalling:
+        return "📉"
+    default:
+        return "➡️"
+    }
+}
+
+const (
+    bestTimeQuietest = 5
+    bestTimeBusiest  = 3
+)
+
+// bestTimeRange resolves the /besttime day argument to a time range starting
+// no earlier than now. An empty day means the next 24 hours.
+func bestTimeRange(day string, now time.Time) (from, until time.Time, label string, ok bool) {
+    day = strings.ToLower(strings.TrimSpace(day))
+    today := util.StartOfDay(now)
+
+    switch day {
+    case "":
+        return now, now.Add(24 * time.Hour), "the next 24 hours", true
+    case "today":
+        return now, today.AddDate(0, 0, 1), "today", true
+    case "tomorrow":
+        start := today.AddDate(0, 0, 1)
+        return start, start.AddDate(0, 0, 1), "tomorrow", true
+    }
+
+    wd, ok := util.ParseWeekday(day)
+    if !ok {
+        return time.Time{}, time.Time{}, "", false
+    }
+
+    offset := (int(wd) - int(now.Weekday()) + 7) % 7
+    start := today.AddDate(0, 0, offset)
+    if offset == 0 {
+        return now, start.AddDate(0, 0, 1), "today", true
+    }
+    return start, start.AddDate(0, 0, 1), wd.String(), true
+}
+
+func (c *Client) bestTimeReply(day string) reply {
+    loc := util.MustLocation(c.cfg.TZ)
+    now := time.Now().In(loc)
+
+    from, until, label, ok := bestTimeRange(day, now)
+    if !ok {
+        return reply{
+            Content:   fmt.Sprintf("❌ Unknown day %q. Use today, tomorrow or a weekday like `monday`.", day),
+            Ephemeral: true,
+        }
+    }
+
+    embed := &discordgo.MessageEmbed{
+        Title:       "🕒 Mac Gym — Best Times to Play",
+        Description: fmt.Sprintf("Based on recorded occupancy for %s.", label),
+        Color:       0x0099ff,
+        Footer: &discordgo.MessageEmbedFooter{
+            Text: "SJSU Badminton Bot • Historical averages, not live data",
+        },
+    }
+
+    profile := occupancy.BuildProfile(c.store.History(c.historySince(now), now), loc)
+    windows := profile.Windows(from, until)
+    if len(windows) == 0 {
+        embed.Description = fmt.Sprintf("Not enough occupancy history for %s yet. Check back after the bot has been running for a while.", label)
+        return reply{Embed: embed}
+    }
+
+    embed.Fields = append(embed.Fields,
+        &discordgo.MessageEmbedField{
+            Name:  "😌 Quietest",
+            Value: formatWindows(occupancy.Quietest(windows, bestTimeQuietest), profile.Capacity()),
+        },
+        &discordgo.MessageEmbedField{
+            Name:  "🔥 Busiest",
+            Value: formatWindows(occupancy.Busiest(windows, bestTimeBusiest), profile.Capacity()),
+        },
+    )
+
+    return reply{Embed: embed}
+}
+
+func formatWindows(windows []occupancy.Window, capacity int) string {
+    var b strings.Builder
+    for _, w := range windows {
+        fmt.Fprintf(&b, "**%s–%s** · avg %.1f / %d courts\n",
+            w.Start.Format("Mon 3 PM"),
+            w.Start.Add(time.Hour).Format("3 PM"),
+            w.Avg, capacity)
+    }
+    return b.String()
+}
+
+// historySince is the start of the occupancy history kept at now
+func (c *Client) historySince(now time.Time) time.Time {
+    return now.Add(-c.cfg.HistoryRetention)
+}
+
+// forecastHours are the horizons shown by /macgym forecast
+var forecastHours = []time.Duration{time.Hour, 2 * time.Hour, 3 * time.Hour, 4 * time.Hour}
+
+// predict forecasts occupancy at the given horizons from stored history
+func (c *Client) predict(now time.Time, horizons ...time.Duration) []occupancy.Prediction {
+    loc := util.MustLocation(c.cfg.TZ)
+    history := c.store.History(c.historySince(now), now.Add(time.Second))
+    return occupancy.Predict(occupancy.BuildProfile(history, loc), history, now, horizons...)
+}
+
+// expectedSummary renders predictions as "≈5 / 8 in 1h · ≈6 / 8 in 2h"
+func expectedSummary(preds []occupancy.Prediction, now time.Time) string {
+    parts := make([]string, 0, len(preds))
+    for _, p := range preds {
+        parts = append(parts, fmt.Sprintf("≈%.0f / %d in %.0fh", p.Expected, p.Capacity, p.At.Sub(now).Hours()))
+    }
+    return strings.Join(parts, " · ")
+}
+
+func (c *Client) macGymForecastReply() reply {
+    now := time.Now()
+    preds := c.predict(now, forecastHours...)
+
+    embed := &discordgo.MessageEmbed{
+        Title:       "🔮 Mac Gym — Occupancy Forecast",
+        Description: "Expected courts in use over the next few hours.",
+        Color:       0x0099ff,
+        Footer: &discordgo.MessageEmbedFooter{
+            Text: "SJSU Badminton Bot • Estimates from past occupancy and current readings",
+        },
+    }
+
+    if len(preds) == 0 {
+        embed.Description = "Not enough occupancy data to forecast yet. Check back after the bot has been running for a while."
+        return reply{Embed: embed}
+    }
+
+    loc := util.MustLocation(c.cfg.TZ)
+    for _, p := range preds {
+        embed.Fields = append(embed.Fields, &discordgo.MessageEmbedField{
+            Name:   fmt.Sprintf("In %.0fh (%s)", p.At.Sub(now).Hours(), p.At.In(loc).Format("3:04 PM")),
+            Value:  fmt.Sprintf("≈%.1f / %d courts\n_%s_", p.Expected, p.Capacity, p.Basis),
+            Inline: true,
+        })
+    }
+
+    return reply{Embed: embed}
+}
@@ -0,0 +1,38 @@
+package discord
+
+import (
+    "testing"
+    "time"
+)
+
+func TestBestTimeRange(t *testing.T) {
+    // Wednesday afternoon
+    now := time.Date(2024, 1, 17, 15, 30, 0, 0, time.UTC)
+
+    testCases := []struct {
+        day   string
+        from  time.Time
+        until time.Time
+        ok    bool
+    }{
+        {"", now, now.Add(24 * time.Hour), true},
+        {"today", now, time.Date(2024, 1, 18, 0, 0, 0, 0, time.UTC), true},
+        {"tomorrow", time.Date(2024, 1, 18, 0, 0, 0, 0, time.UTC), time.Date(2024, 1, 19, 0, 0, 0, 0, time.UTC), true},
+        {"wednesday", now, time.Date(2024, 1, 18, 0, 0, 0, 0, time.UTC), true},
+        {"Mon", time.Date(2024, 1, 22, 0, 0, 0, 0, time.UTC), time.Date(2024, 1, 23, 0, 0, 0, 0, time.UTC), true},
+        {"friday", time.Date(2024, 1, 19, 0, 0, 0, 0, time.UTC), time.Date(2024, 1, 20, 0, 0, 0, 0, time.UTC), true},
+        {"someday", time.Time{}, time.Time{}, false},
+    }
+
+    for _, tc := range testCases {
+        t.Run(tc.day, func(t *testing.T) {
+            from, until, _, ok := bestTimeRange(tc.day, now)
+            if ok != tc.ok {
+                t.Fatalf("Expected ok=%v, got %v", tc.ok, ok)
+            }
+            if !from.Equal(tc.from) || !until.Equal(tc.until) {
+                t.Errorf("Expected [%v, %v), got [%v, %v)", tc.from, tc.until, from, until)
+            }
+        })
+    }
+}
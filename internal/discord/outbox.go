@@ -0,0 +1,351 @@
+package discord
+
+import (
+    "context"
+    "errors"
+    "fmt"
+    "log/slog"
+    "net/http"
+    "sort"
+    "sync"
+    "time"
+
+    "github.com/bwmarrin/discordgo"
+)
+
+const (
+    // outboxBacklogWarn is the queue length past which a growing backlog is
+    // logged; the queue itself is unbounded so a burst is never dropped
+    outboxBacklogWarn = 256
+    outboxBaseBackoff = 2 * time.Second
+    outboxMaxBackoff  = time.Minute
+)
+
+// Outbound message kinds, used in logs and delivery stats
+const (
+    kindAlert        = "alert"
+    kindRoleAlert    = "role_alert"
+    kindReminder     = "reminder"
+    kindDigest       = "digest"
+    kindAnnouncement = "announcement"
+    kindAdmin        = "admin"
+)
+
+var errOutboxClosed = errors.New("outbox is closed")
+
+// messageSender is the part of the Discord session the outbox delivers through
+type messageSender interface {
+    ChannelMessageSendComplex(channelID string, data *discordgo.MessageSend, options ...discordgo.RequestOption) (*discordgo.Message, error)
+    UserChannelCreate(recipientID string, options ...discordgo.RequestOption) (*discordgo.Channel, error)
+}
+
+// outboundMessage is a message waiting to be posted in ChannelID or, when
+// ChannelID is empty, sent as a DM to UserID
+type outboundMessage struct {
+    Kind      string
+    ChannelID string
+    UserID    string
+    GuildID   string
+    Msg       *discordgo.MessageSend
+}
+
+// DeliveryStats counts the outcomes of one kind of outbound message
+type DeliveryStats struct {
+    Delivered int
+    Retried   int
+    Failed    int
+    Dropped   int
+}
+
+// outbox delivers messages from a queue with a pool of workers. The queue
+// grows as needed, so a fan-out to many subscribers waits its turn rather
+// than being dropped. Rate limits and server errors are retried with
+// backoff; errors that can't succeed on retry, like a user with DMs closed,
+// are handed to onPermanent.
+type outbox struct {
+    sender      messageSender
+    workers     int
+    maxAttempts int
+    backoff     time.Duration
+    onPermanent func(m outboundMessage, err error)
+
+    wg sync.WaitGroup
+
+    mu     sync.Mutex
+    ready  *sync.Cond // signalled when queue grows or the outbox closes
+    queue  []outboundMessage
+    closed bool
+    stats  map[string]*DeliveryStats
+}
+
+func newOutbox(sender messageSender, workers, maxAttempts int, onPermanent func(outboundMessage, error)) *outbox {
+    if workers < 1 {
+        workers = 1
+    }
+    if maxAttempts < 1 {
+        maxAttempts = 1
+    }
+    o := &outbox{
+        sender:      sender,
+        workers:     workers,
+        maxAttempts: maxAttempts,
+        backoff:     outboxBaseBackoff,
+        onPermanent: onPermanent,
+        stats:       make(map[string]*DeliveryStats),
+    }
+    o.ready = sync.NewCond(&o.mu)
+    return o
+}
+
+// start launches the workers. Retry waits end early once ctx is cancelled.
+func (o *outbox) start(ctx context.Context) {
+    for i := 0; i < o.workers; i++ {
+        o.wg.Add(1)
+        go func() {
+            defer o.wg.Done()
+            for {
+                m, ok := o.next()
+                if !ok {
+                    return
+                }
+                o.deliver(ctx, m)
+            }
+        }()
+    }
+}
+
+// stop stops accepting messages and waits for the queue to drain
+func (o *outbox) stop() {
+    o.mu.Lock()
+    if o.closed {
+        o.mu.Unlock()
+        return
+    }
+    o.closed = true
+    o.ready.Broadcast()
+    o.mu.Unlock()
+
+    o.wg.Wait()
+}
+
+// enqueue queues a message without blocking. Only a closed outbox drops
+// messages, so a burst of alerts can neither stall the caller nor be lost.
+func (o *outbox) enqueue(m outboundMessage) error {
+    o.mu.Lock()
+    defer o.mu.Unlock()
+
+    if o.closed {
+        o.statsFor(m.Kind).Dropped++
+        return errOutboxClosed
+    }
+
+    o.queue = append(o.queue, m)
+    if n := len(o.queue); n%outboxBacklogWarn == 0 {
+        slog.Warn("Outbound message backlog is growing", "queued", n, "kind", m.Kind)
+    }
+    o.ready.Signal()
+    return nil
+}
+
+// next waits for the oldest queued message. It reports false once the
+// outbox is closed and the queue has drained.
+func (o *outbox) next() (outboundMessage, bool) {
+    o.mu.Lock()
+    defer o.mu.Unlock()
+
+    for len(o.queue) == 0 && !o.closed {
+        o.ready.Wait()
+    }
+    if len(o.queue) == 0 {
+        return outboundMessage{}, false
+    }
+
+    m := o.queue[0]
+    o.queue[0] = outboundMessage{} // let the delivered message be collected
+    o.queue = o.queue[1:]
+    return m, true
+}
+
+// Stats returns a copy of the delivery counts by message kind
+func (o *outbox) Stats() map[string]DeliveryStats {
+    o.mu.Lock()
+    defer o.mu.Unlock()
+
+    stats := make(map[string]DeliveryStats, len(o.stats))
+    for kind, s := range o.stats {
+        stats[kind] = *s
+    }
+    return stats
+}
+
+// statsFor returns the counters for kind; o.mu must be held
+func (o *outbox) statsFor(kind string) *DeliveryStats {
+    s, ok := o.stats[kind]
+    if !ok {
+        s = &DeliveryStats{}
+        o.stats[kind] = s
+    }
+    return s
+}
+
+func (o *outbox) count(kind string, f func(s *DeliveryStats)) {
+    o.mu.Lock()
+    f(o.statsFor(kind))
+    o.mu.Unlock()
+}
+
+func (o *outbox) deliver(ctx context.Context, m outboundMessage) {
+    for attempt := 1; ; attempt++ {
+        err := o.send(m)
+        if err == nil {
+            o.count(m.Kind, func(s *DeliveryStats) { s.Delivered++ })
+            slog.Debug("Message delivered", "kind", m.Kind, "channel", m.ChannelID, "userID", m.UserID, "attempts", attempt)
+            return
+        }
+
+        wait, permanent := classifySendError(err)
+        if permanent || attempt >= o.maxAttempts {
+            o.count(m.Kind, func(s *DeliveryStats) { s.Failed++ })
+            slog.Error("Failed to deliver message",
+                "kind", m.Kind,
+                "channel", m.ChannelID,
+                "userID", m.UserID,
+                "attempts", attempt,
+                "permanent", permanent,
+                "error", err)
+            if permanent && o.onPermanent != nil {
+                o.onPermanent(m, err)
+            }
+            return
+        }
+
+        if wait == 0 {
+            wait = backoffDelay(o.backoff, attempt)
+        }
+        o.count(m.Kind, func(s *DeliveryStats) { s.Retried++ })
+        slog.Warn("Retrying message delivery",
+            "kind", m.Kind,
+            "attempt", attempt,
+            "wait", wait,
+            "error", err)
+
+        select {
+        case <-ctx.Done():
+            o.count(m.Kind, func(s *DeliveryStats) { s.Failed++ })
+            slog.Error("Gave up on message delivery while shutting down", "kind", m.Kind, "error", err)
+            return
+        case <-time.After(wait):
+        }
+    }
+}
+
+// send makes one delivery attempt, leaving rate limits and retries to deliver
+func (o *outbox) send(m outboundMessage) error {
+    opts := []discordgo.RequestOption{
+        discordgo.WithRetryOnRatelimit(false),
+        discordgo.WithRestRetries(0),
+    }
+
+    channelID := m.ChannelID
+    if channelID == "" {
+        channel, err := o.sender.UserChannelCreate(m.UserID, opts...)
+        if err != nil {
+            return fmt.Errorf("creating DM channel: %w", err)
+        }
+        channelID = channel.ID
+    }
+
+    _, err := o.sender.ChannelMessageSendComplex(channelID, m.Msg, opts...)
+    return err
+}
+
+// backoffDelay doubles base for every failed attempt, up to outboxMaxBackoff
+func backoffDelay(base time.Duration, attempt int) time.Duration {
+    d := base
+    for i := 1; i < attempt && d < outboxMaxBackoff; i++ {
+        d *= 2
+    }
+    if d > outboxMaxBackoff {
+        d = outboxMaxBackoff
+    }
+    return d
+}
+
+// classifySendError reports how long Discord asked us to wait before
+// retrying, and whether the error is permanent. Rate limits, server errors
+// and network errors are worth retrying; other client errors are not.
+func classifySendError(err error) (wait time.Duration, permanent bool) {
+    var rateLimit *discordgo.RateLimitError
+    if errors.As(err, &rateLimit) {
+        if rateLimit.TooManyRequests != nil {
+            return rateLimit.RetryAfter, false
+        }
+        return 0, false
+    }
+
+    var restErr *discordgo.RESTError
+    if errors.As(err, &restErr) && restErr.Response != nil {
+        code := restErr.Response.StatusCode
+        switch {
+        case code == http.StatusTooManyRequests, code == http.StatusRequestTimeout, code >= 500:
+            return 0, false
+        case code >= 400:
+            return 0, true
+        }
+    }
+
+    return 0, false
+}
+
+// discordErrorCode returns Discord's JSON error code for err, or 0
+func discordErrorCode(err error) int {
+    var restErr *discordgo.RESTError
+    if errors.As(err, &restErr) && restErr.Message != nil {
+        return restErr.Message.Code
+    }
+    return 0
+}
+
+// handleUndeliverable reacts to permanent delivery failures. Users who can't
+// be DMed have their DM subscriptions turned off, and a role alert channel
+// the bot can no longer post in is cleared so the default channel is used.
+func (c *Client) handleUndeliverable(m outboundMessage, err error) {
+    switch code := discordErrorCode(err); {
+    case m.ChannelID == "" && (code == discordgo.ErrCodeCannotSendMessagesToThisUser || code == discordgo.ErrCodeUnknownUser):
+        c.disableDMs(m.UserID)
+    case m.Kind == kindRoleAlert && m.ChannelID == c.store.AlertChannel(m.GuildID) &&
+        (code == discordgo.ErrCodeUnknownChannel || code == discordgo.ErrCodeMissingAccess || code == discordgo.ErrCodeMissingPermissions):
+        slog.Warn("Clearing unusable alert channel", "guildID", m.GuildID, "channel", m.ChannelID, "code", code)
+        c.store.SetAlertChannel(m.GuildID, "")
+    }
+}
+
+// disableDMs turns off everything delivered to a user by DM
+func (c *Client) disableDMs(userID string) {
+    slog.Warn("User can't be sent DMs, disabling their DM subscriptions", "userID", userID)
+
+    if c.cfg.AlertChan == "" {
+        c.store.Unsubscribe(userID)
+    }
+    c.store.ClearReminders(userID)
+    c.store.SetDigest(userID, "")
+}
+
+// logOutboxStats logs delivery counts by kind, in a stable order
+func logOutboxStats(stats map[string]DeliveryStats) {
+    kinds := make([]string, 0, len(stats))
+    for kind := range stats {
+        kinds = append(kinds, kind)
+    }
+    sort.Strings(kinds)
+
+    for _, kind := range kinds {
+        s := stats[kind]
+        slog.Info("Outbound message stats",
+            "kind", kind,
+            "delivered", s.Delivered,
+            "retried", s.Retried,
+            "failed", s.Failed,
+            "dropped", s.Dropped)
+    }
+}
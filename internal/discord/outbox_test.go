@@ -0,0 +1,212 @@
+package discord
+
+import (
+    "context"
+    "errors"
+    "net/http"
+    "sync"
+    "testing"
+    "time"
+
+    "github.com/bwmarrin/discordgo"
+)
+
+// fakeSender fails sends with the queued errors, then succeeds
+type fakeSender struct {
+    mu     sync.Mutex
+    errs   []error
+    sent   []string // channel IDs messages were delivered to
+    sends  int
+    dmUser []string
+}
+
+func (f *fakeSender) ChannelMessageSendComplex(channelID string, data *discordgo.MessageSend, options ...discordgo.RequestOption) (*discordgo.Message, error) {
+    f.mu.Lock()
+    defer f.mu.Unlock()
+
+    f.sends++
+    if len(f.errs) > 0 {
+        err := f.errs[0]
+        f.errs = f.errs[1:]
+        return nil, err
+    }
+    f.sent = append(f.sent, channelID)
+    return &discordgo.Message{ChannelID: channelID}, nil
+}
+
+func (f *fakeSender) UserChannelCreate(recipientID string, options ...discordgo.RequestOption) (*discordgo.Channel, error) {
+    f.mu.Lock()
+    defer f.mu.Unlock()
+
+    f.dmUser = append(f.dmUser, recipientID)
+    return &discordgo.Channel{ID: "dm-" + recipientID}, nil
+}
+
+func restError(status, code int) error {
+    return &discordgo.RESTError{
+        Response: &http.Response{StatusCode: status, Status: http.StatusText(status)},
+        Message:  &discordgo.APIErrorMessage{Code: code},
+    }
+}
+
+func rateLimitError(wait time.Duration) error {
+    return &discordgo.RateLimitError{RateLimit: &discordgo.RateLimit{
+        TooManyRequests: &discordgo.TooManyRequests{RetryAfter: wait},
+    }}
+}
+
+func TestClassifySendError(t *testing.T) {
+    testCases := []struct {
+        name          string
+        err           error
+        wantWait      time.Duration
+        wantPermanent bool
+    }{
+        {name: "rate limited", err: rateLimitError(3 * time.Second), wantWait: 3 * time.Second},
+        {name: "server error", err: restError(http.StatusInternalServerError, 0)},
+        {name: "bad gateway", err: restError(http.StatusBadGateway, 0)},
+        {name: "DMs closed", err: restError(http.StatusForbidden, discordgo.ErrCodeCannotSendMessagesToThisUser), wantPermanent: true},
+        {name: "unknown channel", err: restError(http.StatusNotFound, discordgo.ErrCodeUnknownChannel), wantPermanent: true},
+        {name: "wrapped client error", err: errors.Join(errors.New("creating DM channel"), restError(http.StatusBadRequest, 0)), wantPermanent: true},
+        {name: "network error", err: errors.New("connection reset by peer")},
+    }
+
+    for _, tc := range testCases {
+        t.Run(tc.name, func(t *testing.T) {
+            wait, permanent := classifySendError(tc.err)
+            if wait != tc.wantWait || permanent != tc.wantPermanent {
+                t.Errorf("classifySendError() = %v, %v; want %v, %v", wait, permanent, tc.wantWait, tc.wantPermanent)
+            }
+        })
+    }
+}
+
+func TestBackoffDelay(t *testing.T) {
+    testCases := []struct {
+        attempt int
+        want    time.Duration
+    }{
+        {1, 2 * time.Second},
+        {2, 4 * time.Second},
+        {4, 16 * time.Second},
+        {10, outboxMaxBackoff},
+    }
+
+    for _, tc := range testCases {
+        if got := backoffDelay(2*time.Second, tc.attempt); got != tc.want {
+            t.Errorf("backoffDelay(attempt %d) = %v, want %v", tc.attempt, got, tc.want)
+        }
+    }
+}
+
+func TestOutboxRetriesTransientErrors(t *testing.T) {
+    sender := &fakeSender{errs: []error{
+        rateLimitError(time.Millisecond),
+        restError(http.StatusServiceUnavailable, 0),
+    }}
+    o := newOutbox(sender, 1, 5, nil)
+    o.backoff = time.Millisecond
+    o.start(context.Background())
+
+    if err := o.enqueue(outboundMessage{Kind: kindAnnouncement, ChannelID: "c1", Msg: &discordgo.MessageSend{}}); err != nil {
+        t.Fatalf("enqueue: %v", err)
+    }
+    o.stop()
+
+    if len(sender.sent) != 1 || sender.sent[0] != "c1" {
+        t.Fatalf("Expected one delivery to c1, got %v", sender.sent)
+    }
+    stats := o.Stats()[kindAnnouncement]
+    if stats.Delivered != 1 || stats.Retried != 2 || stats.Failed != 0 {
+        t.Errorf("Unexpected stats: %+v", stats)
+    }
+}
+
+func TestOutboxGivesUpAfterMaxAttempts(t *testing.T) {
+    sender := &fakeSender{errs: []error{
+        restError(http.StatusInternalServerError, 0),
+        restError(http.StatusInternalServerError, 0),
+        restError(http.StatusInternalServerError, 0),
+    }}
+    permanent := 0
+    o := newOutbox(sender, 1, 2, func(outboundMessage, error) { permanent++ })
+    o.backoff = time.Millisecond
+    o.start(context.Background())
+
+    o.enqueue(outboundMessage{Kind: kindAlert, ChannelID: "c1", Msg: &discordgo.MessageSend{}})
+    o.stop()
+
+    if sender.sends != 2 {
+        t.Errorf("Expected 2 attempts, got %d", sender.sends)
+    }
+    if permanent != 0 {
+        t.Error("Expected exhausted retries not to count as a permanent failure")
+    }
+    if stats := o.Stats()[kindAlert]; stats.Failed != 1 || stats.Delivered != 0 {
+        t.Errorf("Unexpected stats: %+v", stats)
+    }
+}
+
+func TestOutboxPermanentFailure(t *testing.T) {
+    sender := &fakeSender{errs: []error{
+        restError(http.StatusForbidden, discordgo.ErrCodeCannotSendMessagesToThisUser),
+    }}
+    var failed []outboundMessage
+    o := newOutbox(sender, 1, 5, func(m outboundMessage, err error) {
+        if discordErrorCode(err) != discordgo.ErrCodeCannotSendMessagesToThisUser {
+            t.Errorf("Unexpected error code in %v", err)
+        }
+        failed = append(failed, m)
+    })
+    o.start(context.Background())
+
+    o.enqueue(outboundMessage{Kind: kindReminder, UserID: "u1", Msg: &discordgo.MessageSend{}})
+    o.stop()
+
+    if sender.sends != 1 {
+        t.Errorf("Expected a permanent failure not to be retried, got %d attempts", sender.sends)
+    }
+    if len(sender.dmUser) != 1 || sender.dmUser[0] != "u1" {
+        t.Errorf("Expected a DM channel for u1, got %v", sender.dmUser)
+    }
+    if len(failed) != 1 || failed[0].UserID != "u1" {
+        t.Errorf("Expected the permanent failure handler to get u1's message, got %+v", failed)
+    }
+}
+
+func TestOutboxDeliversBurstBeyondBacklogWarning(t *testing.T) {
+    sender := &fakeSender{}
+    o := newOutbox(sender, 4, 1, nil)
+
+    // Queued before the workers start, like a digest fan-out to more
+    // subscribers than the workers can keep up with
+    total := 3*outboxBacklogWarn + 7
+    for i := 0; i < total; i++ {
+        if err := o.enqueue(outboundMessage{Kind: kindDigest, UserID: "u1", Msg: &discordgo.MessageSend{}}); err != nil {
+            t.Fatalf("enqueue %d: %v", i, err)
+        }
+    }
+
+    o.start(context.Background())
+    o.stop()
+
+    if len(sender.sent) != total {
+        t.Errorf("Expected all %d messages delivered, got %d", total, len(sender.sent))
+    }
+    if stats := o.Stats()[kindDigest]; stats.Delivered != total || stats.Dropped != 0 {
+        t.Errorf("Unexpected stats: %+v", stats)
+    }
+}
+
+func TestOutboxDropsWhenClosed(t *testing.T) {
+    o := newOutbox(&fakeSender{}, 1, 1, nil)
+    o.start(context.Background())
+    o.stop()
+
+    if err := o.enqueue(outboundMessage{Kind: kindDigest}); !errors.Is(err, errOutboxClosed) {
+        t.Errorf("Expected errOutboxClosed, got %v", err)
+    }
+    if stats := o.Stats()[kindDigest]; stats.Dropped != 1 {
+        t.Errorf("Unexpected stats: %+v", stats)
+    }
+}
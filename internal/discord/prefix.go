@@ -0,0 +1,203 @@
+package discord
+
+import (
+    "fmt"
+    "log/slog"
+    "strconv"
+    "strings"
+
+    "github.com/bwmarrin/discordgo"
+
+    "github.com/sjsu-badminton/badminton-discord-bot/internal/store"
+)
+
+const commandPrefix = "!"
+
+// prefixCommand handles a "!" command and returns the reply to post in the channel
+type prefixCommand func(c *Client, m *discordgo.MessageCreate, args []string) reply
+
+var prefixCommands = map[string]prefixCommand{
+    "macgym": func(c *Client, m *discordgo.MessageCreate, args []string) reply {
+        if len(args) > 0 {
+            switch strings.ToLower(args[0]) {
+            case "history":
+                return c.macGymHistoryReply(parseIntArg(args, 1, defaultHistoryHours))
+            case "forecast":
+                return c.macGymForecastReply()
+            }
+        }
+        return c.macGymReply()
+    },
+    "badminton": func(c *Client, m *discordgo.MessageCreate, args []string) reply {
+        if len(args) == 0 || strings.ToLower(args[0]) != "events" {
+            return c.badmintonInfoReply()
+        }
+        days := parseIntArg(args, 1, defaultEventDays)
+        if days == 0 {
+            days = defaultEventDays
+        }
+        return c.eventsReply(days)
+    },
+    "besttime": func(c *Client, m *discordgo.MessageCreate, args []string) reply {
+        return c.bestTimeReply(strings.Join(args, " "))
+    },
+    "facility": func(c *Client, m *discordgo.MessageCreate, args []string) reply {
+        return c.facilityReply(strings.Join(args, " "))
+    },
+    "subscribe": func(c *Client, m *discordgo.MessageCreate, args []string) reply {
+        if len(args) > 0 && strings.ToLower(args[0]) == "digest" {
+            frequency := ""
+            if len(args) > 1 {
+                frequency = args[1]
+            }
+            return c.digestReply(m.Author.ID, frequency)
+        }
+        dir, threshold, active, err := parseSubscribeArgs(args)
+        if err != nil {
+            return reply{Content: fmt.Sprintf("❌ %v. Usage: `!subscribe [above|below] [threshold] [days] [hours]`, e.g. `!subscribe below 3 weekdays 17:00-22:00`.", err)}
+        }
+        return c.subscribeReply(m.Author.ID, threshold, dir, active)
+    },
+    "subscriptions": func(c *Client, m *discordgo.MessageCreate, args []string) reply {
+        return c.subscriptionsReply(m.Author.ID)
+    },
+    "quiethours": func(c *Client, m *discordgo.MessageCreate, args []string) reply {
+        return c.quietHoursReply(m.Author.ID, strings.Join(args, " "))
+    },
+    "reminders": func(c *Client, m *discordgo.MessageCreate, args []string) reply {
+        if len(args) > 0 {
+            switch strings.ToLower(args[0]) {
+            case "on":
+                return c.remindersOnReply(m.Author.ID, parseIntArg(args, 1, defaultReminderMinutes))
+            case "off":
+                return c.remindersOffReply(m.Author.ID)
+            }
+        }
+        return c.remindersStatusReply(m.Author.ID)
+    },
+    "unsubscribe": func(c *Client, m *discordgo.MessageCreate, args []string) reply {
+        return c.unsubscribeReply(m.Author.ID)
+    },
+    "rolealert":    rolealertPrefix,
+    "alertchannel": alertchannelPrefix,
+    "status":       statusPrefix,
+    "help": func(c *Client, m *discordgo.MessageCreate, args []string) reply {
+        return c.helpReply()
+    },
+}
+
+// parsePrefixCommand splits a message like "!badminton events 14" into a
+// lowercase command name and its arguments. ok is false for non-commands.
+func parsePrefixCommand(content string) (name string, args []string, ok bool) {
+    if !strings.HasPrefix(content, commandPrefix) {
+        return "", nil, false
+    }
+
+    parts := strings.Fields(strings.TrimPrefix(content, commandPrefix))
+    if len(parts) == 0 {
+        return "", nil, false
+    }
+
+    return strings.ToLower(parts[0]), parts[1:], true
+}
+
+// parseIntArg returns args[idx] as a non-negative integer, or def when the
+// argument is missing or invalid
+func parseIntArg(args []string, idx int, def int) int {
+    if idx >= len(args) {
+        return def
+    }
+    v, err := strconv.Atoi(args[idx])
+    if err != nil || v < 0 {
+        return def
+    }
+    return v
+}
+
+// parseSubscribeArgs parses "[above|below] [threshold] [days] [hours]". A
+// bare threshold alerts above it, as it always has. Days may be given as
+// several words and in either order with the hours; anything that is neither
+// is an error rather than being dropped.
+func parseSubscribeArgs(args []string) (store.Direction, int, store.Window, error) {
+    dir := store.Above
+    if len(args) > 0 {
+        switch d := store.Direction(strings.ToLower(args[0])); d {
+        case store.Above, store.Below:
+            dir = d
+            args = args[1:]
+        }
+    }
+
+    threshold := 0
+    if len(args) > 0 {
+        if v, err := strconv.Atoi(args[0]); err == nil {
+            if v < 0 {
+                return "", 0, store.Window{}, fmt.Errorf("invalid threshold %q", args[0])
+            }
+            threshold = v
+            args = args[1:]
+        }
+    }
+
+    var days []string
+    var hours string
+    for _, arg := range args {
+        if _, err := store.ParseClockRange(arg); err == nil {
+            if hours != "" {
+                return "", 0, store.Window{}, fmt.Errorf("more than one time range: %q and %q", hours, arg)
+            }
+            hours = arg
+        } else {
+            days = append(days, arg)
+        }
+    }
+
+    // Joined with spaces so ParseDays sees "mon wed" or "every day" the way
+    // the slash command's days option would
+    active, err := parseWindow(strings.Join(days, " "), hours)
+    return dir, threshold, active, err
+}
+
+// handleMessage dispatches prefix commands from guild and DM messages
+func (c *Client) handleMessage(s *discordgo.Session, m *discordgo.MessageCreate) {
+    if m.Author == nil || m.Author.Bot {
+        return
+    }
+
+    name, args, ok := parsePrefixCommand(m.Content)
+    if !ok {
+        return
+    }
+
+    slog.Info("Prefix command received",
+        "command", name,
+        "args", args,
+        "user", m.Author.Username,
+        "guild", m.GuildID)
+
+    var r reply
+    if cmd, found := prefixCommands[name]; found {
+        r = cmd(c, m, args)
+    } else {
+        r = reply{Content: fmt.Sprintf("Unknown command: `%s%s`. Use `!help` to see available commands.", commandPrefix, name)}
+    }
+
+    c.sendReply(s, m.ChannelID, m.Reference(), r)
+}
+
+// sendReply renders a command reply as a channel message
+func (c *Client) sendReply(s *discordgo.Session, channelID string, ref *discordgo.MessageReference, r reply) {
+    msg := &discordgo.MessageSend{
+        Content:   r.Content,
+        Reference: ref,
+        // Replies should never ping anyone, including the author
+        AllowedMentions: &discordgo.MessageAllowedMentions{},
+    }
+    if r.Embed != nil {
+        msg.Embeds = []*discordgo.MessageEmbed{r.Embed}
+    }
+
+    if _, err := s.ChannelMessageSendComplex(channelID, msg); err != nil {
+        slog.Error("Failed to send prefix command reply", "channel", channelID, "error", err)
+    }
+}
@@ -0,0 +1,79 @@
+package discord
+
+import (
+    "fmt"
+    "log/slog"
+    "time"
+
+    "github.com/bwmarrin/discordgo"
+
+    "github.com/sjsu-badminton/badminton-discord-bot/internal/store"
+    "github.com/sjsu-badminton/badminton-discord-bot/internal/util"
+)
+
+const (
+    defaultReminderMinutes = 30
+    maxReminderMinutes     = 24 * 60
+)
+
+// SendReminder DMs a user that an event is about to start. It implements
+// sched.Notifier.
+func (c *Client) SendReminder(r store.Reminder) {
+    message := reminderMessage(r, time.Now(), util.MustLocation(c.cfg.TZ))
+
+    if err := c.sendDM(kindReminder, r.UserID, &discordgo.MessageSend{Content: message}); err != nil {
+        slog.Error("Failed to queue event reminder",
+            "userID", r.UserID,
+            "eventID", r.Event.ID,
+            "error", err)
+    }
+}
+
+// reminderMessage describes how soon the event starts. The actual time left
+// is used rather than the lead, since reminders can fire late after downtime
+// or for events posted at short notice.
+func reminderMessage(r store.Reminder, now time.Time, loc *time.Location) string {
+    minutes := int(r.Event.Start.Sub(now).Round(time.Minute) / time.Minute)
+
+    when := "now"
+    switch {
+    case minutes == 1:
+        when = "in 1 minute"
+    case minutes > 1:
+        when = fmt.Sprintf("in %d minutes", minutes)
+    }
+
+    return fmt.Sprintf("⏰ Reminder: **%s** starts %s (%s at %s).",
+        r.Event.Title, when, r.Event.Start.In(loc).Format("3:04 PM"), r.Event.Location)
+}
+
+func (c *Client) remindersOnReply(userID string, minutes int) reply {
+    if minutes < 1 || minutes > maxReminderMinutes {
+        return reply{
+            Content:   fmt.Sprintf("❌ Reminder time must be between 1 and %d minutes.", maxReminderMinutes),
+            Ephemeral: true,
+        }
+    }
+
+    c.store.SetReminderLead(userID, time.Duration(minutes)*time.Minute)
+    return reply{
+        Content:   fmt.Sprintf("✅ You'll get a DM %d minutes before each upcoming badminton event.", minutes),
+        Ephemeral: true,
+    }
+}
+
+func (c *Client) remindersOffReply(userID string) reply {
+    c.store.ClearReminders(userID)
+    return reply{Content: "✅ Event reminders turned off.", Ephemeral: true}
+}
+
+func (c *Client) remindersStatusReply(userID string) reply {
+    lead, ok := c.store.ReminderLead(userID)
+    if !ok {
+        return reply{Content: "You don't have event reminders on. Use `/reminders on` to get a DM before events start.", Ephemeral: true}
+    }
+    return reply{
+        Content:   fmt.Sprintf("You'll get a DM %d minutes before each upcoming badminton event.", int(lead/time.Minute)),
+        Ephemeral: true,
+    }
+}
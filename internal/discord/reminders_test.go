@@ -0,0 +1,32 @@
+package discord
+
+import (
+    "testing"
+    "time"
+
+    "github.com/sjsu-badminton/badminton-discord-bot/internal/store"
+)
+
+func TestReminderMessage(t *testing.T) {
+    start := time.Date(2024, 1, 15, 18, 0, 0, 0, time.UTC)
+    r := store.Reminder{
+        UserID: "user1",
+        Event:  store.Event{Title: "Open Play", Start: start, Location: "Mac Gym"},
+        Lead:   30 * time.Minute,
+    }
+
+    testCases := []struct {
+        now      time.Time
+        expected string
+    }{
+        {start.Add(-30 * time.Minute), "⏰ Reminder: **Open Play** starts in 30 minutes (6:00 PM at Mac Gym)."},
+        {start.Add(-70 * time.Second), "⏰ Reminder: **Open Play** starts in 1 minute (6:00 PM at Mac Gym)."},
+        {start.Add(-10 * time.Second), "⏰ Reminder: **Open Play** starts now (6:00 PM at Mac Gym)."},
+    }
+
+    for _, tc := range testCases {
+        if got := reminderMessage(r, tc.now, time.UTC); got != tc.expected {
+            t.Errorf("reminderMessage at %v = %q, want %q", tc.now, got, tc.expected)
+        }
+    }
+}
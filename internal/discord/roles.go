@@ -0,0 +1,287 @@
+package discord
+
+import (
+    "fmt"
+    "log/slog"
+    "regexp"
+    "sort"
+    "strings"
+
+    "github.com/bwmarrin/discordgo"
+
+    "github.com/sjsu-badminton/badminton-discord-bot/internal/store"
+)
+
+var (
+    // guildAdminPermissions limits role alert and channel configuration to
+    // members who can manage the server
+    guildAdminPermissions int64 = discordgo.PermissionManageServer
+    dmDisabled                  = false
+    minRoleThreshold            = 1.0
+
+    roleMentionRe    = regexp.MustCompile(`^(?:<@&(\d+)>|(\d+))$`)
+    channelMentionRe = regexp.MustCompile(`^(?:<#(\d+)>|(\d+))$`)
+)
+
+// parseMentionID extracts the ID from a "<@&id>"/"<#id>" mention or a bare ID
+func parseMentionID(re *regexp.Regexp, s string) (string, bool) {
+    m := re.FindStringSubmatch(strings.TrimSpace(s))
+    if m == nil {
+        return "", false
+    }
+    if m[1] != "" {
+        return m[1], true
+    }
+    return m[2], true
+}
+
+// roleAlertMessage builds a role alert that can only ping that role
+func roleAlertMessage(a store.ThresholdAlert) *discordgo.MessageSend {
+    return &discordgo.MessageSend{
+        Content: fmt.Sprintf("<@&%s> %s", a.RoleID, thresholdMessage(a)),
+        AllowedMentions: &discordgo.MessageAllowedMentions{
+            Roles: []string{a.RoleID},
+        },
+    }
+}
+
+// sendRoleAlert queues a role alert for the guild's alert channel, falling
+// back to ALERT_CHANNEL_ID
+func (c *Client) sendRoleAlert(a store.ThresholdAlert) {
+    channelID := c.store.AlertChannel(a.GuildID)
+    if channelID == "" {
+        channelID = c.cfg.AlertChan
+    }
+    if channelID == "" {
+        slog.Warn("No alert channel for role alert; use /alertchannel to set one",
+            "guildID", a.GuildID,
+            "roleID", a.RoleID)
+        return
+    }
+
+    err := c.outbox.enqueue(outboundMessage{
+        Kind:      kindRoleAlert,
+        ChannelID: channelID,
+        GuildID:   a.GuildID,
+        Msg:       roleAlertMessage(a),
+    })
+    if err != nil {
+        slog.Error("Failed to queue role alert",
+            "guildID", a.GuildID,
+            "roleID", a.RoleID,
+            "channel", channelID,
+            "error", err)
+    }
+}
+
+// canManageGuild reports whether a prefix command author may configure role
+// alerts, mirroring the slash commands' default member permissions
+func (c *Client) canManageGuild(m *discordgo.MessageCreate) bool {
+    if m.GuildID == "" {
+        return false
+    }
+    perms, err := c.sess.UserChannelPermissions(m.Author.ID, m.ChannelID)
+    if err != nil {
+        slog.Warn("Failed to check member permissions", "user", m.Author.ID, "guild", m.GuildID, "error", err)
+        return false
+    }
+    return perms&(discordgo.PermissionManageServer|discordgo.PermissionAdministrator) != 0
+}
+
+func (c *Client) handleRoleAlert(s *discordgo.Session, i *discordgo.InteractionCreate) {
+    opts := i.ApplicationCommandData().Options
+    if len(opts) == 0 {
+        c.respondReply(s, i, c.roleAlertListReply(i.GuildID))
+        return
+    }
+
+    switch opts[0].Name {
+    case "set":
+        var roleID, days, hours string
+        threshold := 0
+        dir := store.Above
+        for _, opt := range opts[0].Options {
+            switch opt.Name {
+            case "role":
+                roleID = opt.RoleValue(nil, "").ID
+            case "threshold":
+                threshold = int(opt.IntValue())
+            case "direction":
+                dir = store.Direction(opt.StringValue())
+            case "days":
+                days = opt.StringValue()
+            case "hours":
+                hours = opt.StringValue()
+            }
+        }
+
+        active, err := parseWindow(days, hours)
+        if err != nil {
+            c.ephemeral(s, i, fmt.Sprintf("❌ %v. Use days like `weekdays` or `mon,wed,fri` and hours like `17:00-22:00`.", err))
+            return
+        }
+        c.respondReply(s, i, c.roleAlertSetReply(i.GuildID, roleID, threshold, dir, active))
+    case "remove":
+        roleID := ""
+        if len(opts[0].Options) > 0 {
+            roleID = opts[0].Options[0].RoleValue(nil, "").ID
+        }
+        c.respondReply(s, i, c.roleAlertRemoveReply(i.GuildID, roleID))
+    default:
+        c.respondReply(s, i, c.roleAlertListReply(i.GuildID))
+    }
+}
+
+func (c *Client) handleAlertChannel(s *discordgo.Session, i *discordgo.InteractionCreate) {
+    channelID := ""
+    if opts := i.ApplicationCommandData().Options; len(opts) > 0 {
+        channelID = opts[0].ChannelValue(nil).ID
+    }
+    c.respondReply(s, i, c.alertChannelReply(i.GuildID, channelID))
+}
+
+func (c *Client) roleAlertSetReply(guildID, roleID string, threshold int, dir store.Direction, active store.Window) reply {
+    if guildID == "" {
+        return reply{Content: "❌ Role alerts can only be set up in a server.", Ephemeral: true}
+    }
+    if threshold <= 0 {
+        return reply{Content: "❌ Role alerts need a threshold of at least 1 court.", Ephemeral: true}
+    }
+
+    sub := store.NewRoleSubscription(guildID, roleID, threshold, dir)
+    sub.Active = active
+    c.store.Subscribe(sub)
+
+    var message string
+    if sub.Direction == store.Below {
+        message = fmt.Sprintf("✅ <@&%s> will be pinged when fewer than %d courts are in use at Mac Gym.", roleID, threshold)
+    } else {
+        message = fmt.Sprintf("✅ <@&%s> will be pinged when Mac Gym occupancy reaches %d or higher.", roleID, threshold)
+    }
+    if !active.IsZero() {
+        message += fmt.Sprintf(" Alerts are only sent %s (%s).", active, c.cfg.TZ)
+    }
+    if c.store.AlertChannel(guildID) == "" && c.cfg.AlertChan == "" {
+        message += "\n⚠️ No alert channel is set for this server yet. Use `/alertchannel` to choose one."
+    }
+    return reply{Content: message, Ephemeral: true}
+}
+
+func (c *Client) roleAlertRemoveReply(guildID, roleID string) reply {
+    sub, ok := c.store.Subscribers()[store.RoleKey(roleID)]
+    if !ok || sub.GuildID != guildID {
+        return reply{Content: fmt.Sprintf("<@&%s> doesn't have an occupancy alert.", roleID), Ephemeral: true}
+    }
+
+    c.store.Unsubscribe(store.RoleKey(roleID))
+    return reply{Content: fmt.Sprintf("✅ Removed the occupancy alert for <@&%s>.", roleID), Ephemeral: true}
+}
+
+func (c *Client) roleAlertListReply(guildID string) reply {
+    var subs []store.Subscription
+    for _, sub := range c.store.Subscribers() {
+        if sub.RoleID != "" && sub.GuildID == guildID {
+            subs = append(subs, sub)
+        }
+    }
+    sort.Slice(subs, func(i, j int) bool { return subs[i].RoleID < subs[j].RoleID })
+
+    channel := "Not set"
+    if id := c.store.AlertChannel(guildID); id != "" {
+        channel = fmt.Sprintf("<#%s>", id)
+    } else if c.cfg.AlertChan != "" {
+        channel = fmt.Sprintf("<#%s> (default)", c.cfg.AlertChan)
+    }
+
+    embed := &discordgo.MessageEmbed{
+        Title:       "🔔 Role Alerts",
+        Description: fmt.Sprintf("**Alert channel:** %s", channel),
+        Color:       0x0099ff,
+        Footer: &discordgo.MessageEmbedFooter{
+            Text: "SJSU Badminton Bot",
+        },
+    }
+
+    if len(subs) == 0 {
+        embed.Description += "\n\nNo role alerts set up. Use `/rolealert set` to add one."
+    }
+    for _, sub := range subs {
+        embed.Fields = append(embed.Fields, &discordgo.MessageEmbedField{
+            Name:  "Role",
+            Value: fmt.Sprintf("<@&%s>\n%s", sub.RoleID, describeSubscription(sub)),
+        })
+    }
+
+    return reply{Embed: embed, Ephemeral: true}
+}
+
+func (c *Client) alertChannelReply(guildID, channelID string) reply {
+    if guildID == "" {
+        return reply{Content: "❌ The alert channel can only be set in a server.", Ephemeral: true}
+    }
+
+    c.store.SetAlertChannel(guildID, channelID)
+    if channelID == "" {
+        return reply{Content: "✅ Alert channel cleared.", Ephemeral: true}
+    }
+    return reply{Content: fmt.Sprintf("✅ Role alerts for this server will be posted in <#%s>.", channelID), Ephemeral: true}
+}
+
+// rolealertPrefix handles "!rolealert set @role [above|below] <threshold> [days] [hours]",
+// "!rolealert remove @role" and "!rolealert list"
+func rolealertPrefix(c *Client, m *discordgo.MessageCreate, args []string) reply {
+    if !c.canManageGuild(m) {
+        return reply{Content: "❌ You need the Manage Server permission to configure role alerts."}
+    }
+    if len(args) == 0 {
+        return c.roleAlertListReply(m.GuildID)
+    }
+
+    usage := reply{Content: "Usage: `!rolealert set @role [above|below] <threshold> [days] [hours]`, `!rolealert remove @role` or `!rolealert list`."}
+    switch strings.ToLower(args[0]) {
+    case "set":
+        if len(args) < 3 {
+            return usage
+        }
+        roleID, ok := parseMentionID(roleMentionRe, args[1])
+        if !ok {
+            return usage
+        }
+        dir, threshold, active, err := parseSubscribeArgs(args[2:])
+        if err != nil {
+            return reply{Content: fmt.Sprintf("❌ %v.", err)}
+        }
+        return c.roleAlertSetReply(m.GuildID, roleID, threshold, dir, active)
+    case "remove":
+        if len(args) < 2 {
+            return usage
+        }
+        roleID, ok := parseMentionID(roleMentionRe, args[1])
+        if !ok {
+            return usage
+        }
+        return c.roleAlertRemoveReply(m.GuildID, roleID)
+    case "list":
+        return c.roleAlertListReply(m.GuildID)
+    }
+    return usage
+}
+
+// alertchannelPrefix handles "!alertchannel <#channel|off>"
+func alertchannelPrefix(c *Client, m *discordgo.MessageCreate, args []string) reply {
+    if !c.canManageGuild(m) {
+        return reply{Content: "❌ You need the Manage Server permission to set the alert channel."}
+    }
+    if len(args) == 0 {
+        return reply{Content: "Usage: `!alertchannel #channel` or `!alertchannel off`."}
+    }
+    if strings.ToLower(args[0]) == "off" {
+        return c.alertChannelReply(m.GuildID, "")
+    }
+
+    channelID, ok := parseMentionID(channelMentionRe, args[0])
+    if !ok {
+        return reply{Content: "Usage: `!alertchannel #channel` or `!alertchannel off`."}
+    }
+    return c.alertChannelReply(m.GuildID, channelID)
+}
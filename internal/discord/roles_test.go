@@ -0,0 +1,64 @@
+package discord
+
+import (
+    "strings"
+    "testing"
+
+    "github.com/sjsu-badminton/badminton-discord-bot/internal/store"
+)
+
+func TestParseMentionID(t *testing.T) {
+    testCases := []struct {
+        name   string
+        re     string
+        input  string
+        wantID string
+        wantOK bool
+    }{
+        {name: "role mention", re: "role", input: "<@&123>", wantID: "123", wantOK: true},
+        {name: "bare role ID", re: "role", input: "123", wantID: "123", wantOK: true},
+        {name: "user mention is not a role", re: "role", input: "<@123>", wantOK: false},
+        {name: "role name", re: "role", input: "@players", wantOK: false},
+        {name: "channel mention", re: "channel", input: "<#456>", wantID: "456", wantOK: true},
+        {name: "role mention is not a channel", re: "channel", input: "<@&456>", wantOK: false},
+        {name: "channel name", re: "channel", input: "#alerts", wantOK: false},
+    }
+
+    for _, tc := range testCases {
+        t.Run(tc.name, func(t *testing.T) {
+            re := roleMentionRe
+            if tc.re == "channel" {
+                re = channelMentionRe
+            }
+
+            id, ok := parseMentionID(re, tc.input)
+            if ok != tc.wantOK || id != tc.wantID {
+                t.Errorf("parseMentionID(%q) = %q, %v; want %q, %v", tc.input, id, ok, tc.wantID, tc.wantOK)
+            }
+        })
+    }
+}
+
+func TestRoleAlertMessage(t *testing.T) {
+    a := store.ThresholdAlert{
+        RoleID:    "123",
+        GuildID:   "g1",
+        Threshold: 3,
+        Direction: store.Below,
+        Snapshot:  store.MacGymSnapshot{InUse: 2, Capacity: 10},
+    }
+
+    msg := roleAlertMessage(a)
+    if !strings.HasPrefix(msg.Content, "<@&123> ") {
+        t.Errorf("Expected role mention prefix, got %q", msg.Content)
+    }
+    if !strings.Contains(msg.Content, "role alert: fewer than 3 in use") {
+        t.Errorf("Expected role alert wording, got %q", msg.Content)
+    }
+    if msg.AllowedMentions == nil || len(msg.AllowedMentions.Parse) != 0 || len(msg.AllowedMentions.Users) != 0 {
+        t.Fatalf("Expected only explicit role mentions, got %+v", msg.AllowedMentions)
+    }
+    if roles := msg.AllowedMentions.Roles; len(roles) != 1 || roles[0] != "123" {
+        t.Errorf("Expected only the subscribed role to be mentionable, got %v", roles)
+    }
+}
@@ -0,0 +1,151 @@
+package discord
+
+import (
+    "fmt"
+    "sort"
+    "strings"
+    "time"
+
+    "github.com/bwmarrin/discordgo"
+
+    "github.com/sjsu-badminton/badminton-discord-bot/internal/sched"
+    "github.com/sjsu-badminton/badminton-discord-bot/internal/util"
+)
+
+// maxStatusError bounds how much of a scrape error is shown in /status
+const maxStatusError = 200
+
+// statusSources lists the scraped sources in the order /status shows them
+var statusSources = []string{sched.SourceMacGym, sched.SourceFitness}
+
+func (c *Client) handleStatus(s *discordgo.Session, i *discordgo.InteractionCreate) {
+    c.respondReply(s, i, c.statusReply())
+}
+
+// statusPrefix handles "!status"
+func statusPrefix(c *Client, m *discordgo.MessageCreate, args []string) reply {
+    if !c.canManageGuild(m) {
+        return reply{Content: "❌ You need the Manage Server permission to see the bot status."}
+    }
+    return c.statusReply()
+}
+
+func (c *Client) statusReply() reply {
+    sources := make([]sched.SourceStatus, 0, len(statusSources))
+    for _, source := range statusSources {
+        sources = append(sources, c.sourceStatus(source))
+    }
+
+    var breakers []util.BreakerStatus
+    if c.cron != nil {
+        breakers = c.cron.Breakers()
+    }
+
+    return reply{Embed: statusEmbed(sources, breakers, c.outbox.Stats(), time.Now()), Ephemeral: true}
+}
+
+// statusEmbed summarises scrape health, circuit breakers and message
+// delivery for admins
+func statusEmbed(sources []sched.SourceStatus, breakers []util.BreakerStatus, stats map[string]DeliveryStats, now time.Time) *discordgo.MessageEmbed {
+    embed := &discordgo.MessageEmbed{
+        Title:     "🩺 Bot Status",
+        Color:     0x00ff00, // Green
+        Timestamp: now.Format(time.RFC3339),
+        Footer: &discordgo.MessageEmbedFooter{
+            Text: "SJSU Badminton Bot",
+        },
+    }
+
+    for _, s := range sources {
+        if s.Failing() {
+            embed.Color = 0xff0000 // Red
+        }
+        embed.Fields = append(embed.Fields, &discordgo.MessageEmbedField{
+            Name:  sourceName(s.Source),
+            Value: describeSourceStatus(s),
+        })
+    }
+
+    embed.Fields = append(embed.Fields, &discordgo.MessageEmbedField{
+        Name:  "Circuit Breakers",
+        Value: describeBreakers(breakers),
+    })
+    embed.Fields = append(embed.Fields, &discordgo.MessageEmbedField{
+        Name:  "Outbound Messages",
+        Value: describeDeliveryStats(stats),
+    })
+
+    return embed
+}
+
+func describeSourceStatus(s sched.SourceStatus) string {
+    lastSuccess := "never"
+    if !s.LastSuccess.IsZero() {
+        lastSuccess = fmt.Sprintf("<t:%d:R>", s.LastSuccess.Unix())
+    }
+
+    switch {
+    case s.Failing():
+        text := fmt.Sprintf("❌ Failing since <t:%d:f> (%d in a row), last success %s",
+            s.FailingSince.Unix(), s.ConsecutiveFailures, lastSuccess)
+        if s.LastError != "" {
+            text += fmt.Sprintf("\nLast error: `%s`", truncate(s.LastError, maxStatusError))
+        }
+        return text
+    case s.LastSuccess.IsZero():
+        return "⏳ Not scraped yet"
+    }
+    return "✅ Last success " + lastSuccess
+}
+
+func describeBreakers(breakers []util.BreakerStatus) string {
+    if len(breakers) == 0 {
+        return "No requests made yet"
+    }
+
+    lines := make([]string, 0, len(breakers))
+    for _, b := range breakers {
+        var line string
+        switch b.State {
+        case util.BreakerOpen:
+            line = fmt.Sprintf("🔴 `%s` open after %d failures, next try <t:%d:R>", b.Host, b.Failures, b.RetryAt.Unix())
+        case util.BreakerHalfOpen:
+            line = fmt.Sprintf("🟡 `%s` half-open, trial request in flight", b.Host)
+        default:
+            line = fmt.Sprintf("🟢 `%s` closed", b.Host)
+            if b.Failures > 0 {
+                line += fmt.Sprintf(" (%d recent failures)", b.Failures)
+            }
+        }
+        lines = append(lines, line)
+    }
+    return strings.Join(lines, "\n")
+}
+
+func describeDeliveryStats(stats map[string]DeliveryStats) string {
+    if len(stats) == 0 {
+        return "Nothing sent yet"
+    }
+
+    kinds := make([]string, 0, len(stats))
+    for kind := range stats {
+        kinds = append(kinds, kind)
+    }
+    sort.Strings(kinds)
+
+    lines := make([]string, 0, len(kinds))
+    for _, kind := range kinds {
+        s := stats[kind]
+        lines = append(lines, fmt.Sprintf("**%s**: %d delivered, %d retried, %d failed, %d dropped",
+            kind, s.Delivered, s.Retried, s.Failed, s.Dropped))
+    }
+    return strings.Join(lines, "\n")
+}
+
+// truncate shortens s to at most n bytes, marking the cut with "…"
+func truncate(s string, n int) string {
+    if len(s) <= n {
+        return s
+    }
+    return strings.ToValidUTF8(s[:n], "") + "…"
+}
@@ -0,0 +1,80 @@
+package discord
+
+import (
+    "strings"
+    "testing"
+    "time"
+
+    "github.com/sjsu-badminton/badminton-discord-bot/internal/sched"
+    "github.com/sjsu-badminton/badminton-discord-bot/internal/util"
+)
+
+func TestStatusEmbed(t *testing.T) {
+    now := time.Date(2024, 1, 15, 12, 0, 0, 0, time.UTC)
+    healthy := sched.SourceStatus{Source: sched.SourceMacGym, LastSuccess: now.Add(-time.Minute)}
+    failing := sched.SourceStatus{
+        Source:              sched.SourceFitness,
+        LastSuccess:         now.Add(-time.Hour),
+        FailingSince:        now.Add(-30 * time.Minute),
+        ConsecutiveFailures: 4,
+        LastError:           "fetching fitness schedule: " + strings.Repeat("x", 300),
+    }
+    open := util.BreakerStatus{Host: "fitness.sjsu.edu", State: util.BreakerOpen, Failures: 3, RetryAt: now.Add(5 * time.Minute)}
+    closed := util.BreakerStatus{Host: "www.connect2mycloud.com", State: util.BreakerClosed}
+
+    testCases := []struct {
+        name      string
+        sources   []sched.SourceStatus
+        breakers  []util.BreakerStatus
+        stats     map[string]DeliveryStats
+        wantColor int
+        want      []string
+    }{
+        {
+            name:      "nothing yet",
+            sources:   []sched.SourceStatus{{Source: sched.SourceMacGym}},
+            wantColor: 0x00ff00,
+            want:      []string{"Not scraped yet", "No requests made yet", "Nothing sent yet"},
+        },
+        {
+            name:      "healthy",
+            sources:   []sched.SourceStatus{healthy},
+            breakers:  []util.BreakerStatus{closed},
+            stats:     map[string]DeliveryStats{kindAlert: {Delivered: 5, Retried: 1}},
+            wantColor: 0x00ff00,
+            want:      []string{"Last success <t:1705319940:R>", "`www.connect2mycloud.com` closed", "**alert**: 5 delivered, 1 retried, 0 failed, 0 dropped"},
+        },
+        {
+            name:      "failing with open breaker",
+            sources:   []sched.SourceStatus{healthy, failing},
+            breakers:  []util.BreakerStatus{open, closed},
+            wantColor: 0xff0000,
+            want:      []string{"Failing since <t:1705318200:f> (4 in a row)", "Last error: `fetching fitness schedule: xxx", "…`", "`fitness.sjsu.edu` open after 3 failures, next try <t:1705320300:R>"},
+        },
+    }
+
+    for _, tc := range testCases {
+        t.Run(tc.name, func(t *testing.T) {
+            embed := statusEmbed(tc.sources, tc.breakers, tc.stats, now)
+            if embed.Color != tc.wantColor {
+                t.Errorf("Expected color %#x, got %#x", tc.wantColor, embed.Color)
+            }
+            if len(embed.Fields) != len(tc.sources)+2 {
+                t.Fatalf("Expected %d fields, got %d", len(tc.sources)+2, len(embed.Fields))
+            }
+
+            var text strings.Builder
+            for _, f := range embed.Fields {
+                if len(f.Value) > 1024 {
+                    t.Errorf("Field %q is longer than Discord allows", f.Name)
+                }
+                text.WriteString(f.Value + "\n")
+            }
+            for _, want := range tc.want {
+                if !strings.Contains(text.String(), want) {
+                    t.Errorf("Expected %q in status:\n%s", want, text.String())
+                }
+            }
+        })
+    }
+}
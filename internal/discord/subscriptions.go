@@ -0,0 +1,142 @@
+package discord
+
+import (
+    "fmt"
+    "strings"
+    "time"
+
+    "github.com/bwmarrin/discordgo"
+
+    "github.com/sjsu-badminton/badminton-discord-bot/internal/config"
+    "github.com/sjsu-badminton/badminton-discord-bot/internal/store"
+)
+
+// parseWindow builds an active window from optional days ("weekdays",
+// "mon,wed") and hours ("17:00-22:00") options
+func parseWindow(days, hours string) (store.Window, error) {
+    var w store.Window
+    var err error
+
+    if days != "" {
+        if w.Days, err = store.ParseDays(days); err != nil {
+            return store.Window{}, err
+        }
+    }
+    if hours != "" {
+        if w.Hours, err = store.ParseClockRange(hours); err != nil {
+            return store.Window{}, err
+        }
+    }
+    return w, nil
+}
+
+// describeSubscription summarizes an occupancy alert for replies and listings
+func describeSubscription(sub store.Subscription) string {
+    var alert string
+    switch {
+    case sub.Threshold == 0:
+        alert = "General updates only (no occupancy threshold)"
+    case sub.Direction == store.Below:
+        alert = fmt.Sprintf("When fewer than %d courts are in use", sub.Threshold)
+    default:
+        alert = fmt.Sprintf("When %d or more courts are in use", sub.Threshold)
+    }
+
+    if !sub.Active.IsZero() {
+        alert += fmt.Sprintf("\n**Active:** %s", sub.Active)
+    }
+    return alert
+}
+
+func (c *Client) quietHoursReply(userID, hours string) reply {
+    switch strings.ToLower(strings.TrimSpace(hours)) {
+    case "":
+        r := c.store.QuietHours(userID)
+        if r.IsZero() {
+            return reply{Content: "You don't have quiet hours set. Use `/quiethours 22:00-08:00` to mute occupancy alerts overnight.", Ephemeral: true}
+        }
+        return reply{Content: fmt.Sprintf("Your quiet hours are %s (%s).", r, c.cfg.TZ), Ephemeral: true}
+    case "off", "none":
+        c.store.SetQuietHours(userID, store.ClockRange{})
+        return reply{Content: "✅ Quiet hours cleared.", Ephemeral: true}
+    }
+
+    r, err := store.ParseClockRange(hours)
+    if err != nil {
+        return reply{Content: fmt.Sprintf("❌ %v. Use a range like `22:00-08:00`.", err), Ephemeral: true}
+    }
+
+    c.store.SetQuietHours(userID, r)
+    return reply{
+        Content:   fmt.Sprintf("✅ You won't get occupancy alerts between %s (%s).", r, c.cfg.TZ),
+        Ephemeral: true,
+    }
+}
+
+func (c *Client) digestReply(userID, frequency string) reply {
+    frequency = strings.ToLower(strings.TrimSpace(frequency))
+    if frequency == "off" {
+        c.store.SetDigest(userID, "")
+        return reply{Content: "✅ Digest turned off.", Ephemeral: true}
+    }
+
+    f := store.DigestFrequency(frequency)
+    if !f.Valid() {
+        return reply{Content: "❌ Choose a digest frequency: `daily`, `weekly` or `off`.", Ephemeral: true}
+    }
+
+    c.store.SetDigest(userID, f)
+    return reply{
+        Content:   fmt.Sprintf("✅ You'll get a %s digest DM with upcoming events and Mac Gym occupancy (%s).", f, describeDigestSchedule(f, c.cfg)),
+        Ephemeral: true,
+    }
+}
+
+// describeDigestSchedule explains when a digest is delivered
+func describeDigestSchedule(f store.DigestFrequency, cfg config.Config) string {
+    spec := cfg.CronDigestDaily
+    if f == store.DigestWeekly {
+        spec = cfg.CronDigestWeekly
+    }
+    return fmt.Sprintf("schedule `%s`, %s", spec, cfg.TZ)
+}
+
+func (c *Client) subscriptionsReply(userID string) reply {
+    embed := &discordgo.MessageEmbed{
+        Title:       "🔔 Your Badminton Alerts",
+        Description: fmt.Sprintf("Times are in %s.", c.cfg.TZ),
+        Color:       0x0099ff,
+        Footer: &discordgo.MessageEmbedFooter{
+            Text: "SJSU Badminton Bot",
+        },
+    }
+
+    alert := "Not subscribed. Use `/subscribe occupancy` to get occupancy alerts."
+    if sub, ok := c.store.Subscribers()[userID]; ok {
+        alert = describeSubscription(sub)
+    }
+
+    quiet := "None"
+    if r := c.store.QuietHours(userID); !r.IsZero() {
+        quiet = r.String()
+    }
+
+    reminders := "Off"
+    if lead, ok := c.store.ReminderLead(userID); ok {
+        reminders = fmt.Sprintf("%d minutes before each event", int(lead/time.Minute))
+    }
+
+    digest := "Off"
+    if f := c.store.Digest(userID); f != "" {
+        digest = strings.ToUpper(string(f[:1])) + string(f[1:])
+    }
+
+    embed.Fields = []*discordgo.MessageEmbedField{
+        {Name: "Occupancy Alert", Value: alert},
+        {Name: "Quiet Hours", Value: quiet, Inline: true},
+        {Name: "Event Reminders", Value: reminders, Inline: true},
+        {Name: "Digest", Value: digest, Inline: true},
+    }
+
+    return reply{Embed: embed, Ephemeral: true}
+}
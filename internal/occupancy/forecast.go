@@ -0,0 +1,106 @@
+package occupancy
+
+import (
+    "math"
+    "time"
+
+    "github.com/sjsu-badminton/badminton-discord-bot/internal/store"
+)
+
+const (
+    // recentWindow is how far back readings count towards the current level
+    recentWindow = 15 * time.Minute
+
+    // deviationHalfLife is how quickly today's deviation from the seasonal
+    // baseline fades as the forecast looks further ahead
+    deviationHalfLife = time.Hour
+)
+
+// Basis describes which inputs a prediction was derived from
+type Basis int
+
+const (
+    BasisBlended Basis = iota // seasonal baseline adjusted by recent readings
+    BasisSeasonal             // seasonal baseline only
+    BasisRecent               // recent readings only, no history for the slot
+)
+
+func (b Basis) String() string {
+    switch b {
+    case BasisSeasonal:
+        return "historical average"
+    case BasisRecent:
+        return "current level"
+    default:
+        return "historical average + current level"
+    }
+}
+
+// Prediction is the expected occupancy at a point in time
+type Prediction struct {
+    At       time.Time
+    Expected float64
+    Capacity int
+    Basis    Basis
+}
+
+// Predict forecasts occupancy at now+h for each horizon. The seasonal
+// weekday/hour baseline is shifted by how far the recent readings (ordered
+// oldest first) currently deviate from it, with that deviation decaying
+// over time. Horizons without any usable input are omitted.
+func Predict(p *Profile, recent []store.MacGymSnapshot, now time.Time, horizons ...time.Duration) []Prediction {
+    level, capacity, hasLevel := currentLevel(recent, now)
+    if capacity == 0 {
+        capacity = p.Capacity()
+    }
+
+    local := now.In(p.loc)
+    baseNow, hasBaseNow := p.Average(local.Weekday(), local.Hour())
+
+    var out []Prediction
+    for _, h := range horizons {
+        at := now.Add(h).In(p.loc)
+        base, hasBase := p.Average(at.Weekday(), at.Hour())
+
+        pred := Prediction{At: at, Capacity: capacity}
+        switch {
+        case hasBase && hasLevel && hasBaseNow:
+            decay := math.Pow(0.5, float64(h)/float64(deviationHalfLife))
+            pred.Expected = base + (level-baseNow)*decay
+            pred.Basis = BasisBlended
+        case hasBase:
+            pred.Expected = base
+            pred.Basis = BasisSeasonal
+        case hasLevel:
+            pred.Expected = level
+            pred.Basis = BasisRecent
+        default:
+            continue
+        }
+
+        pred.Expected = math.Max(0, pred.Expected)
+        if capacity > 0 {
+            pred.Expected = math.Min(float64(capacity), pred.Expected)
+        }
+        out = append(out, pred)
+    }
+    return out
+}
+
+// currentLevel averages readings from the last recentWindow before now
+func currentLevel(recent []store.MacGymSnapshot, now time.Time) (level float64, capacity int, ok bool) {
+    var sum float64
+    var n int
+    for _, r := range recent {
+        if r.Capacity == 0 || r.RetrievedAt.Before(now.Add(-recentWindow)) || r.RetrievedAt.After(now) {
+            continue
+        }
+        sum += float64(r.InUse)
+        n++
+        capacity = r.Capacity
+    }
+    if n == 0 {
+        return 0, 0, false
+    }
+    return sum / float64(n), capacity, true
+}
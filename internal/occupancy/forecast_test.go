@@ -0,0 +1,83 @@
+package occupancy
+
+import (
+    "math"
+    "testing"
+    "time"
+
+    "github.com/sjsu-badminton/badminton-discord-bot/internal/store"
+)
+
+func TestPredict(t *testing.T) {
+    loc := time.UTC
+    lastWeek := time.Date(2024, 1, 15, 0, 0, 0, 0, loc) // Monday
+    now := lastWeek.AddDate(0, 0, 7).Add(17 * time.Hour)  // next Monday 5pm
+
+    // Seasonal baseline: 4 courts at 5pm, 6 at 6pm, nothing recorded at 7pm
+    history := []store.MacGymSnapshot{
+        {RetrievedAt: lastWeek.Add(17 * time.Hour), Capacity: 8, InUse: 4},
+        {RetrievedAt: lastWeek.Add(18 * time.Hour), Capacity: 8, InUse: 6},
+    }
+    p := BuildProfile(history, loc)
+
+    // Right now it's 2 courts busier than usual
+    recent := []store.MacGymSnapshot{
+        {RetrievedAt: now.Add(-30 * time.Minute), Capacity: 8, InUse: 0}, // too old to count
+        {RetrievedAt: now.Add(-10 * time.Minute), Capacity: 8, InUse: 6},
+        {RetrievedAt: now.Add(-2 * time.Minute), Capacity: 8, InUse: 6},
+    }
+
+    testCases := []struct {
+        name     string
+        recent   []store.MacGymSnapshot
+        horizon  time.Duration
+        expected float64
+        basis    Basis
+    }{
+        {"blended now", recent, 0, 6, BasisBlended},
+        {"blended 1h decays deviation", recent, time.Hour, 7, BasisBlended},
+        {"no history for slot falls back to current level", recent, 2 * time.Hour, 6, BasisRecent},
+        {"seasonal only without recent readings", nil, time.Hour, 6, BasisSeasonal},
+    }
+
+    for _, tc := range testCases {
+        t.Run(tc.name, func(t *testing.T) {
+            preds := Predict(p, tc.recent, now, tc.horizon)
+            if len(preds) != 1 {
+                t.Fatalf("Expected 1 prediction, got %d", len(preds))
+            }
+
+            pred := preds[0]
+            if math.Abs(pred.Expected-tc.expected) > 0.001 {
+                t.Errorf("Expected %.2f, got %.2f", tc.expected, pred.Expected)
+            }
+            if pred.Basis != tc.basis {
+                t.Errorf("Expected basis %s, got %s", tc.basis, pred.Basis)
+            }
+            if pred.Capacity != 8 {
+                t.Errorf("Expected capacity 8, got %d", pred.Capacity)
+            }
+        })
+    }
+}
+
+func TestPredictWithoutData(t *testing.T) {
+    p := BuildProfile(nil, time.UTC)
+    if preds := Predict(p, nil, time.Now(), time.Hour); len(preds) != 0 {
+        t.Errorf("Expected no predictions without data, got %+v", preds)
+    }
+}
+
+func TestPredictClampsToCapacity(t *testing.T) {
+    now := time.Date(2024, 1, 22, 17, 0, 0, 0, time.UTC)
+    p := BuildProfile([]store.MacGymSnapshot{
+        {RetrievedAt: now.AddDate(0, 0, -7), Capacity: 8, InUse: 1},
+        {RetrievedAt: now.AddDate(0, 0, -7).Add(time.Hour), Capacity: 8, InUse: 7},
+    }, time.UTC)
+
+    recent := []store.MacGymSnapshot{{RetrievedAt: now, Capacity: 8, InUse: 8}}
+    preds := Predict(p, recent, now, time.Hour)
+    if len(preds) != 1 || preds[0].Expected != 8 {
+        t.Errorf("Expected prediction clamped to capacity 8, got %+v", preds)
+    }
+}
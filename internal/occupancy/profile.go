@@ -0,0 +1,167 @@
+package occupancy
+
+import (
+    "sort"
+    "time"
+
+    "github.com/sjsu-badminton/badminton-discord-bot/internal/store"
+)
+
+// maxCarry bounds how long a reading is assumed to stay current when no
+// newer one follows, so a gap while the bot was down isn't filled in
+const maxCarry = 3 * time.Hour
+
+// cell accumulates courts in use weighted by seconds, and the number of
+// readings that were current during the slot
+type cell struct {
+    sum    float64
+    weight float64
+    n      int
+}
+
+// Profile is the average occupancy for each weekday and hour of day
+type Profile struct {
+    loc      *time.Location
+    cells    [7][24]cell
+    capacity int
+}
+
+// BuildProfile aggregates readings into a weekday×hour profile in loc.
+// Readings are only recorded when the source updates, so each one counts
+// for as long as it stayed current: until the next reading, at most
+// maxCarry later. The last reading counts until the end of its hour. This
+// keeps flat stretches, like an empty gym whose count never changes, from
+// having no samples. Readings without capacity data are ignored.
+func BuildProfile(readings []store.MacGymSnapshot, loc *time.Location) *Profile {
+    p := &Profile{loc: loc}
+
+    var valid []store.MacGymSnapshot
+    for _, r := range readings {
+        if r.Capacity != 0 {
+            valid = append(valid, r)
+        }
+    }
+    sort.SliceStable(valid, func(i, j int) bool {
+        return valid[i].RetrievedAt.Before(valid[j].RetrievedAt)
+    })
+
+    for i, r := range valid {
+        start := r.RetrievedAt.In(loc)
+        end := start.Add(maxCarry)
+        if i+1 < len(valid) {
+            if next := valid[i+1].RetrievedAt.In(loc); next.Before(end) {
+                end = next
+            }
+        } else {
+            end = startOfHour(start).Add(time.Hour)
+        }
+        p.add(r.InUse, start, end)
+        p.capacity = r.Capacity
+    }
+    return p
+}
+
+// add spreads a reading current from start to end over the hour slots it
+// covers, weighted by how long it was current in each
+func (p *Profile) add(inUse int, start, end time.Time) {
+    for t := start; t.Before(end); {
+        next := startOfHour(t).Add(time.Hour)
+        if next.After(end) {
+            next = end
+        }
+        w := next.Sub(t).Seconds()
+        c := &p.cells[t.Weekday()][t.Hour()]
+        c.sum += float64(inUse) * w
+        c.weight += w
+        c.n++
+        t = next
+    }
+}
+
+// startOfHour truncates t to the hour in its own location
+func startOfHour(t time.Time) time.Time {
+    return time.Date(t.Year(), t.Month(), t.Day(), t.Hour(), 0, 0, 0, t.Location())
+}
+
+// Average returns the time-weighted mean courts in use for the weekday and
+// hour, and false when no reading was current during that slot
+func (p *Profile) Average(day time.Weekday, hour int) (float64, bool) {
+    c := p.cells[day][hour]
+    if c.weight == 0 {
+        return 0, false
+    }
+    return c.sum / c.weight, true
+}
+
+// Samples returns the number of readings current during the weekday and hour
+func (p *Profile) Samples(day time.Weekday, hour int) int {
+    return p.cells[day][hour].n
+}
+
+// Capacity returns the capacity of the most recent reading in the profile
+func (p *Profile) Capacity() int {
+    return p.capacity
+}
+
+// Empty reports whether the profile has no readings at all
+func (p *Profile) Empty() bool {
+    for d := range p.cells {
+        for h := range p.cells[d] {
+            if p.cells[d][h].n > 0 {
+                return false
+            }
+        }
+    }
+    return true
+}
+
+// Window is a one hour slot with its historical average occupancy
+type Window struct {
+    Start   time.Time
+    Avg     float64
+    Samples int
+}
+
+// Windows returns the hourly slots starting in [from, until) that have
+// history, in chronological order. Slots begin on the hour; the current
+// partial hour is included.
+func (p *Profile) Windows(from, until time.Time) []Window {
+    var out []Window
+    local := from.In(p.loc)
+    t := time.Date(local.Year(), local.Month(), local.Day(), local.Hour(), 0, 0, 0, p.loc)
+    for ; t.Before(until); t = t.Add(time.Hour) {
+        avg, ok := p.Average(t.Weekday(), t.Hour())
+        if !ok {
+            continue
+        }
+        out = append(out, Window{
+            Start:   t,
+            Avg:     avg,
+            Samples: p.Samples(t.Weekday(), t.Hour()),
+        })
+    }
+    return out
+}
+
+// Quietest returns up to n windows with the lowest average occupancy,
+// earliest first among ties
+func Quietest(windows []Window, n int) []Window {
+    return rank(windows, n, func(a, b Window) bool { return a.Avg < b.Avg })
+}
+
+// Busiest returns up to n windows with the highest average occupancy,
+// earliest first among ties
+func Busiest(windows []Window, n int) []Window {
+    return rank(windows, n, func(a, b Window) bool { return a.Avg > b.Avg })
+}
+
+func rank(windows []Window, n int, better func(a, b Window) bool) []Window {
+    sorted := append([]Window(nil), windows...)
+    sort.SliceStable(sorted, func(i, j int) bool {
+        return better(sorted[i], sorted[j])
+    })
+    if len(sorted) > n {
+        sorted = sorted[:n]
+    }
+    return sorted
+}
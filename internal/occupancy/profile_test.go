@@ -0,0 +1,115 @@
+package occupancy
+
+import (
+    "testing"
+    "time"
+
+    "github.com/sjsu-badminton/badminton-discord-bot/internal/store"
+)
+
+func TestBuildProfile(t *testing.T) {
+    loc := time.UTC
+    monday := time.Date(2024, 1, 15, 0, 0, 0, 0, loc)
+
+    var rs []store.MacGymSnapshot
+    // Two weeks of Mondays: quiet at 8am, busy at 6pm
+    for week := 0; week < 2; week++ {
+        day := monday.AddDate(0, 0, 7*week)
+        rs = append(rs,
+            store.MacGymSnapshot{RetrievedAt: day.Add(8 * time.Hour), Capacity: 8, InUse: 1 + week},
+            store.MacGymSnapshot{RetrievedAt: day.Add(18 * time.Hour), Capacity: 8, InUse: 7},
+            store.MacGymSnapshot{RetrievedAt: day.Add(20 * time.Hour), InUse: 0}, // no capacity data
+        )
+    }
+
+    p := BuildProfile(rs, loc)
+
+    testCases := []struct {
+        name string
+        day  time.Weekday
+        hour int
+        avg  float64
+        ok   bool
+    }{
+        {"morning", time.Monday, 8, 1.5, true},
+        {"morning carried forward", time.Monday, 10, 1.5, true},
+        {"evening", time.Monday, 18, 7, true},
+        {"reading without capacity data ignored", time.Monday, 20, 7, true},
+        {"beyond the carry limit", time.Monday, 21, 0, false},
+        {"other day", time.Tuesday, 8, 0, false},
+    }
+
+    for _, tc := range testCases {
+        t.Run(tc.name, func(t *testing.T) {
+            avg, ok := p.Average(tc.day, tc.hour)
+            if ok != tc.ok || avg != tc.avg {
+                t.Errorf("Expected (%.1f, %v), got (%.1f, %v)", tc.avg, tc.ok, avg, ok)
+            }
+        })
+    }
+
+    if p.Capacity() != 8 {
+        t.Errorf("Expected capacity 8, got %d", p.Capacity())
+    }
+}
+
+func TestQuietestAndBusiest(t *testing.T) {
+    loc := time.UTC
+    monday := time.Date(2024, 1, 15, 0, 0, 0, 0, loc)
+
+    var rs []store.MacGymSnapshot
+    for hour, inUse := range map[int]int{9: 2, 10: 5, 11: 1, 12: 6, 13: 1} {
+        rs = append(rs, store.MacGymSnapshot{
+            RetrievedAt: monday.Add(time.Duration(hour) * time.Hour),
+            Capacity:    8,
+            InUse:       inUse,
+        })
+    }
+    p := BuildProfile(rs, loc)
+
+    // One week later, starting mid-way through the 10 o'clock hour
+    from := monday.AddDate(0, 0, 7).Add(10*time.Hour + 30*time.Minute)
+    windows := p.Windows(from, from.Add(24*time.Hour))
+    if len(windows) != 4 {
+        t.Fatalf("Expected 4 windows with history, got %d", len(windows))
+    }
+
+    quiet := Quietest(windows, 2)
+    if len(quiet) != 2 || quiet[0].Start.Hour() != 11 || quiet[1].Start.Hour() != 13 {
+        t.Errorf("Unexpected quietest windows: %+v", quiet)
+    }
+
+    busy := Busiest(windows, 1)
+    if len(busy) != 1 || busy[0].Start.Hour() != 12 {
+        t.Errorf("Unexpected busiest windows: %+v", busy)
+    }
+}
+
+func TestProfileWeightsReadingsByTime(t *testing.T) {
+    loc := time.UTC
+    monday := time.Date(2024, 1, 15, 0, 0, 0, 0, loc)
+    at := func(h, m, inUse int) store.MacGymSnapshot {
+        return store.MacGymSnapshot{RetrievedAt: monday.Add(time.Duration(h)*time.Hour + time.Duration(m)*time.Minute), Capacity: 8, InUse: inUse}
+    }
+
+    // Busy at 9 with frequent updates, emptying at 10:50, then no update
+    // at all through the idle 11 o'clock hour
+    p := BuildProfile([]store.MacGymSnapshot{
+        at(9, 0, 5), at(9, 20, 6), at(9, 40, 5),
+        at(10, 0, 4), at(10, 50, 0),
+        at(12, 0, 3), at(12, 20, 4), at(12, 40, 3),
+    }, loc)
+
+    if avg, ok := p.Average(time.Monday, 11); !ok || avg != 0 {
+        t.Errorf("Expected the idle hour to average 0 from the carried reading, got (%.2f, %v)", avg, ok)
+    }
+    if avg, _ := p.Average(time.Monday, 10); avg < 3.3 || avg > 3.4 {
+        t.Errorf("Expected 10 o'clock weighted to 3.33 (4 for 50 minutes, 0 for 10), got %.2f", avg)
+    }
+
+    from := monday.AddDate(0, 0, 7).Add(9 * time.Hour)
+    quiet := Quietest(p.Windows(from, from.Add(4*time.Hour)), 1)
+    if len(quiet) != 1 || quiet[0].Start.Hour() != 11 {
+        t.Errorf("Expected the idle 11 o'clock hour to be the quietest, got %+v", quiet)
+    }
+}
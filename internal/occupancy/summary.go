@@ -0,0 +1,112 @@
+package occupancy
+
+import (
+    "math"
+    "time"
+
+    "github.com/sjsu-badminton/badminton-discord-bot/internal/store"
+)
+
+// Trend describes the direction occupancy is moving over a window
+type Trend int
+
+const (
+    TrendSteady Trend = iota
+    TrendRising
+    TrendFalling
+)
+
+func (t Trend) String() string {
+    switch t {
+    case TrendRising:
+        return "rising"
+    case TrendFalling:
+        return "falling"
+    default:
+        return "steady"
+    }
+}
+
+// steadySlope is the change in courts per hour below which occupancy is considered steady
+const steadySlope = 0.5
+
+// Summary aggregates occupancy readings over a window
+type Summary struct {
+    Samples  int
+    From     time.Time
+    To       time.Time
+    Min      int
+    Max      int
+    Avg      float64
+    Capacity int     // capacity of the latest reading
+    Slope    float64 // least-squares change in courts per hour
+    Trend    Trend
+}
+
+// Summarize computes min/avg/max and trend for readings ordered oldest first.
+// Readings without capacity data are ignored.
+func Summarize(readings []store.MacGymSnapshot) Summary {
+    var sum Summary
+    var total float64
+
+    for _, r := range readings {
+        if r.Capacity == 0 {
+            continue
+        }
+        if sum.Samples == 0 {
+            sum.From = r.RetrievedAt
+            sum.Min = r.InUse
+            sum.Max = r.InUse
+        }
+        sum.Samples++
+        sum.To = r.RetrievedAt
+        sum.Capacity = r.Capacity
+        total += float64(r.InUse)
+        if r.InUse < sum.Min {
+            sum.Min = r.InUse
+        }
+        if r.InUse > sum.Max {
+            sum.Max = r.InUse
+        }
+    }
+
+    if sum.Samples == 0 {
+        return sum
+    }
+    sum.Avg = total / float64(sum.Samples)
+
+    sum.Slope = slopePerHour(readings, sum.From)
+    switch {
+    case sum.Slope >= steadySlope:
+        sum.Trend = TrendRising
+    case sum.Slope <= -steadySlope:
+        sum.Trend = TrendFalling
+    default:
+        sum.Trend = TrendSteady
+    }
+
+    return sum
+}
+
+// slopePerHour fits InUse against hours since origin with least squares
+func slopePerHour(readings []store.MacGymSnapshot, origin time.Time) float64 {
+    var n, sx, sy, sxx, sxy float64
+    for _, r := range readings {
+        if r.Capacity == 0 {
+            continue
+        }
+        x := r.RetrievedAt.Sub(origin).Hours()
+        y := float64(r.InUse)
+        n++
+        sx += x
+        sy += y
+        sxx += x * x
+        sxy += x * y
+    }
+
+    denom := n*sxx - sx*sx
+    if n < 2 || math.Abs(denom) < 1e-9 {
+        return 0
+    }
+    return (n*sxy - sx*sy) / denom
+}
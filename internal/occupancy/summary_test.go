@@ -0,0 +1,64 @@
+package occupancy
+
+import (
+    "testing"
+    "time"
+
+    "github.com/sjsu-badminton/badminton-discord-bot/internal/store"
+)
+
+func readings(start time.Time, step time.Duration, inUse ...int) []store.MacGymSnapshot {
+    out := make([]store.MacGymSnapshot, len(inUse))
+    for i, v := range inUse {
+        out[i] = store.MacGymSnapshot{
+            RetrievedAt: start.Add(time.Duration(i) * step),
+            Capacity:    8,
+            InUse:       v,
+        }
+    }
+    return out
+}
+
+func TestSummarize(t *testing.T) {
+    start := time.Date(2024, 1, 15, 14, 0, 0, 0, time.UTC)
+
+    testCases := []struct {
+        name     string
+        readings []store.MacGymSnapshot
+        samples  int
+        min      int
+        max      int
+        avg      float64
+        trend    Trend
+    }{
+        {"empty", nil, 0, 0, 0, 0, TrendSteady},
+        {"flat", readings(start, 30*time.Minute, 4, 4, 4, 4), 4, 4, 4, 4, TrendSteady},
+        {"rising", readings(start, 30*time.Minute, 1, 2, 4, 6, 8), 5, 1, 8, 4.2, TrendRising},
+        {"falling", readings(start, 30*time.Minute, 7, 5, 3, 2), 4, 2, 7, 4.25, TrendFalling},
+        {"noise", readings(start, 30*time.Minute, 5, 6, 5, 6, 5, 6), 6, 5, 6, 5.5, TrendSteady},
+        {
+            "skips readings without capacity",
+            append(readings(start, time.Hour, 3, 5), store.MacGymSnapshot{RetrievedAt: start.Add(3 * time.Hour)}),
+            2, 3, 5, 4, TrendRising,
+        },
+    }
+
+    for _, tc := range testCases {
+        t.Run(tc.name, func(t *testing.T) {
+            sum := Summarize(tc.readings)
+
+            if sum.Samples != tc.samples {
+                t.Errorf("Expected %d samples, got %d", tc.samples, sum.Samples)
+            }
+            if sum.Min != tc.min || sum.Max != tc.max {
+                t.Errorf("Expected min/max %d/%d, got %d/%d", tc.min, tc.max, sum.Min, sum.Max)
+            }
+            if diff := sum.Avg - tc.avg; diff > 0.001 || diff < -0.001 {
+                t.Errorf("Expected avg %.2f, got %.2f", tc.avg, sum.Avg)
+            }
+            if sum.Trend != tc.trend {
+                t.Errorf("Expected trend %s, got %s (slope %.2f)", tc.trend, sum.Trend, sum.Slope)
+            }
+        })
+    }
+}
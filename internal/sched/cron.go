@@ -2,25 +2,66 @@ package sched
 
 import (
     "context"
+    "errors"
+    "fmt"
     "log/slog"
     "math/rand"
+    "sync/atomic"
     "time"
 
     "github.com/robfig/cron/v3"
 
+    "github.com/sjsu-badminton/badminton-discord-bot/internal/classify"
     "github.com/sjsu-badminton/badminton-discord-bot/internal/config"
     "github.com/sjsu-badminton/badminton-discord-bot/internal/scrape"
     "github.com/sjsu-badminton/badminton-discord-bot/internal/store"
     "github.com/sjsu-badminton/badminton-discord-bot/internal/util"
 )
 
+// SourceFitness identifies events scraped from the SJSU fitness schedule
+const SourceFitness = "fitness"
+
+// Notifier receives scheduler updates that should be posted to Discord
+type Notifier interface {
+    // AnnounceEvents is called with newly discovered upcoming events
+    AnnounceEvents(events []store.Event)
+    // AnnounceMoves is called with upcoming events that were rescheduled or
+    // moved to another location
+    AnnounceMoves(moves []store.Change)
+    // SendReminder is called once for each due event reminder
+    SendReminder(r store.Reminder)
+    // SendDigest is called for each digest subscriber when their digest is due
+    SendDigest(userID string, d Digest)
+    // SourceDown is called once when a source has been failing for longer
+    // than SOURCE_ALERT_AFTER
+    SourceDown(s SourceStatus)
+    // SourceRecovered is called when a source reported by SourceDown
+    // succeeds again, with its status from just before the success
+    SourceRecovered(s SourceStatus)
+}
+
 type Cron struct {
-    c     *cron.Cron
-    store *store.MemoryStore
+    // ctx ends in-flight scrapes, including their retry waits, at shutdown
+    ctx      context.Context
+    c        *cron.Cron
+    store    store.Store
+    notifier Notifier
+    health   *Health
+    scraper  *scrape.Scraper
+
+    // eventsLoaded is set after the first refresh that listed any events.
+    // Until then, a refresh into a store with no events from the source is
+    // the existing schedule loading rather than new postings.
+    eventsLoaded atomic.Bool
 }
 
-func Start(ctx context.Context, cfg config.Config, st *store.MemoryStore) *Cron {
+func Start(ctx context.Context, cfg config.Config, st store.Store, n Notifier) (*Cron, error) {
     loc := util.MustLocation(cfg.TZ)
+
+    scraper, err := NewScraper(cfg, nil)
+    if err != nil {
+        return nil, err
+    }
     
     // Create cron with location and logger
     c := cron.New(
@@ -29,28 +70,108 @@ func Start(ctx context.Context, cfg config.Config, st *store.MemoryStore) *Cron
     )
 
     cronJob := &Cron{
-        c:     c,
-        store: st,
+        ctx:      ctx,
+        c:        c,
+        store:    st,
+        notifier: n,
+        health:   NewHealth(cfg.SourceAlertAfter),
+        scraper:  scraper,
     }
 
-    // Add Mac Gym refresh job with jitter
-    c.AddFunc(cfg.CronMacGym, cronJob.refreshMacGym(cfg))
-
-    // Add events refresh job with jitter
-    c.AddFunc(cfg.CronEvents, cronJob.refreshEvents(cfg, loc))
+    // Refresh jobs, due reminders, and digests; the cron location makes
+    // digest delivery times local. An invalid spec fails startup rather than
+    // leaving its job silently unscheduled.
+    jobs := []struct {
+        env  string
+        spec string
+        run  func()
+    }{
+        {"REFRESH_MACGYM_CRON", cfg.CronMacGym, cronJob.refreshMacGym},
+        {"REFRESH_EVENTS_CRON", cfg.CronEvents, cronJob.refreshEvents(cfg, loc)},
+        {"REMINDERS_CRON", cfg.CronRemind, cronJob.sendReminders},
+        {"DIGEST_DAILY_CRON", cfg.CronDigestDaily, cronJob.sendDigests(store.DigestDaily, loc)},
+        {"DIGEST_WEEKLY_CRON", cfg.CronDigestWeekly, cronJob.sendDigests(store.DigestWeekly, loc)},
+    }
+    for _, job := range jobs {
+        if _, err := c.AddFunc(job.spec, job.run); err != nil {
+            return nil, fmt.Errorf("invalid %s %q: %w", job.env, job.spec, err)
+        }
+    }
 
     // Start with a small delay to avoid thundering herd
     go func() {
         jitter := time.Duration(rand.Intn(30)) * time.Second
-        time.Sleep(jitter)
+        if err := util.SleepContext(ctx, jitter); err != nil {
+            return
+        }
         c.Start()
         slog.Info("Cron scheduler started", 
             "macGymSchedule", cfg.CronMacGym,
             "eventsSchedule", cfg.CronEvents,
+            "remindersSchedule", cfg.CronRemind,
+            "dailyDigestSchedule", cfg.CronDigestDaily,
+            "weeklyDigestSchedule", cfg.CronDigestWeekly,
             "timezone", cfg.TZ)
     }()
 
-    return cronJob
+    return cronJob, nil
+}
+
+// NewScraper builds the scraper for the configured sources, sending
+// requests through d (nil for a default http.Client). It fails if the event
+// classification rules or schedule profile file can't be loaded.
+func NewScraper(cfg config.Config, d util.Doer) (*scrape.Scraper, error) {
+    rules, err := classify.Load(cfg.EventRulesPath)
+    if err != nil {
+        return nil, err
+    }
+    profile, err := scrape.LoadProfile(cfg.FitnessProfilePath)
+    if err != nil {
+        return nil, err
+    }
+
+    return scrape.New(scrape.Options{
+        Doer:             d,
+        UserAgent:        cfg.UserAgent,
+        Location:         util.MustLocation(cfg.TZ),
+        CourtLocationIDs: cfg.MacGymLocationIDs,
+        Rules:            rules,
+        Profile:          profile,
+        Breaker: util.BreakerOptions{
+            Threshold:   cfg.BreakerThreshold,
+            Cooldown:    cfg.BreakerCooldown,
+            MaxCooldown: cfg.BreakerMaxCooldown,
+        },
+        MacGym: scrape.SourceOptions{
+            URL:     cfg.MacGymURL,
+            Timeout: cfg.MacGymTimeout,
+        },
+        Fitness: scrape.SourceOptions{
+            URL:     cfg.FitnessURL,
+            Timeout: cfg.FitnessTimeout,
+        },
+        FitnessQuery: scrape.ScheduleQuery{
+            Weeks:      cfg.FitnessWeeks,
+            PageDays:   cfg.FitnessPageDays,
+            Method:     cfg.FitnessMethod,
+            StartParam: cfg.FitnessStartParam,
+            EndParam:   cfg.FitnessEndParam,
+            DateFormat: cfg.FitnessDateFormat,
+            Params:     cfg.FitnessParams,
+            TokenURL:   cfg.FitnessTokenURL,
+            TokenField: cfg.FitnessTokenField,
+        },
+    }), nil
+}
+
+// Health returns scrape health for every source
+func (cr *Cron) Health() *Health {
+    return cr.health
+}
+
+// Breakers returns the circuit breaker state of every scraped host
+func (cr *Cron) Breakers() []util.BreakerStatus {
+    return cr.scraper.Breakers()
 }
 
 func (cr *Cron) Stop() {
@@ -60,50 +181,182 @@ func (cr *Cron) Stop() {
     slog.Info("Cron scheduler stopped")
 }
 
-func (cr *Cron) refreshMacGym(cfg config.Config) func() {
-    return func() {
-        start := time.Now()
-        
-        ctx, cancel := context.WithTimeout(context.Background(), 30*time.Second)
-        defer cancel()
-        
-        snap, err := scrape.FetchMacGym(ctx, cfg.MacGymURL)
-        if err != nil {
-            slog.Error("Failed to fetch Mac Gym data", 
-                "error", err,
-                "duration", time.Since(start))
-            return
-        }
-        
-        cr.store.SetMac(snap)
-        
-        slog.Info("Mac Gym data refreshed", 
-            "capacity", snap.Capacity,
-            "inUse", snap.InUse,
+func (cr *Cron) refreshMacGym() {
+    start := time.Now()
+    
+    snap, err := cr.scraper.FetchMacGym(cr.ctx)
+    if errors.Is(err, util.ErrNotModified) {
+        // The current snapshot is still accurate; nothing to parse or store
+        cr.recordSuccess(SourceMacGym, start)
+        slog.Debug("Mac Gym data unchanged", "duration", time.Since(start))
+        return
+    }
+    if errors.Is(err, util.ErrCircuitOpen) {
+        slog.Info("Skipping Mac Gym refresh", "reason", err)
+        cr.recordFailure(SourceMacGym, start, nil)
+        return
+    }
+    if err != nil {
+        slog.Error("Failed to fetch Mac Gym data", 
+            "error", err,
             "duration", time.Since(start))
+        cr.recordFailure(SourceMacGym, start, err)
+        return
     }
+    
+    cr.recordSuccess(SourceMacGym, start)
+    cr.store.SetMac(snap)
+    
+    slog.Info("Mac Gym data refreshed", 
+        "capacity", snap.Capacity,
+        "inUse", snap.InUse,
+        "duration", time.Since(start))
 }
 
 func (cr *Cron) refreshEvents(cfg config.Config, loc *time.Location) func() {
     return func() {
         start := time.Now()
         
-        ctx, cancel := context.WithTimeout(context.Background(), 60*time.Second)
-        defer cancel()
-        
-        events, err := scrape.FetchBadmintonEvents(ctx, cfg.FitnessURL, loc)
+        events, err := cr.scraper.FetchBadmintonEvents(cr.ctx, loc)
+        if errors.Is(err, util.ErrNotModified) {
+            // Same listing as last time, so no events were added, changed or cancelled
+            cr.recordSuccess(SourceFitness, start)
+            cr.store.PruneEvents(start, cfg.EventRetention)
+            slog.Debug("Fitness schedule unchanged", "duration", time.Since(start))
+            return
+        }
+        if errors.Is(err, util.ErrCircuitOpen) {
+            slog.Info("Skipping fitness events refresh", "reason", err)
+            cr.recordFailure(SourceFitness, start, nil)
+            return
+        }
         if err != nil {
             slog.Error("Failed to fetch fitness events", 
                 "error", err,
                 "duration", time.Since(start))
+            cr.recordFailure(SourceFitness, start, err)
             return
         }
         
-        cr.store.UpsertEvents(events)
+        cr.recordSuccess(SourceFitness, start)
+        changes := cr.applyEvents(events, start)
+        cr.store.PruneEvents(start, cfg.EventRetention)
         
         slog.Info("Fitness events refreshed", 
             "eventsFound", len(events),
+            "added", len(changes.Added),
+            "changed", len(changes.Changed),
+            "cancelled", len(changes.Cancelled),
             "totalEvents", cr.store.GetEventCount(),
             "duration", time.Since(start))
     }
 }
+
+// recordFailure tracks a failed scrape and alerts admins once the source
+// has been failing for too long. A nil err marks a run skipped because the
+// host's circuit breaker is open, keeping the error that opened it.
+func (cr *Cron) recordFailure(source string, at time.Time, err error) {
+    status, escalate := cr.health.RecordFailure(source, at, err)
+    if !escalate || cr.notifier == nil {
+        return
+    }
+    
+    slog.Warn("Source has been failing, alerting admins",
+        "source", source,
+        "failingSince", status.FailingSince,
+        "failures", status.ConsecutiveFailures)
+    cr.notifier.SourceDown(status)
+}
+
+// recordSuccess tracks a successful scrape and tells admins when a source
+// they were alerted about has recovered
+func (cr *Cron) recordSuccess(source string, at time.Time) {
+    prev, recovered := cr.health.RecordSuccess(source, at)
+    if !recovered || cr.notifier == nil {
+        return
+    }
+    
+    slog.Info("Source recovered", "source", source, "failures", prev.ConsecutiveFailures)
+    cr.notifier.SourceRecovered(prev)
+}
+
+// sendReminders delivers event reminders that have come due. The store marks
+// them sent, so refreshes and restarts never repeat one, and cancelled events
+// are skipped because they're no longer upcoming.
+func (cr *Cron) sendReminders() {
+    due := cr.store.DueReminders(time.Now())
+    if len(due) == 0 || cr.notifier == nil {
+        return
+    }
+    
+    slog.Info("Sending event reminders", "count", len(due))
+    for _, r := range due {
+        cr.notifier.SendReminder(r)
+    }
+}
+
+// applyEvents stores a fitness listing and announces what it added or moved
+func (cr *Cron) applyEvents(events []store.Event, at time.Time) store.ChangeSet {
+    initial := !cr.eventsLoaded.Load() && cr.store.SourceEventCount(SourceFitness) == 0
+    changes := cr.store.ApplyRefresh(store.Refresh{
+        Source: SourceFitness,
+        Events: events,
+        At:     at,
+        Until:  listingEnd(events),
+    })
+    if len(events) > 0 {
+        cr.eventsLoaded.Store(true)
+    }
+
+    cr.announceChanges(changes, at, initial)
+    return changes
+}
+
+// announceChanges passes newly added and moved events that haven't ended to
+// the notifier. An initial load, into a store that held nothing from the
+// source, is suppressed so the whole schedule isn't announced as new; with
+// events kept from before a restart, ones posted meanwhile are announced.
+func (cr *Cron) announceChanges(changes store.ChangeSet, now time.Time, initial bool) {
+    if initial {
+        slog.Info("Suppressing announcements for initial events load", "added", len(changes.Added))
+        return
+    }
+    if cr.notifier == nil {
+        return
+    }
+    
+    var fresh []store.Event
+    for _, e := range changes.Added {
+        if e.End.After(now) {
+            fresh = append(fresh, e)
+        }
+    }
+    var moves []store.Change
+    for _, c := range changes.Changed {
+        if c.Moved() && c.End.After(now) {
+            moves = append(moves, c)
+        }
+    }
+    
+    if len(fresh) > 0 {
+        slog.Info("Announcing new events", "count", len(fresh))
+        cr.notifier.AnnounceEvents(fresh)
+    }
+    if len(moves) > 0 {
+        slog.Info("Announcing moved events", "count", len(moves))
+        cr.notifier.AnnounceMoves(moves)
+    }
+}
+
+// listingEnd returns the latest start time in a listing. Only events up to
+// that point can be judged missing; an empty listing covers nothing so a
+// failed or truncated scrape never cancels everything.
+func listingEnd(events []store.Event) time.Time {
+    var end time.Time
+    for _, e := range events {
+        if e.Start.After(end) {
+            end = e.Start
+        }
+    }
+    return end
+}
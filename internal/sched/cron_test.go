@@ -0,0 +1,193 @@
+package sched
+
+import (
+    "context"
+    "strings"
+    "testing"
+    "time"
+
+    "github.com/sjsu-badminton/badminton-discord-bot/internal/config"
+    "github.com/sjsu-badminton/badminton-discord-bot/internal/store"
+)
+
+type recordingNotifier struct {
+    announced [][]store.Event
+    moves     [][]store.Change
+    reminders []store.Reminder
+    digests   map[string]Digest
+    down      []SourceStatus
+    recovered []SourceStatus
+}
+
+func (n *recordingNotifier) AnnounceEvents(events []store.Event) {
+    n.announced = append(n.announced, events)
+}
+
+func (n *recordingNotifier) AnnounceMoves(moves []store.Change) {
+    n.moves = append(n.moves, moves)
+}
+
+func (n *recordingNotifier) SendReminder(r store.Reminder) {
+    n.reminders = append(n.reminders, r)
+}
+
+func (n *recordingNotifier) SendDigest(userID string, d Digest) {
+    if n.digests == nil {
+        n.digests = make(map[string]Digest)
+    }
+    n.digests[userID] = d
+}
+
+func (n *recordingNotifier) SourceDown(s SourceStatus) {
+    n.down = append(n.down, s)
+}
+
+func (n *recordingNotifier) SourceRecovered(s SourceStatus) {
+    n.recovered = append(n.recovered, s)
+}
+
+func TestAnnounceChangesSkipsInitialLoad(t *testing.T) {
+    now := time.Date(2024, 1, 15, 12, 0, 0, 0, time.UTC)
+    upcoming := store.Event{ID: "a", Start: now.Add(time.Hour), End: now.Add(2 * time.Hour)}
+    ended := store.Event{ID: "b", Start: now.Add(-2 * time.Hour), End: now.Add(-time.Hour)}
+
+    n := &recordingNotifier{}
+    cr := &Cron{notifier: n}
+
+    cr.announceChanges(store.ChangeSet{Added: []store.Event{upcoming}}, now, true)
+    if len(n.announced) != 0 {
+        t.Fatalf("Initial load should not be announced, got %v", n.announced)
+    }
+
+    cr.announceChanges(store.ChangeSet{}, now, false)
+    if len(n.announced) != 0 || len(n.moves) != 0 {
+        t.Fatalf("Empty refresh should not be announced, got %v", n.announced)
+    }
+
+    moved := store.Change{Event: upcoming, Previous: store.Event{ID: "c", Start: now, End: now.Add(time.Hour)}}
+    retagged := store.Change{Event: upcoming, Previous: upcoming}
+    cr.announceChanges(store.ChangeSet{
+        Added:   []store.Event{upcoming, ended},
+        Changed: []store.Change{moved, retagged, {Event: ended, Previous: upcoming}},
+    }, now, false)
+    if len(n.announced) != 1 {
+        t.Fatalf("Expected 1 announcement, got %d", len(n.announced))
+    }
+    if got := n.announced[0]; len(got) != 1 || got[0].ID != "a" {
+        t.Errorf("Expected only the upcoming event to be announced, got %v", got)
+    }
+    if len(n.moves) != 1 || len(n.moves[0]) != 1 || n.moves[0][0].Previous.ID != "c" {
+        t.Errorf("Expected only the upcoming moved event to be announced, got %v", n.moves)
+    }
+}
+
+func TestApplyEventsInitialLoad(t *testing.T) {
+    now := time.Date(2024, 1, 15, 12, 0, 0, 0, time.UTC)
+    event := func(id string, hours int) store.Event {
+        start := now.Add(time.Duration(hours) * time.Hour)
+        return store.Event{ID: id, Title: id, Start: start, End: start.Add(time.Hour)}
+    }
+
+    testCases := []struct {
+        name      string
+        stored    []store.Event // from before a restart
+        listings  [][]store.Event
+        wantAdded []string // announced event IDs, in order
+    }{
+        {
+            name:      "first load is not announced",
+            listings:  [][]store.Event{{event("a", 2)}, {event("a", 2), event("b", 3)}},
+            wantAdded: []string{"b"},
+        },
+        {
+            name:      "empty first refresh doesn't end the initial load",
+            listings:  [][]store.Event{{}, {event("a", 2)}, {event("a", 2), event("b", 3)}},
+            wantAdded: []string{"b"},
+        },
+        {
+            name:      "events posted while down are announced",
+            stored:    []store.Event{event("a", 2)},
+            listings:  [][]store.Event{{event("a", 2), event("b", 3)}},
+            wantAdded: []string{"b"},
+        },
+    }
+
+    for _, tc := range testCases {
+        t.Run(tc.name, func(t *testing.T) {
+            st := store.NewMemoryStore()
+            if len(tc.stored) > 0 {
+                st.ApplyRefresh(store.Refresh{Source: SourceFitness, Events: tc.stored, At: now.Add(-time.Hour)})
+            }
+            n := &recordingNotifier{}
+            cr := &Cron{store: st, notifier: n}
+
+            for i, events := range tc.listings {
+                cr.applyEvents(events, now.Add(time.Duration(i)*time.Minute))
+            }
+
+            var got []string
+            for _, batch := range n.announced {
+                for _, e := range batch {
+                    got = append(got, e.ID)
+                }
+            }
+            if strings.Join(got, ",") != strings.Join(tc.wantAdded, ",") {
+                t.Errorf("Expected announcements %v, got %v", tc.wantAdded, got)
+            }
+        })
+    }
+}
+
+func TestSendReminders(t *testing.T) {
+    now := time.Now()
+    st := store.NewMemoryStore()
+    soon := store.Event{ID: "soon", Title: "Open Play", Start: now.Add(20 * time.Minute), End: now.Add(2 * time.Hour)}
+    later := store.Event{ID: "later", Title: "Club Practice", Start: now.Add(3 * time.Hour), End: now.Add(5 * time.Hour)}
+    st.UpsertEvents([]store.Event{soon, later})
+    st.SetReminderLead("user1", 30*time.Minute)
+
+    n := &recordingNotifier{}
+    cr := &Cron{store: st, notifier: n}
+
+    cr.sendReminders()
+    cr.sendReminders()
+
+    if len(n.reminders) != 1 {
+        t.Fatalf("Expected exactly 1 reminder across two runs, got %d", len(n.reminders))
+    }
+    if r := n.reminders[0]; r.UserID != "user1" || r.Event.ID != "soon" {
+        t.Errorf("Unexpected reminder %+v", r)
+    }
+}
+
+func TestStartRejectsInvalidCron(t *testing.T) {
+    valid := config.Config{
+        TZ:               "America/Los_Angeles",
+        CronMacGym:       "@every 2m",
+        CronEvents:       "@every 30m",
+        CronRemind:       "@every 1m",
+        CronDigestDaily:  "0 8 * * *",
+        CronDigestWeekly: "0 8 * * 1",
+    }
+
+    testCases := []struct {
+        name    string
+        edit    func(*config.Config)
+        wantErr string
+    }{
+        {name: "reminders", edit: func(c *config.Config) { c.CronRemind = "every minute" }, wantErr: "REMINDERS_CRON"},
+        {name: "daily digest", edit: func(c *config.Config) { c.CronDigestDaily = "0 25 * * *" }, wantErr: "DIGEST_DAILY_CRON"},
+        {name: "weekly digest", edit: func(c *config.Config) { c.CronDigestWeekly = "0 8 * *" }, wantErr: "DIGEST_WEEKLY_CRON"},
+    }
+
+    for _, tc := range testCases {
+        t.Run(tc.name, func(t *testing.T) {
+            cfg := valid
+            tc.edit(&cfg)
+            _, err := Start(context.Background(), cfg, store.NewMemoryStore(), &recordingNotifier{})
+            if err == nil || !strings.Contains(err.Error(), tc.wantErr) {
+                t.Errorf("Start() error = %v, want one naming %s", err, tc.wantErr)
+            }
+        })
+    }
+}
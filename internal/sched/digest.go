@@ -0,0 +1,80 @@
+package sched
+
+import (
+    "log/slog"
+    "time"
+
+    "github.com/sjsu-badminton/badminton-discord-bot/internal/occupancy"
+    "github.com/sjsu-badminton/badminton-discord-bot/internal/store"
+    "github.com/sjsu-badminton/badminton-discord-bot/internal/util"
+)
+
+// digestHours is how many of the quietest and busiest hours a digest lists
+const digestHours = 3
+
+// Digest is the scheduled summary sent to digest subscribers: the events
+// coming up in the next period and how busy Mac Gym was over the last one
+type Digest struct {
+    Frequency store.DigestFrequency
+
+    // Upcoming events over the next day (daily) or week (weekly)
+    Events []store.Event
+
+    // Occupancy over the previous calendar day (daily) or seven days (weekly)
+    From      time.Time
+    Until     time.Time
+    Occupancy occupancy.Summary
+    Quietest  []occupancy.Window
+    Busiest   []occupancy.Window
+}
+
+// periodDays is the length of a digest period in days
+func periodDays(f store.DigestFrequency) int {
+    if f == store.DigestWeekly {
+        return 7
+    }
+    return 1
+}
+
+// BuildDigest assembles the digest for frequency f at now. Occupancy periods
+// are whole days in loc, ending at the start of today.
+func BuildDigest(st store.Store, f store.DigestFrequency, now time.Time, loc *time.Location) Digest {
+    days := periodDays(f)
+    until := util.StartOfDay(now.In(loc))
+    from := until.AddDate(0, 0, -days)
+
+    readings := st.History(from, until)
+    windows := occupancy.BuildProfile(readings, loc).Windows(from, until)
+
+    return Digest{
+        Frequency: f,
+        Events:    st.ListUpcoming(now, days),
+        From:      from,
+        Until:     until,
+        Occupancy: occupancy.Summarize(readings),
+        Quietest:  occupancy.Quietest(windows, digestHours),
+        Busiest:   occupancy.Busiest(windows, digestHours),
+    }
+}
+
+// sendDigests builds one digest for frequency f and sends it to each of its
+// subscribers
+func (cr *Cron) sendDigests(f store.DigestFrequency, loc *time.Location) func() {
+    return func() {
+        users := cr.store.DigestSubscribers(f)
+        if len(users) == 0 || cr.notifier == nil {
+            return
+        }
+
+        d := BuildDigest(cr.store, f, time.Now(), loc)
+        slog.Info("Sending digests",
+            "frequency", f,
+            "recipients", len(users),
+            "events", len(d.Events),
+            "readings", d.Occupancy.Samples)
+
+        for _, userID := range users {
+            cr.notifier.SendDigest(userID, d)
+        }
+    }
+}
@@ -0,0 +1,76 @@
+package sched
+
+import (
+    "testing"
+    "time"
+
+    "github.com/sjsu-badminton/badminton-discord-bot/internal/store"
+)
+
+func TestBuildDigest(t *testing.T) {
+    loc := time.UTC
+    now := time.Date(2024, 1, 17, 8, 0, 0, 0, loc) // Wednesday
+    st := store.NewMemoryStore()
+
+    // Hourly readings on Monday and Tuesday; Tuesday evening is busiest
+    for day := 15; day <= 16; day++ {
+        for hour := 8; hour < 22; hour++ {
+            inUse := 2
+            if day == 16 && hour >= 18 {
+                inUse = 7
+            }
+            st.SetMac(store.MacGymSnapshot{
+                RetrievedAt: time.Date(2024, 1, day, hour, 0, 0, 0, loc),
+                Capacity:    8,
+                InUse:       inUse,
+            })
+        }
+    }
+
+    tomorrow := store.Event{ID: "a", Title: "Open Play", Start: now.Add(10 * time.Hour), End: now.Add(12 * time.Hour)}
+    nextWeek := store.Event{ID: "b", Title: "Tournament", Start: now.Add(5 * 24 * time.Hour), End: now.Add(5*24*time.Hour + 2*time.Hour)}
+    st.UpsertEvents([]store.Event{tomorrow, nextWeek})
+
+    daily := BuildDigest(st, store.DigestDaily, now, loc)
+    if !daily.From.Equal(time.Date(2024, 1, 16, 0, 0, 0, 0, loc)) || !daily.Until.Equal(time.Date(2024, 1, 17, 0, 0, 0, 0, loc)) {
+        t.Errorf("Expected daily digest to cover yesterday, got %v to %v", daily.From, daily.Until)
+    }
+    if daily.Occupancy.Samples != 14 || daily.Occupancy.Max != 7 {
+        t.Errorf("Expected 14 readings from yesterday with max 7, got %+v", daily.Occupancy)
+    }
+    if len(daily.Events) != 1 || daily.Events[0].ID != "a" {
+        t.Errorf("Expected only the next day's event, got %+v", daily.Events)
+    }
+    if len(daily.Busiest) != digestHours || daily.Busiest[0].Start.Hour() < 18 {
+        t.Errorf("Expected the evening to be busiest, got %+v", daily.Busiest)
+    }
+
+    weekly := BuildDigest(st, store.DigestWeekly, now, loc)
+    if weekly.Occupancy.Samples != 28 {
+        t.Errorf("Expected weekly digest to include both days, got %d readings", weekly.Occupancy.Samples)
+    }
+    if len(weekly.Events) != 2 {
+        t.Errorf("Expected a week of events, got %+v", weekly.Events)
+    }
+}
+
+func TestSendDigestsOnlyToFrequencySubscribers(t *testing.T) {
+    st := store.NewMemoryStore()
+    st.SetDigest("daily1", store.DigestDaily)
+    st.SetDigest("daily2", store.DigestDaily)
+    st.SetDigest("weekly1", store.DigestWeekly)
+
+    n := &recordingNotifier{}
+    cr := &Cron{store: st, notifier: n}
+
+    cr.sendDigests(store.DigestDaily, time.UTC)()
+
+    if len(n.digests) != 2 {
+        t.Fatalf("Expected 2 daily digests, got %v", n.digests)
+    }
+    for _, userID := range []string{"daily1", "daily2"} {
+        if d, ok := n.digests[userID]; !ok || d.Frequency != store.DigestDaily {
+            t.Errorf("Expected a daily digest for %s, got %+v", userID, d)
+        }
+    }
+}
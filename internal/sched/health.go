@@ -0,0 +1,130 @@
+package sched
+
+import (
+    "sort"
+    "sync"
+    "time"
+)
+
+// SourceMacGym identifies Mac Gym occupancy scrapes
+const SourceMacGym = "macgym"
+
+// SourceStatus is the scrape health of one source
+type SourceStatus struct {
+    Source              string
+    LastSuccess         time.Time
+    LastFailure         time.Time
+    LastError           string
+    ConsecutiveFailures int
+    // FailingSince is when the current run of failures started, or zero
+    // while the source is healthy
+    FailingSince time.Time
+}
+
+// Failing reports whether the most recent scrape failed
+func (s SourceStatus) Failing() bool {
+    return s.ConsecutiveFailures > 0
+}
+
+// Stale reports whether the last successful scrape is older than maxAge at
+// now. A source that has never succeeded is stale once it has failed.
+func (s SourceStatus) Stale(now time.Time, maxAge time.Duration) bool {
+    if s.LastSuccess.IsZero() {
+        return s.Failing()
+    }
+    return now.Sub(s.LastSuccess) > maxAge
+}
+
+// Health tracks consecutive failures and last success per source, and
+// decides when a failing source should be escalated to admins
+type Health struct {
+    mu         sync.Mutex
+    alertAfter time.Duration
+    sources    map[string]*SourceStatus
+    alerted    map[string]bool // sources whose current outage admins were told about
+}
+
+// NewHealth returns a tracker that escalates sources failing for longer than
+// alertAfter
+func NewHealth(alertAfter time.Duration) *Health {
+    return &Health{
+        alertAfter: alertAfter,
+        sources:    make(map[string]*SourceStatus),
+        alerted:    make(map[string]bool),
+    }
+}
+
+func (h *Health) status(source string) *SourceStatus {
+    s, ok := h.sources[source]
+    if !ok {
+        s = &SourceStatus{Source: source}
+        h.sources[source] = s
+    }
+    return s
+}
+
+// RecordSuccess marks a successful scrape. recovered is true when admins
+// had been alerted about the outage that just ended; prev is the status
+// before the success so the outage can be described.
+func (h *Health) RecordSuccess(source string, at time.Time) (prev SourceStatus, recovered bool) {
+    h.mu.Lock()
+    defer h.mu.Unlock()
+
+    s := h.status(source)
+    prev = *s
+    recovered = h.alerted[source]
+
+    s.LastSuccess = at
+    s.ConsecutiveFailures = 0
+    s.FailingSince = time.Time{}
+    delete(h.alerted, source)
+    return prev, recovered
+}
+
+// RecordFailure marks a failed scrape. escalate is true exactly once per
+// outage, when the source has been failing for longer than alertAfter.
+func (h *Health) RecordFailure(source string, at time.Time, err error) (cur SourceStatus, escalate bool) {
+    h.mu.Lock()
+    defer h.mu.Unlock()
+
+    s := h.status(source)
+    if s.ConsecutiveFailures == 0 {
+        s.FailingSince = at
+    }
+    s.ConsecutiveFailures++
+    s.LastFailure = at
+    if err != nil {
+        s.LastError = err.Error()
+    }
+
+    if !h.alerted[source] && at.Sub(s.FailingSince) >= h.alertAfter {
+        h.alerted[source] = true
+        escalate = true
+    }
+    return *s, escalate
+}
+
+// Status returns the health of source; ok is false if it was never scraped
+func (h *Health) Status(source string) (SourceStatus, bool) {
+    h.mu.Lock()
+    defer h.mu.Unlock()
+
+    s, ok := h.sources[source]
+    if !ok {
+        return SourceStatus{Source: source}, false
+    }
+    return *s, true
+}
+
+// Statuses returns every tracked source, sorted by name
+func (h *Health) Statuses() []SourceStatus {
+    h.mu.Lock()
+    defer h.mu.Unlock()
+
+    out := make([]SourceStatus, 0, len(h.sources))
+    for _, s := range h.sources {
+        out = append(out, *s)
+    }
+    sort.Slice(out, func(i, j int) bool { return out[i].Source < out[j].Source })
+    return out
+}
@@ -0,0 +1,79 @@
+package sched
+
+import (
+    "errors"
+    "testing"
+    "time"
+)
+
+func TestHealthEscalatesOncePerOutage(t *testing.T) {
+    start := time.Date(2024, 1, 15, 12, 0, 0, 0, time.UTC)
+    n := &recordingNotifier{}
+    cr := &Cron{notifier: n, health: NewHealth(30 * time.Minute)}
+
+    cr.recordSuccess(SourceMacGym, start)
+    if len(n.recovered) != 0 {
+        t.Fatal("A success without an outage should not be reported as a recovery")
+    }
+
+    // Failures every 10 minutes escalate once the outage reaches 30 minutes
+    for i := 1; i <= 6; i++ {
+        cr.recordFailure(SourceMacGym, start.Add(time.Duration(i)*10*time.Minute), errors.New("timeout"))
+        wantDown := 0
+        if i >= 4 {
+            wantDown = 1
+        }
+        if len(n.down) != wantDown {
+            t.Fatalf("After %d failures expected %d admin alerts, got %d", i, wantDown, len(n.down))
+        }
+    }
+
+    down := n.down[0]
+    if down.Source != SourceMacGym || down.ConsecutiveFailures != 4 || down.LastError != "timeout" {
+        t.Errorf("Unexpected escalated status: %+v", down)
+    }
+    if !down.FailingSince.Equal(start.Add(10 * time.Minute)) {
+        t.Errorf("Expected outage to start at the first failure, got %v", down.FailingSince)
+    }
+
+    recoveredAt := start.Add(70 * time.Minute)
+    cr.recordSuccess(SourceMacGym, recoveredAt)
+    if len(n.recovered) != 1 || n.recovered[0].ConsecutiveFailures != 6 {
+        t.Fatalf("Expected one recovery after 6 failures, got %+v", n.recovered)
+    }
+
+    status, ok := cr.health.Status(SourceMacGym)
+    if !ok || status.Failing() || !status.LastSuccess.Equal(recoveredAt) || !status.FailingSince.IsZero() {
+        t.Errorf("Expected a healthy status after recovery, got %+v", status)
+    }
+
+    // A new outage escalates again
+    cr.recordFailure(SourceMacGym, recoveredAt.Add(time.Minute), errors.New("boom"))
+    cr.recordFailure(SourceMacGym, recoveredAt.Add(40*time.Minute), errors.New("boom"))
+    if len(n.down) != 2 {
+        t.Errorf("Expected a second admin alert for a new outage, got %d", len(n.down))
+    }
+}
+
+func TestSourceStatusStale(t *testing.T) {
+    now := time.Date(2024, 1, 15, 12, 0, 0, 0, time.UTC)
+
+    testCases := []struct {
+        name   string
+        status SourceStatus
+        want   bool
+    }{
+        {name: "never scraped", status: SourceStatus{}, want: false},
+        {name: "never succeeded", status: SourceStatus{ConsecutiveFailures: 1}, want: true},
+        {name: "recent success", status: SourceStatus{LastSuccess: now.Add(-5 * time.Minute)}, want: false},
+        {name: "old success", status: SourceStatus{LastSuccess: now.Add(-15 * time.Minute)}, want: true},
+    }
+
+    for _, tc := range testCases {
+        t.Run(tc.name, func(t *testing.T) {
+            if got := tc.status.Stale(now, 10*time.Minute); got != tc.want {
+                t.Errorf("Stale() = %v, want %v", got, tc.want)
+            }
+        })
+    }
+}
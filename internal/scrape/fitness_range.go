@@ -0,0 +1,252 @@
+package scrape
+
+import (
+    "bytes"
+    "context"
+    "crypto/sha256"
+    "fmt"
+    "io"
+    "log/slog"
+    "net/http"
+    "net/url"
+    "strings"
+    "time"
+
+    "github.com/PuerkitoBio/goquery"
+
+    "github.com/sjsu-badminton/badminton-discord-bot/internal/store"
+    "github.com/sjsu-badminton/badminton-discord-bot/internal/util"
+)
+
+const (
+    defaultPageDays   = 7
+    defaultStartParam = "start"
+    defaultEndParam   = "end"
+    defaultDateFormat = "2006-01-02"
+    defaultTokenField = "__RequestVerificationToken"
+
+    // tokenHeader carries the anti-forgery token on requests made by script
+    tokenHeader = "RequestVerificationToken"
+)
+
+// ScheduleQuery asks the fitness schedule for a date range, one page at a
+// time. A zero Weeks fetches the source URL as is.
+type ScheduleQuery struct {
+    // Weeks is how far ahead of today to fetch
+    Weeks int
+    // PageDays is the number of days each request covers; defaults to 7
+    PageDays int
+    // Method is GET, sending the range in the query string, or POST,
+    // sending it as a form; defaults to GET
+    Method string
+    // StartParam and EndParam name the range parameters; the end is the day
+    // after the last day of the page. Default to "start" and "end".
+    StartParam string
+    EndParam   string
+    // DateFormat formats the range, as a Go time layout; defaults to
+    // "2006-01-02"
+    DateFormat string
+    // Params are sent with every page, e.g. the facility ID
+    Params url.Values
+    // TokenURL, if set, is fetched before the pages for its cookies and
+    // anti-forgery token
+    TokenURL string
+    // TokenField names the token's hidden input and the form field it is
+    // sent back in; defaults to "__RequestVerificationToken"
+    TokenField string
+}
+
+func (q ScheduleQuery) withDefaults() ScheduleQuery {
+    if q.PageDays <= 0 {
+        q.PageDays = defaultPageDays
+    }
+    if q.Method == "" {
+        q.Method = http.MethodGet
+    }
+    q.Method = strings.ToUpper(q.Method)
+    if q.StartParam == "" {
+        q.StartParam = defaultStartParam
+    }
+    if q.EndParam == "" {
+        q.EndParam = defaultEndParam
+    }
+    if q.DateFormat == "" {
+        q.DateFormat = defaultDateFormat
+    }
+    if q.TokenField == "" {
+        q.TokenField = defaultTokenField
+    }
+    return q
+}
+
+// schedulePage is one date range of the schedule
+type schedulePage struct {
+    from, to time.Time // to is exclusive
+}
+
+// pages splits the query's weeks from today into pages
+func (q ScheduleQuery) pages(now time.Time) []schedulePage {
+    start := util.StartOfDay(now)
+    end := start.AddDate(0, 0, 7*q.Weeks)
+
+    var pages []schedulePage
+    for from := start; from.Before(end); from = from.AddDate(0, 0, q.PageDays) {
+        to := from.AddDate(0, 0, q.PageDays)
+        if to.After(end) {
+            to = end
+        }
+        pages = append(pages, schedulePage{from: from, to: to})
+    }
+    return pages
+}
+
+// fetchScheduleRange fetches every page of the schedule from now on and
+// merges their events. Each request gets the source's timeout, so a long
+// range isn't cut short. It returns util.ErrNotModified when every page is
+// the same as on the previous fetch: GET pages are conditional requests
+// cached by page URL, while POST pages are compared by a hash of their
+// bodies. Pages that moved out of the range are dropped from the cache.
+func (sc *Scraper) fetchScheduleRange(ctx context.Context, loc *time.Location, now time.Time) ([]store.Event, error) {
+    q := sc.query
+    pages := q.pages(now.In(loc))
+    slog.Info("Fetching fitness schedule", "url", sc.fitnessOpts.URL, "pages", len(pages), "method", q.Method)
+
+    token, err := sc.scheduleToken(ctx)
+    if err != nil {
+        return nil, err
+    }
+
+    hash := sha256.New()
+    modified := false
+    requested := make(map[string]bool, len(pages))
+    seen := make(map[string]bool)
+    var events []store.Event
+    for _, page := range pages {
+        body, contentType, pageModified, err := sc.fetchSchedulePage(ctx, page, token, requested)
+        if err != nil {
+            return nil, fmt.Errorf("fetching fitness schedule %s to %s: %w",
+                page.from.Format(defaultDateFormat), page.to.Format(defaultDateFormat), err)
+        }
+        modified = modified || pageModified
+        hash.Write(body)
+
+        // The source URL, not the page's, so events keep their SourceURL as
+        // the range moves
+        pageEvents, err := sc.parseSchedule(bytes.NewReader(body), contentType, loc, sc.fitnessOpts.URL)
+        if err != nil {
+            return nil, fmt.Errorf("parsing fitness schedule %s to %s: %w",
+                page.from.Format(defaultDateFormat), page.to.Format(defaultDateFormat), err)
+        }
+
+        // Pages may overlap, or the server may ignore the range entirely
+        for _, e := range pageEvents {
+            if !seen[e.ID] {
+                seen[e.ID] = true
+                events = append(events, e)
+            }
+        }
+    }
+
+    // Page URLs carry their dates, so without this every day would leave
+    // the previous day's pages cached for good
+    sc.fitness.Retain(requested)
+
+    if q.Method == http.MethodPost {
+        var sum [sha256.Size]byte
+        hash.Sum(sum[:0])
+
+        sc.mu.Lock()
+        modified = sum != sc.scheduleHash
+        sc.scheduleHash = sum
+        sc.mu.Unlock()
+    }
+
+    if !modified {
+        slog.Debug("Fitness schedule not modified")
+        return nil, util.ErrNotModified
+    }
+    return events, nil
+}
+
+// fetchSchedulePage requests one page and returns its body and content type.
+// modified is false when a GET page is unchanged since it was last fetched,
+// in which case the body is the cached copy. GET page URLs are added to
+// requested.
+func (sc *Scraper) fetchSchedulePage(ctx context.Context, page schedulePage, token string, requested map[string]bool) (body []byte, contentType string, modified bool, err error) {
+    ctx, cancel := withTimeout(ctx, sc.fitnessOpts)
+    defer cancel()
+
+    q := sc.query
+    params := url.Values{}
+    for k, v := range q.Params {
+        params[k] = v
+    }
+    params.Set(q.StartParam, page.from.Format(q.DateFormat))
+    params.Set(q.EndParam, page.to.Format(q.DateFormat))
+
+    header := http.Header{}
+    if token != "" {
+        header.Set(tokenHeader, token)
+    }
+
+    var resp *http.Response
+    if q.Method == http.MethodPost {
+        if token != "" {
+            params.Set(q.TokenField, token)
+        }
+        resp, err = sc.fitness.Send(ctx, q.Method, sc.fitnessOpts.URL, params, header)
+    } else {
+        u, perr := url.Parse(sc.fitnessOpts.URL)
+        if perr != nil {
+            return nil, "", false, fmt.Errorf("parsing fitness URL: %w", perr)
+        }
+        query := u.Query()
+        for k, v := range params {
+            query[k] = v
+        }
+        u.RawQuery = query.Encode()
+        requested[u.String()] = true
+        resp, err = sc.fitness.GetWithHeader(ctx, u.String(), header)
+    }
+    if err != nil {
+        return nil, "", false, err
+    }
+    defer resp.Body.Close()
+
+    if body, err = io.ReadAll(resp.Body); err != nil {
+        return nil, "", false, fmt.Errorf("reading response: %w", err)
+    }
+    return body, resp.Header.Get("Content-Type"), resp.StatusCode != http.StatusNotModified, nil
+}
+
+// scheduleToken loads the token page, keeping its cookies, and returns the
+// anti-forgery token found on it. Without a token page it returns "".
+func (sc *Scraper) scheduleToken(ctx context.Context) (string, error) {
+    q := sc.query
+    if q.TokenURL == "" {
+        return "", nil
+    }
+
+    ctx, cancel := withTimeout(ctx, sc.fitnessOpts)
+    defer cancel()
+
+    resp, err := sc.fitness.Send(ctx, http.MethodGet, q.TokenURL, nil, nil)
+    if err != nil {
+        return "", fmt.Errorf("fetching anti-forgery token: %w", err)
+    }
+    defer resp.Body.Close()
+
+    doc, err := goquery.NewDocumentFromReader(resp.Body)
+    if err != nil {
+        return "", fmt.Errorf("parsing anti-forgery token page: %w", err)
+    }
+
+    field := q.TokenField
+    if v, ok := doc.Find(fmt.Sprintf("input[name=%q]", field)).First().Attr("value"); ok && v != "" {
+        return v, nil
+    }
+    if v, ok := doc.Find(fmt.Sprintf("meta[name=%q]", field)).First().Attr("content"); ok && v != "" {
+        return v, nil
+    }
+    return "", fmt.Errorf("no %s found on %s", field, q.TokenURL)
+}
@@ -0,0 +1,240 @@
+package scrape
+
+import (
+    "context"
+    "encoding/json"
+    "errors"
+    "fmt"
+    "net/http"
+    "net/http/httptest"
+    "net/url"
+    "sort"
+    "strings"
+    "testing"
+    "time"
+
+    "github.com/sjsu-badminton/badminton-discord-bot/internal/util"
+)
+
+func TestScheduleQueryPages(t *testing.T) {
+    loc := mustLoadLA(t)
+    now := time.Date(2024, 1, 15, 18, 30, 0, 0, loc)
+
+    testCases := []struct {
+        name  string
+        query ScheduleQuery
+        want  []string
+    }{
+        {
+            name:  "weekly pages",
+            query: ScheduleQuery{Weeks: 2},
+            want:  []string{"2024-01-15/2024-01-22", "2024-01-22/2024-01-29"},
+        },
+        {
+            name:  "last page cut short",
+            query: ScheduleQuery{Weeks: 1, PageDays: 3},
+            want:  []string{"2024-01-15/2024-01-18", "2024-01-18/2024-01-21", "2024-01-21/2024-01-22"},
+        },
+    }
+
+    for _, tc := range testCases {
+        t.Run(tc.name, func(t *testing.T) {
+            var got []string
+            for _, p := range tc.query.withDefaults().pages(now) {
+                got = append(got, p.from.Format("2006-01-02")+"/"+p.to.Format("2006-01-02"))
+            }
+            if strings.Join(got, " ") != strings.Join(tc.want, " ") {
+                t.Errorf("Expected pages %q, got %q", tc.want, got)
+            }
+        })
+    }
+}
+
+// scheduleServer serves one badminton session on the first day of each
+// requested range, plus one event on every page, like a server that
+// ignores part of the query. GET pages carry an ETag of their query and
+// delay, if set, slows every page.
+func scheduleServer(t *testing.T, method string, token string, delay time.Duration) (*httptest.Server, *[]url.Values) {
+    t.Helper()
+    var requests []url.Values
+
+    srv := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+        if r.URL.Path == "/Facility" {
+            http.SetCookie(w, &http.Cookie{Name: ".AspNetCore.Antiforgery", Value: "cookie-" + token, Path: "/"})
+            fmt.Fprintf(w, `<form><input name="__RequestVerificationToken" type="hidden" value="%s"></form>`, token)
+            return
+        }
+
+        if r.Method != method {
+            http.Error(w, "wrong method", http.StatusMethodNotAllowed)
+            return
+        }
+        if token != "" {
+            cookie, err := r.Cookie(".AspNetCore.Antiforgery")
+            r.ParseForm()
+            if err != nil || cookie.Value != "cookie-"+token || r.PostForm.Get("__RequestVerificationToken") != token ||
+                r.Header.Get("RequestVerificationToken") != token {
+                http.Error(w, "bad token", http.StatusBadRequest)
+                return
+            }
+        }
+
+        r.ParseForm()
+        requests = append(requests, r.Form)
+        time.Sleep(delay)
+
+        if method == http.MethodGet {
+            etag := `"` + r.URL.RawQuery + `"`
+            if r.Header.Get("If-None-Match") == etag {
+                w.WriteHeader(http.StatusNotModified)
+                return
+            }
+            w.Header().Set("ETag", etag)
+        }
+
+        start, err := time.Parse("2006-01-02", r.Form.Get("start"))
+        if err != nil {
+            http.Error(w, "bad start", http.StatusBadRequest)
+            return
+        }
+        w.Header().Set("Content-Type", "application/json")
+        json.NewEncoder(w).Encode([]FitnessEvent{
+            {Title: "Badminton Open Play", Location: "SRAC Gym", Date: start.Format("2006-01-02"), StartTime: "18:00", EndTime: "20:00"},
+            {Title: "Badminton Club Tournament", Location: "SRAC Gym", Date: "2024-01-20", StartTime: "10:00", EndTime: "14:00"},
+            {Title: "Basketball Open Gym", Location: "SRAC Gym", Date: start.Format("2006-01-02"), StartTime: "20:00", EndTime: "22:00"},
+        })
+    }))
+    t.Cleanup(srv.Close)
+    return srv, &requests
+}
+
+func TestFetchScheduleRange(t *testing.T) {
+    loc := mustLoadLA(t)
+    now := time.Date(2024, 1, 15, 9, 0, 0, 0, loc)
+
+    testCases := []struct {
+        name   string
+        method string
+        token  string
+    }{
+        {name: "GET query", method: http.MethodGet},
+        {name: "POST form with anti-forgery token", method: http.MethodPost, token: "tok123"},
+    }
+
+    for _, tc := range testCases {
+        t.Run(tc.name, func(t *testing.T) {
+            srv, requests := scheduleServer(t, tc.method, tc.token, 0)
+
+            query := ScheduleQuery{
+                Weeks:  3,
+                Method: tc.method,
+                Params: url.Values{"facilityId": {"12"}},
+            }
+            if tc.token != "" {
+                query.TokenURL = srv.URL + "/Facility"
+            }
+            sc := New(Options{
+                Doer:         srv.Client(),
+                Fitness:      SourceOptions{URL: srv.URL + "/Facility/GetSchedule"},
+                FitnessQuery: query,
+            })
+
+            events, err := sc.fetchScheduleRange(context.Background(), loc, now)
+            if err != nil {
+                t.Fatalf("Unexpected error: %v", err)
+            }
+
+            if len(*requests) != 3 {
+                t.Fatalf("Expected 3 page requests, got %d", len(*requests))
+            }
+            for i, r := range *requests {
+                from := time.Date(2024, 1, 15+7*i, 0, 0, 0, 0, loc)
+                if r.Get("start") != from.Format("2006-01-02") || r.Get("end") != from.AddDate(0, 0, 7).Format("2006-01-02") ||
+                    r.Get("facilityId") != "12" {
+                    t.Errorf("Page %d sent %v", i, r)
+                }
+            }
+
+            var got []string
+            for _, e := range events {
+                got = append(got, e.Start.Format("2006-01-02 ")+e.Title)
+                if e.SourceURL != srv.URL+"/Facility/GetSchedule" {
+                    t.Errorf("Expected SourceURL without the page range, got %q", e.SourceURL)
+                }
+            }
+            sort.Strings(got)
+            want := []string{
+                "2024-01-15 Badminton Open Play",
+                "2024-01-20 Badminton Club Tournament",
+                "2024-01-22 Badminton Open Play",
+                "2024-01-29 Badminton Open Play",
+            }
+            if strings.Join(got, "|") != strings.Join(want, "|") {
+                t.Errorf("Expected events %q, got %q", want, got)
+            }
+
+            if _, err := sc.fetchScheduleRange(context.Background(), loc, now); !errors.Is(err, util.ErrNotModified) {
+                t.Errorf("Second fetch of the same pages = %v, want ErrNotModified", err)
+            }
+            if events, err := sc.fetchScheduleRange(context.Background(), loc, now.AddDate(0, 0, 1)); err != nil || len(events) == 0 {
+                t.Errorf("Fetch of a later range = %d events, %v; want new events", len(events), err)
+            }
+        })
+    }
+}
+
+func TestFetchScheduleRangeMissingToken(t *testing.T) {
+    srv, _ := scheduleServer(t, http.MethodPost, "", 0)
+
+    sc := New(Options{
+        Doer:         srv.Client(),
+        Fitness:      SourceOptions{URL: srv.URL + "/Facility/GetSchedule"},
+        FitnessQuery: ScheduleQuery{Weeks: 1, Method: http.MethodPost, TokenURL: srv.URL + "/Facility"},
+    })
+
+    _, err := sc.FetchBadmintonEvents(context.Background(), mustLoadLA(t))
+    if err == nil || !strings.Contains(err.Error(), "no __RequestVerificationToken found") {
+        t.Errorf("Expected a missing token error, got %v", err)
+    }
+}
+
+func TestFetchScheduleRangeTimesOutPerPage(t *testing.T) {
+    // Each page fits in the timeout but the whole range doesn't
+    srv, requests := scheduleServer(t, http.MethodGet, "", 80*time.Millisecond)
+
+    sc := New(Options{
+        Doer:         srv.Client(),
+        Fitness:      SourceOptions{URL: srv.URL + "/Facility/GetSchedule", Timeout: 200 * time.Millisecond},
+        FitnessQuery: ScheduleQuery{Weeks: 4},
+    })
+
+    events, err := sc.FetchBadmintonEvents(context.Background(), mustLoadLA(t))
+    if err != nil {
+        t.Fatalf("Unexpected error: %v", err)
+    }
+    if len(*requests) != 4 || len(events) == 0 {
+        t.Errorf("Expected 4 pages with events, got %d pages and %d events", len(*requests), len(events))
+    }
+}
+
+func TestFetchScheduleRangeEvictsPastPages(t *testing.T) {
+    loc := mustLoadLA(t)
+    now := time.Date(2024, 1, 15, 9, 0, 0, 0, loc)
+    srv, _ := scheduleServer(t, http.MethodGet, "", 0)
+
+    sc := New(Options{
+        Doer:         srv.Client(),
+        Fitness:      SourceOptions{URL: srv.URL + "/Facility/GetSchedule"},
+        FitnessQuery: ScheduleQuery{Weeks: 2, PageDays: 3},
+    })
+
+    // 14 days in pages of 3 days is 5 pages, whatever the day
+    for day := 0; day < 10; day++ {
+        if _, err := sc.fetchScheduleRange(context.Background(), loc, now.AddDate(0, 0, day)); err != nil {
+            t.Fatalf("Day %d: unexpected error: %v", day, err)
+        }
+        if n := sc.fitness.CacheLen(); n != 5 {
+            t.Fatalf("Day %d: expected 5 cached pages, got %d", day, n)
+        }
+    }
+}
@@ -5,17 +5,22 @@ import (
     "fmt"
     "io"
     "log/slog"
-    "regexp"
-    "strconv"
+    "net/http"
+    "net/url"
     "strings"
     "time"
 
     "github.com/PuerkitoBio/goquery"
 
+    "github.com/sjsu-badminton/badminton-discord-bot/internal/classify"
     "github.com/sjsu-badminton/badminton-discord-bot/internal/store"
     "github.com/sjsu-badminton/badminton-discord-bot/internal/util"
 )
 
+// fitnessScheduleURL is the SourceURL of fitness schedule events when the
+// page they came from is unknown
+const fitnessScheduleURL = "https://fitness.sjsu.edu/Facility/GetSchedule"
+
 // FitnessEvent represents a fitness schedule event
 type FitnessEvent struct {
     Title     string `json:"title"`
@@ -26,58 +31,123 @@ type FitnessEvent struct {
     Type      string `json:"type"`
 }
 
-// FetchBadmintonEvents fetches and parses badminton events from the fitness schedule
-func FetchBadmintonEvents(ctx context.Context, url string, loc *time.Location) ([]store.Event, error) {
-    slog.Info("Fetching fitness schedule", "url", url)
+// FetchBadmintonEvents fetches and parses badminton events from the fitness
+// schedule: FitnessQuery's weeks in pages when it sets Weeks, or else
+// whatever the source URL shows by default
+func (sc *Scraper) FetchBadmintonEvents(ctx context.Context, loc *time.Location) ([]store.Event, error) {
+    if sc.query.Weeks > 0 {
+        // Times out per page rather than for the whole range
+        return sc.fetchScheduleRange(ctx, loc, time.Now())
+    }
+
+    ctx, cancel := withTimeout(ctx, sc.fitnessOpts)
+    defer cancel()
+
+    pageURL := sc.fitnessOpts.URL
+    slog.Info("Fetching fitness schedule", "url", pageURL)
     
-    resp, err := util.Get(ctx, url)
+    resp, err := sc.fitness.Get(ctx, pageURL)
     if err != nil {
         return nil, fmt.Errorf("fetching fitness schedule: %w", err)
     }
     defer resp.Body.Close()
 
-    ct := resp.Header.Get("Content-Type")
-    
+    if resp.StatusCode == http.StatusNotModified {
+        slog.Debug("Fitness schedule not modified")
+        return nil, util.ErrNotModified
+    }
+
+    return sc.parseSchedule(resp.Body, resp.Header.Get("Content-Type"), loc, pageURL)
+}
+
+// parseSchedule parses a schedule page as JSON or HTML by its content type
+func (sc *Scraper) parseSchedule(body io.Reader, ct string, loc *time.Location, pageURL string) ([]store.Event, error) {
     if strings.Contains(ct, "application/json") {
-        return parseJSONSchedule(resp.Body, loc)
+        return parseJSONSchedule(body, loc, sc.rules, pageURL)
     }
     
     if strings.Contains(ct, "text/html") || ct == "" {
-        return parseHTMLSchedule(resp.Body, loc)
+        return ParseHTMLSchedule(body, HTMLOptions{
+            Profile:  sc.profile,
+            Rules:    sc.rules,
+            Location: loc,
+            PageURL:  pageURL,
+        })
     }
 
     return nil, fmt.Errorf("unexpected content type: %s", ct)
 }
 
 // parseJSONSchedule parses JSON format fitness schedule
-func parseJSONSchedule(body io.Reader, loc *time.Location) ([]store.Event, error) {
+func parseJSONSchedule(body io.Reader, loc *time.Location, rules *classify.Rules, sourceURL string) ([]store.Event, error) {
     var events []FitnessEvent
     if err := util.DecodeJSON(body, &events); err != nil {
         return nil, fmt.Errorf("decoding JSON schedule: %w", err)
     }
     
-    return convertToStoreEvents(events, loc)
+    return convertToStoreEvents(events, loc, rules, sourceURL)
+}
+
+// HTMLOptions configures ParseHTMLSchedule
+type HTMLOptions struct {
+    // Profile defaults to DefaultProfile
+    Profile *Profile
+    // Rules defaults to classify.Default
+    Rules *classify.Rules
+    // Location defaults to time.Local
+    Location *time.Location
+    // Now resolves dates shown without a year; zero uses the current time
+    Now time.Time
+    // PageURL is where the page came from. It is each event's SourceURL and
+    // the base for relative registration links.
+    PageURL string
 }
 
-// parseHTMLSchedule parses HTML format fitness schedule
-func parseHTMLSchedule(body io.Reader, loc *time.Location) ([]store.Event, error) {
+// ParseHTMLSchedule extracts the badminton events from a schedule page
+func ParseHTMLSchedule(body io.Reader, opts HTMLOptions) ([]store.Event, error) {
+    if opts.Profile == nil {
+        opts.Profile = DefaultProfile()
+    }
+    if opts.Rules == nil {
+        opts.Rules = classify.Default()
+    }
+    if opts.Location == nil {
+        opts.Location = time.Local
+    }
+    if opts.Now.IsZero() {
+        opts.Now = time.Now()
+    }
+    if opts.PageURL == "" {
+        opts.PageURL = fitnessScheduleURL
+    }
+    profile, rules, loc := opts.Profile, opts.Rules, opts.Location
+
     doc, err := goquery.NewDocumentFromReader(body)
     if err != nil {
         return nil, fmt.Errorf("parsing HTML: %w", err)
     }
     
+    p := newScheduleDoc(doc, loc, opts.Now.In(loc), profile)
     var events []store.Event
     
-    // Look for event containers - common patterns in fitness schedules
-    selectors := []string{
-        ".event", ".schedule-item", ".activity", ".class",
-        "tr", ".card", ".event-card", "[data-event]",
-    }
-    
-    for _, selector := range selectors {
+    for _, selector := range profile.Rows {
         doc.Find(selector).Each(func(i int, s *goquery.Selection) {
-            event := parseEventFromElement(s, loc)
-            if event != nil && isBadmintonEvent(event) {
+            day, ok := p.dateFor(s)
+            if !ok {
+                // Only complain about rows that would otherwise have been events
+                if title, timeText := eventTitleAndTime(s, profile); title != "" && timeText != "" &&
+                    rules.Match(title, "") {
+                    slog.Warn("Skipping schedule entry without a parseable date", "title", title, "time", timeText)
+                }
+                return
+            }
+            
+            event := parseEventFromElement(s, day, loc, profile, opts.PageURL)
+            if event == nil {
+                return
+            }
+            if tags, ok := rules.Classify(event.Title, event.Location); ok {
+                event.Tags = tags
                 events = append(events, *event)
             }
         })
@@ -90,9 +160,9 @@ func parseHTMLSchedule(body io.Reader, loc *time.Location) ([]store.Event, error
     return events, nil
 }
 
-// parseEventFromElement extracts event data from a DOM element
-func parseEventFromElement(s *goquery.Selection, loc *time.Location) *store.Event {
-    title := strings.TrimSpace(s.Find(".title, .name, .event-title, h3, h4").First().Text())
+// eventTitleAndTime extracts the title and time range text of a DOM element
+func eventTitleAndTime(s *goquery.Selection, profile *Profile) (string, string) {
+    title := strings.TrimSpace(findAll(s, profile.Title).First().Text())
     if title == "" {
         // Try to get text from the element itself
         title = strings.TrimSpace(s.Text())
@@ -101,16 +171,25 @@ func parseEventFromElement(s *goquery.Selection, loc *time.Location) *store.Even
         }
     }
     
-    location := strings.TrimSpace(s.Find(".location, .room, .facility, .venue").First().Text())
-    if location == "" {
-        location = "SJSU Fitness Center"
+    timeText := strings.TrimSpace(findAll(s, profile.Time).First().Text())
+    if timeText == "" {
+        timeText = findTimeRange(s.Text())
     }
     
-    // Try to extract time information
-    timeText := strings.TrimSpace(s.Find(".time, .duration, .schedule").First().Text())
-    startTime, endTime := parseTimeRange(timeText, loc)
+    return title, timeText
+}
+
+// parseEventFromElement extracts event data from a DOM element on the given day
+func parseEventFromElement(s *goquery.Selection, day time.Time, loc *time.Location, profile *Profile, pageURL string) *store.Event {
+    title, timeText := eventTitleAndTime(s, profile)
+    
+    location := strings.TrimSpace(findAll(s, profile.Location).First().Text())
+    if location == "" {
+        location = profile.DefaultLocation
+    }
     
-    if title == "" || startTime.IsZero() {
+    startTime, endTime, ok := parseTimeRange(timeText, day, loc)
+    if title == "" || !ok {
         return nil
     }
     
@@ -120,104 +199,48 @@ func parseEventFromElement(s *goquery.Selection, loc *time.Location) *store.Even
         Location:    location,
         Start:       startTime,
         End:         endTime,
-        SourceURL:   "https://fitness.sjsu.edu/Facility/GetSchedule",
-        Tags:        []string{"badminton"},
+        SourceURL:   pageURL,
+        RegisterURL: registerURL(s, profile, pageURL),
         RetrievedAt: time.Now(),
     }
     
     return event
 }
 
-// parseTimeRange parses time range from text like "9:00 AM - 10:30 AM" or "9:00-10:30"
-func parseTimeRange(timeText string, loc *time.Location) (time.Time, time.Time) {
-    if timeText == "" {
-        return time.Time{}, time.Time{}
-    }
-    
-    // Common time patterns
-    patterns := []string{
-        `(\d{1,2}):(\d{2})\s*(AM|PM)\s*-\s*(\d{1,2}):(\d{2})\s*(AM|PM)`, // 9:00 AM - 10:30 AM
-        `(\d{1,2}):(\d{2})\s*-\s*(\d{1,2}):(\d{2})`,                    // 9:00 - 10:30
-        `(\d{1,2})\s*(AM|PM)\s*-\s*(\d{1,2})\s*(AM|PM)`,              // 9 AM - 10 PM
-    }
-    
-    for _, pattern := range patterns {
-        re := regexp.MustCompile(`(?i)` + pattern)
-        matches := re.FindStringSubmatch(timeText)
-        
-        if len(matches) >= 4 {
-            startTime := parseTime(matches[1:3], loc)
-            endTime := parseTime(matches[3:5], loc)
-            
-            if !startTime.IsZero() && !endTime.IsZero() {
-                return startTime, endTime
-            }
-        }
+// registerURL returns the absolute href of the entry's registration link
+func registerURL(s *goquery.Selection, profile *Profile, pageURL string) string {
+    href, ok := findAll(s, profile.Link).First().Attr("href")
+    href = strings.TrimSpace(href)
+    if !ok || href == "" || strings.HasPrefix(href, "#") || strings.HasPrefix(strings.ToLower(href), "javascript:") {
+        return ""
     }
-    
-    return time.Time{}, time.Time{}
-}
 
-// parseTime parses a single time from hour and minute strings
-func parseTime(parts []string, loc *time.Location) time.Time {
-    if len(parts) < 2 {
-        return time.Time{}
-    }
-    
-    hour, err := strconv.Atoi(parts[0])
+    ref, err := url.Parse(href)
     if err != nil {
-        return time.Time{}
+        return ""
     }
-    
-    minute, err := strconv.Atoi(parts[1])
+    base, err := url.Parse(pageURL)
     if err != nil {
-        return time.Time{}
+        return ref.String()
     }
-    
-    // Handle AM/PM if present
-    if len(parts) > 2 {
-        ampm := strings.ToUpper(parts[2])
-        if ampm == "PM" && hour != 12 {
-            hour += 12
-        } else if ampm == "AM" && hour == 12 {
-            hour = 0
-        }
-    }
-    
-    // Use today's date for the time
-    now := time.Now().In(loc)
-    return time.Date(now.Year(), now.Month(), now.Day(), hour, minute, 0, 0, loc)
+    return base.ResolveReference(ref).String()
 }
 
-// isBadmintonEvent checks if an event is badminton-related
-func isBadmintonEvent(event *store.Event) bool {
-    if event == nil {
-        return false
-    }
-    
-    title := strings.ToLower(event.Title)
-    location := strings.ToLower(event.Location)
-    
-    badmintonKeywords := []string{
-        "badminton", "shuttlecock", "racquet", "racket",
-        "court", "doubles", "singles", "tournament",
+// findAll is s.Find, except that an empty selector matches nothing
+func findAll(s *goquery.Selection, selector string) *goquery.Selection {
+    if selector == "" {
+        return s.FilterFunction(func(int, *goquery.Selection) bool { return false })
     }
-    
-    for _, keyword := range badmintonKeywords {
-        if strings.Contains(title, keyword) || strings.Contains(location, keyword) {
-            return true
-        }
-    }
-    
-    return false
+    return s.Find(selector)
 }
 
 // convertToStoreEvents converts FitnessEvent slice to store.Event slice
-func convertToStoreEvents(events []FitnessEvent, loc *time.Location) ([]store.Event, error) {
+func convertToStoreEvents(events []FitnessEvent, loc *time.Location, rules *classify.Rules, sourceURL string) ([]store.Event, error) {
     var storeEvents []store.Event
     
     for _, event := range events {
-        if !isBadmintonEventTitle(event.Title) {
+        tags, ok := rules.Classify(event.Title, event.Location)
+        if !ok {
             continue
         }
         
@@ -233,14 +256,19 @@ func convertToStoreEvents(events []FitnessEvent, loc *time.Location) ([]store.Ev
             continue
         }
         
+        // Overnight sessions end on the following day
+        if !endTime.After(startTime) {
+            endTime = endTime.AddDate(0, 0, 1)
+        }
+        
         storeEvent := store.Event{
             ID:          store.HashKey(event.Title, startTime, endTime, event.Location),
             Title:       event.Title,
             Location:    event.Location,
             Start:       startTime,
             End:         endTime,
-            SourceURL:   "https://fitness.sjsu.edu/Facility/GetSchedule",
-            Tags:        []string{"badminton"},
+            SourceURL:   sourceURL,
+            Tags:        tags,
             RetrievedAt: time.Now(),
         }
         
@@ -250,20 +278,6 @@ func convertToStoreEvents(events []FitnessEvent, loc *time.Location) ([]store.Ev
     return storeEvents, nil
 }
 
-// isBadmintonEventTitle checks if a title indicates a badminton event
-func isBadmintonEventTitle(title string) bool {
-    title = strings.ToLower(title)
-    badmintonKeywords := []string{"badminton", "shuttlecock", "racquet", "racket"}
-    
-    for _, keyword := range badmintonKeywords {
-        if strings.Contains(title, keyword) {
-            return true
-        }
-    }
-    
-    return false
-}
-
 // parseEventTime parses date and time strings into a time.Time
 func parseEventTime(dateStr, timeStr string, loc *time.Location) (time.Time, error) {
     // Try common date formats
@@ -273,18 +287,17 @@ func parseEventTime(dateStr, timeStr string, loc *time.Location) (time.Time, err
     }
     
     var date time.Time
-    var err error
+    err := fmt.Errorf("unable to parse date: %q", dateStr)
     
     for _, format := range dateFormats {
-        date, err = time.ParseInLocation(format, dateStr, loc)
-        if err == nil {
+        if d, perr := time.ParseInLocation(format, strings.TrimSpace(dateStr), loc); perr == nil {
+            date, err = d, nil
             break
         }
     }
     
     if err != nil {
-        // If date parsing fails, use today
-        date = time.Now().In(loc)
+        return time.Time{}, err
     }
     
     // Parse time
@@ -345,7 +358,7 @@ func CreateFallbackBadmintonEvents(loc *time.Location) []store.Event {
                 Location:    "SJSU Fitness Center",
                 Start:       startTime,
                 End:         endTime,
-                SourceURL:   "https://fitness.sjsu.edu/Facility/GetSchedule",
+                SourceURL:   fitnessScheduleURL,
                 Tags:        []string{"badminton", "fallback"},
                 RetrievedAt: now,
             }
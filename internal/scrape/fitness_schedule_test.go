@@ -0,0 +1,231 @@
+package scrape
+
+import (
+    "os"
+    "strings"
+    "testing"
+    "time"
+
+    "github.com/sjsu-badminton/badminton-discord-bot/internal/classify"
+)
+
+func mustLoadLA(t *testing.T) *time.Location {
+    t.Helper()
+    loc, err := time.LoadLocation("America/Los_Angeles")
+    if err != nil {
+        t.Fatalf("Failed to load timezone: %v", err)
+    }
+    return loc
+}
+
+func TestParseHTMLScheduleDates(t *testing.T) {
+    loc := mustLoadLA(t)
+    now := time.Date(2024, 1, 14, 12, 0, 0, 0, loc)
+
+    type want struct {
+        title string
+        start time.Time
+        end   time.Time
+    }
+
+    testCases := []struct {
+        name     string
+        filename string
+        expected []want
+    }{
+        {
+            name:     "day headers",
+            filename: "testdata/fitness_day_headers.html",
+            expected: []want{
+                {"Badminton Open Play", time.Date(2024, 1, 15, 18, 0, 0, 0, loc), time.Date(2024, 1, 15, 20, 0, 0, 0, loc)},
+                {"Badminton Club Practice", time.Date(2024, 1, 16, 19, 0, 0, 0, loc), time.Date(2024, 1, 16, 21, 0, 0, 0, loc)},
+                {"Late Night Badminton", time.Date(2024, 1, 16, 22, 0, 0, 0, loc), time.Date(2024, 1, 17, 1, 0, 0, 0, loc)},
+            },
+        },
+        {
+            name:     "weekly grid columns",
+            filename: "testdata/fitness_week_grid.html",
+            expected: []want{
+                {"Badminton Open Play", time.Date(2024, 1, 15, 11, 0, 0, 0, loc), time.Date(2024, 1, 15, 13, 0, 0, 0, loc)},
+                {"Badminton Lessons", time.Date(2024, 1, 17, 10, 30, 0, 0, loc), time.Date(2024, 1, 17, 11, 30, 0, 0, loc)},
+            },
+        },
+        {
+            name:     "entries without a date are skipped",
+            filename: "testdata/fitness_undated.html",
+            expected: []want{
+                {"Badminton Doubles Night", time.Date(2024, 1, 18, 18, 0, 0, 0, loc), time.Date(2024, 1, 18, 20, 0, 0, 0, loc)},
+            },
+        },
+    }
+
+    for _, tc := range testCases {
+        t.Run(tc.name, func(t *testing.T) {
+            f, err := os.Open(tc.filename)
+            if err != nil {
+                t.Fatalf("Failed to open fixture: %v", err)
+            }
+            defer f.Close()
+
+            events, err := ParseHTMLSchedule(f, HTMLOptions{Location: loc, Now: now})
+            if err != nil {
+                t.Fatalf("Unexpected error: %v", err)
+            }
+
+            if len(events) != len(tc.expected) {
+                t.Fatalf("Expected %d events, got %d: %+v", len(tc.expected), len(events), events)
+            }
+
+            for i, w := range tc.expected {
+                e := events[i]
+                if e.Title != w.title {
+                    t.Errorf("Event[%d] expected title %q, got %q", i, w.title, e.Title)
+                }
+                if !e.Start.Equal(w.start) || !e.End.Equal(w.end) {
+                    t.Errorf("Event[%d] expected %v - %v, got %v - %v", i, w.start, w.end, e.Start, e.End)
+                }
+            }
+        })
+    }
+}
+
+func TestParseTimeRange(t *testing.T) {
+    loc := mustLoadLA(t)
+    day := time.Date(2024, 1, 15, 0, 0, 0, 0, loc)
+
+    testCases := []struct {
+        text  string
+        start string
+        end   string
+        ok    bool
+    }{
+        {"9:00 AM - 10:30 AM", "2024-01-15 09:00", "2024-01-15 10:30", true},
+        {"9:00-10:30", "2024-01-15 09:00", "2024-01-15 10:30", true},
+        {"9 AM - 10 PM", "2024-01-15 09:00", "2024-01-15 22:00", true},
+        {"6pm to 8pm", "2024-01-15 18:00", "2024-01-15 20:00", true},
+        {"11 - 1 PM", "2024-01-15 11:00", "2024-01-15 13:00", true},
+        {"7 - 9 PM", "2024-01-15 19:00", "2024-01-15 21:00", true},
+        {"11:30 AM - 1", "2024-01-15 11:30", "2024-01-15 13:00", true},
+        {"10:00 PM - 1:00 AM", "2024-01-15 22:00", "2024-01-16 01:00", true},
+        {"22:00 - 02:00", "2024-01-15 22:00", "2024-01-16 02:00", true},
+        {"Open 6:00 PM – 8:00 PM daily", "2024-01-15 18:00", "2024-01-15 20:00", true},
+        {"Courts 1-4", "", "", false},
+        {"", "", "", false},
+    }
+
+    for _, tc := range testCases {
+        t.Run(tc.text, func(t *testing.T) {
+            start, end, ok := parseTimeRange(tc.text, day, loc)
+            if ok != tc.ok {
+                t.Fatalf("Expected ok=%v, got %v (%v - %v)", tc.ok, ok, start, end)
+            }
+            if !ok {
+                return
+            }
+            if got := start.Format("2006-01-02 15:04"); got != tc.start {
+                t.Errorf("Expected start %s, got %s", tc.start, got)
+            }
+            if got := end.Format("2006-01-02 15:04"); got != tc.end {
+                t.Errorf("Expected end %s, got %s", tc.end, got)
+            }
+        })
+    }
+}
+
+func TestParseScheduleDate(t *testing.T) {
+    loc := mustLoadLA(t)
+    now := time.Date(2024, 12, 28, 12, 0, 0, 0, loc) // Saturday
+
+    testCases := []struct {
+        text     string
+        expected string
+        ok       bool
+    }{
+        {"Monday, January 15, 2024", "2024-01-15", true},
+        {"2024-03-09", "2024-03-09", true},
+        {"Tue 12/31", "2024-12-31", true},
+        {"Thu 1/2", "2025-01-02", true},
+        {"1/2/25", "2025-01-02", true},
+        {"Jan 3rd", "2025-01-03", true},
+        {"Dec. 20", "2024-12-20", true},
+        {"Monday", "2024-12-30", true},
+        {"Saturday", "2024-12-28", true},
+        {"2/30/2024", "", false},
+        {"Monday Night Badminton", "", false},
+        {"Badminton Open Play", "", false},
+    }
+
+    for _, tc := range testCases {
+        t.Run(tc.text, func(t *testing.T) {
+            d, ok := parseScheduleDate(tc.text, now, loc)
+            if ok != tc.ok {
+                t.Fatalf("Expected ok=%v, got %v (%v)", tc.ok, ok, d)
+            }
+            if ok && d.Format("2006-01-02") != tc.expected {
+                t.Errorf("Expected %s, got %s", tc.expected, d.Format("2006-01-02"))
+            }
+        })
+    }
+}
+
+func TestParseEventTimeRejectsBadDates(t *testing.T) {
+    loc := mustLoadLA(t)
+
+    if _, err := parseEventTime("not a date", "6:00 PM", loc); err == nil {
+        t.Error("Expected error for unparseable date")
+    } else if !strings.Contains(err.Error(), "date") {
+        t.Errorf("Expected a date error, got %v", err)
+    }
+
+    if _, err := parseEventTime("", "6:00 PM", loc); err == nil {
+        t.Error("Expected error for missing date")
+    }
+
+    got, err := parseEventTime("01/15/2024", "6:00 PM", loc)
+    if err != nil {
+        t.Fatalf("Unexpected error: %v", err)
+    }
+    if want := time.Date(2024, 1, 15, 18, 0, 0, 0, loc); !got.Equal(want) {
+        t.Errorf("Expected %v, got %v", want, got)
+    }
+}
+
+func TestConvertToStoreEventsOvernight(t *testing.T) {
+    loc := mustLoadLA(t)
+
+    events, err := convertToStoreEvents([]FitnessEvent{
+        {Title: "Late Night Badminton", Date: "2024-01-15", StartTime: "22:00", EndTime: "01:00"},
+        {Title: "Badminton Open Play", Date: "someday", StartTime: "18:00", EndTime: "20:00"},
+    }, loc, classify.Default(), fitnessScheduleURL)
+    if err != nil {
+        t.Fatalf("Unexpected error: %v", err)
+    }
+
+    if len(events) != 1 {
+        t.Fatalf("Expected the undated event to be dropped, got %d events", len(events))
+    }
+
+    if want := time.Date(2024, 1, 16, 1, 0, 0, 0, loc); !events[0].End.Equal(want) {
+        t.Errorf("Expected overnight end %v, got %v", want, events[0].End)
+    }
+}
+
+func TestConvertToStoreEventsClassifies(t *testing.T) {
+    loc := mustLoadLA(t)
+
+    events, err := convertToStoreEvents([]FitnessEvent{
+        {Title: "Badminton Club Tournament", Location: "SRAC Gym", Date: "2024-01-15", StartTime: "18:00", EndTime: "20:00"},
+        {Title: "Tennis Tournament", Location: "Tennis Courts", Date: "2024-01-15", StartTime: "18:00", EndTime: "20:00"},
+        {Title: "Basketball Court Reservation", Location: "SRAC Court 1", Date: "2024-01-15", StartTime: "18:00", EndTime: "20:00"},
+    }, loc, classify.Default(), fitnessScheduleURL)
+    if err != nil {
+        t.Fatalf("Unexpected error: %v", err)
+    }
+
+    if len(events) != 1 || events[0].Title != "Badminton Club Tournament" {
+        t.Fatalf("Expected only the badminton event, got %+v", events)
+    }
+    if got, want := strings.Join(events[0].Tags, ","), "badminton,club,tournament"; got != want {
+        t.Errorf("Expected tags %q, got %q", want, got)
+    }
+}
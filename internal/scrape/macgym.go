@@ -1,10 +1,12 @@
 package scrape
 
 import (
+    "bytes"
     "context"
     "fmt"
     "io"
     "log/slog"
+    "net/http"
     "strings"
     "time"
 
@@ -27,103 +29,173 @@ type MacGymResponse struct {
 }
 
 // FetchMacGym fetches and parses Mac Gym occupancy data
-func FetchMacGym(ctx context.Context, url string) (store.MacGymSnapshot, error) {
+func (sc *Scraper) FetchMacGym(ctx context.Context) (store.MacGymSnapshot, error) {
+    url := sc.macGymOpts.URL
     slog.Info("Fetching Mac Gym data", "url", url, "version", "v2.2")
     
-    r, err := util.Get(ctx, url)
+    ctx, cancel := withTimeout(ctx, sc.macGymOpts)
+    defer cancel()
+    
+    r, err := sc.macGym.Get(ctx, url)
     if err != nil {
-        slog.Error("Failed to fetch Mac Gym data, using fallback", "error", err)
-        return store.MacGymSnapshot{}, fmt.Errorf("API returned HTML instead of JSON - cannot extract real occupancy data")
+        slog.Error("Failed to fetch Mac Gym data", "error", err)
+        return store.MacGymSnapshot{}, fmt.Errorf("fetching Mac Gym data: %w", err)
     }
     defer r.Body.Close()
 
-    // Check if response is HTML (API might have changed)
-    contentType := r.Header.Get("Content-Type")
-    if strings.Contains(contentType, "text/html") {
-        slog.Error("Mac Gym API returned HTML instead of JSON, cannot extract real data")
-        return store.MacGymSnapshot{}, fmt.Errorf("API returned HTML instead of JSON - cannot extract real occupancy data")
+    if r.StatusCode == http.StatusNotModified {
+        slog.Debug("Mac Gym data not modified")
+        return store.MacGymSnapshot{}, util.ErrNotModified
     }
 
-    // Read the response body to check content
     bodyBytes, err := io.ReadAll(r.Body)
     if err != nil {
-        slog.Error("Failed to read Mac Gym response body, using fallback", "error", err)
-        return store.MacGymSnapshot{}, fmt.Errorf("API returned HTML instead of JSON - cannot extract real occupancy data")
+        slog.Error("Failed to read Mac Gym response body", "error", err)
+        return store.MacGymSnapshot{}, fmt.Errorf("reading Mac Gym response: %w", err)
     }
     
-    // Check if response is HTML
-    bodyStr := string(bodyBytes)
-    if strings.HasPrefix(strings.TrimSpace(bodyStr), "<") {
-        slog.Error("Mac Gym API returned HTML instead of JSON, cannot extract real data")
-        return store.MacGymSnapshot{}, fmt.Errorf("API returned HTML instead of JSON - cannot extract real occupancy data")
+    // The endpoint serves either JSON or the HTML occupancy widget
+    if looksLikeHTML(r.Header.Get("Content-Type"), bodyBytes) {
+        locations, err := parseWidgetHTML(bytes.NewReader(bodyBytes), sc.loc)
+        if err != nil {
+            slog.Error("Failed to parse Mac Gym widget", "error", err)
+            return store.MacGymSnapshot{}, fmt.Errorf("parsing Mac Gym widget: %w", err)
+        }
+        return sc.macGymSnapshot(locations, nil, time.Now()), nil
     }
     
     var response MacGymResponse
-    if err := util.DecodeJSON(strings.NewReader(bodyStr), &response); err != nil {
-        slog.Error("Failed to decode Mac Gym JSON, using fallback", "error", err)
-        return store.MacGymSnapshot{}, fmt.Errorf("API returned HTML instead of JSON - cannot extract real occupancy data")
+    if err := util.DecodeJSON(bytes.NewReader(bodyBytes), &response); err != nil {
+        slog.Error("Failed to decode Mac Gym JSON", "error", err)
+        return store.MacGymSnapshot{}, fmt.Errorf("decoding Mac Gym JSON: %w", err)
     }
 
     if !response.Success {
-        slog.Error("Mac Gym API returned error, using fallback", "message", response.Message)
-        return store.MacGymSnapshot{}, fmt.Errorf("API returned HTML instead of JSON - cannot extract real occupancy data")
+        slog.Error("Mac Gym API returned error", "message", response.Message)
+        return store.MacGymSnapshot{}, fmt.Errorf("Mac Gym API returned error: %s", response.Message)
     }
 
-    snap := store.MacGymSnapshot{
-        RetrievedAt: time.Now(),
-        Location:    "Mac Gym",
-        Raw:         response,
-    }
+    return sc.macGymSnapshot(jsonFacilities(response), response, time.Now()), nil
+}
 
-    // Find badminton-related data in the response
+// jsonFacilities converts the API's locations to facilities
+func jsonFacilities(response MacGymResponse) []store.Facility {
+    facilities := make([]store.Facility, 0, len(response.Data))
     for _, location := range response.Data {
-        locationName := strings.ToLower(location.LocationName)
+        f := store.Facility{
+            ID:       location.LocationID,
+            Name:     location.LocationName,
+            Count:    location.CurrentCount,
+            Capacity: location.MaxCapacity,
+            Closed:   strings.EqualFold(location.Status, "closed"),
+        }
+        if f.ID == "" {
+            f.ID = f.Name
+        }
         
-        // Look for badminton courts or general gym capacity
-        if strings.Contains(locationName, "badminton") || 
-           strings.Contains(locationName, "court") ||
-           strings.Contains(locationName, "gym") {
-            
-            snap.Capacity = location.MaxCapacity
-            snap.InUse = location.CurrentCount
-            snap.Details = fmt.Sprintf("%s: %d/%d in use", 
-                location.LocationName, location.CurrentCount, location.MaxCapacity)
-            
-            // Parse last updated time if available
-            if location.LastUpdated != "" {
-                if t, err := time.Parse(time.RFC3339, location.LastUpdated); err == nil {
-                    snap.RetrievedAt = t
+        // Parse last updated time if available
+        if location.LastUpdated != "" {
+            if t, err := time.Parse(time.RFC3339, location.LastUpdated); err == nil {
+                f.Updated = t
+            }
+        }
+        facilities = append(facilities, f)
+    }
+    return facilities
+}
+
+// isMacGymLocation reports whether a location name looks like the badminton
+// courts or the gym they're in
+func isMacGymLocation(name string) bool {
+    name = strings.ToLower(name)
+    return strings.Contains(name, "badminton") ||
+        strings.Contains(name, "court") ||
+        strings.Contains(name, "gym")
+}
+
+// courtFacilities returns the indexes of the facilities that make up the
+// badminton courts: those with a configured location ID or name, or when
+// none are configured or found, the first named like the courts or the gym,
+// falling back to the first facility
+func courtFacilities(facilities []store.Facility, ids []string) []int {
+    if len(ids) > 0 {
+        var courts []int
+        for i, f := range facilities {
+            for _, id := range ids {
+                if strings.EqualFold(f.ID, id) || strings.EqualFold(f.Name, id) {
+                    courts = append(courts, i)
+                    break
                 }
             }
-            
-            slog.Info("Found badminton data", 
-                "location", location.LocationName,
-                "capacity", location.MaxCapacity,
-                "inUse", location.CurrentCount)
-            
-            break
         }
+        if len(courts) > 0 {
+            return courts
+        }
+        slog.Warn("None of the configured court locations were found, guessing by name", "ids", ids)
     }
 
-    // Fallback: if no specific badminton data found, use first available location
-    if snap.Details == "" && len(response.Data) > 0 {
-        loc := response.Data[0]
-        snap.Capacity = loc.MaxCapacity
-        snap.InUse = loc.CurrentCount
-        snap.Details = fmt.Sprintf("%s: %d/%d in use", 
-            loc.LocationName, loc.CurrentCount, loc.MaxCapacity)
-        
-        slog.Info("Using fallback location data", 
-            "location", loc.LocationName,
-            "capacity", loc.MaxCapacity,
-            "inUse", loc.CurrentCount)
+    for i, f := range facilities {
+        if isMacGymLocation(f.Name) {
+            return []int{i}
+        }
+    }
+    if len(facilities) > 0 {
+        slog.Info("Using fallback location data", "location", facilities[0].Name)
+        return []int{0}
+    }
+    return nil
+}
+
+// macGymSnapshot builds a snapshot holding every facility, with the court
+// facilities' counts and capacities summed into InUse and Capacity
+func (sc *Scraper) macGymSnapshot(facilities []store.Facility, raw any, now time.Time) store.MacGymSnapshot {
+    snap := store.MacGymSnapshot{
+        RetrievedAt: now,
+        Location:    "Mac Gym",
+        Facilities:  facilities,
+        Raw:         raw,
     }
 
-    if snap.Details == "" {
+    courts := courtFacilities(facilities, sc.courtIDs)
+    if len(courts) == 0 {
         snap.Details = "Mac Gym status retrieved (no capacity data available)"
+        return snap
+    }
+
+    var names []string
+    var updated time.Time
+    closed := true
+    for _, i := range courts {
+        f := &snap.Facilities[i]
+        f.Courts = true
+        snap.InUse += f.Count
+        snap.Capacity += f.Capacity
+        names = append(names, f.Name)
+        closed = closed && f.Closed
+        if f.Updated.After(updated) {
+            updated = f.Updated
+        }
+    }
+    if !updated.IsZero() {
+        snap.RetrievedAt = updated
+    }
+
+    name := strings.Join(names, " + ")
+    if snap.Capacity > 0 {
+        snap.Details = fmt.Sprintf("%s: %d/%d in use", name, snap.InUse, snap.Capacity)
+    } else {
+        snap.Details = fmt.Sprintf("%s: last count %d", name, snap.InUse)
+    }
+    if closed {
+        snap.Details += " (closed)"
     }
 
-    return snap, nil
+    slog.Info("Found badminton data",
+        "locations", names,
+        "capacity", snap.Capacity,
+        "inUse", snap.InUse,
+        "facilities", len(facilities))
+    return snap
 }
 
 // CreateFallbackMacGymData creates fallback data when the API is unavailable
@@ -2,135 +2,124 @@ package scrape
 
 import (
 	"context"
-	"io"
-	"log/slog"
+	"net/http"
+	"net/http/httptest"
 	"os"
-	"regexp"
+	"strings"
 	"testing"
 	"time"
 
-	"github.com/sjsu-badminton/badminton-discord-bot/internal/config"
-	"github.com/sjsu-badminton/badminton-discord-bot/internal/util"
+	"github.com/sjsu-badminton/badminton-discord-bot/internal/store"
 )
 
 func TestMacGymHTMLParsing(t *testing.T) {
-	// Set up test logger
-	slog.SetDefault(slog.New(slog.NewTextHandler(os.Stdout, &slog.HandlerOptions{
-		Level: slog.LevelDebug,
-	})))
-
-	// Load configuration
-	cfg, err := config.Load()
-	if err != nil {
-		t.Fatalf("Failed to load config: %v", err)
-	}
-
-	t.Logf("Testing HTML parsing with Mac Gym URL: %s", cfg.MacGymURL)
-
-	ctx, cancel := context.WithTimeout(context.Background(), 30*time.Second)
-	defer cancel()
-
-	// Fetch the raw HTML response
-	r, err := util.Get(ctx, cfg.MacGymURL)
-	if err != nil {
-		t.Fatalf("Failed to fetch Mac Gym data: %v", err)
-	}
-	defer r.Body.Close()
-
-	// Read the response body
-	bodyBytes, err := io.ReadAll(r.Body)
-	if err != nil {
-		t.Fatalf("Failed to read response body: %v", err)
+	loc := mustLoadLA(t)
+
+	testCases := []struct {
+		name    string
+		fixture string
+		want    []store.Facility
+	}{
+		{
+			name:    "single location",
+			fixture: "testdata/macgym_widget.html",
+			want: []store.Facility{
+				{ID: "MAC Gym", Name: "MAC Gym", Count: 10, Updated: time.Date(2025, 9, 9, 16, 6, 0, 0, loc)},
+			},
+		},
+		{
+			name:    "circle charts with data attributes",
+			fixture: "testdata/macgym_widget_multi.html",
+			want: []store.Facility{
+				{ID: "5633", Name: "Event Center Fitness Floor", Count: 23, Capacity: 120, Updated: time.Date(2025, 9, 9, 16, 1, 0, 0, loc)},
+				{ID: "5634", Name: "MAC Gym", Count: 10, Capacity: 150, Updated: time.Date(2025, 9, 9, 16, 6, 0, 0, loc)},
+				{ID: "5635", Name: "Aquatic Center", Closed: true, Count: 0, Capacity: 60, Updated: time.Date(2025, 9, 8, 21, 0, 0, 0, loc)},
+			},
+		},
+		{
+			name:    "plain text table",
+			fixture: "testdata/macgym_widget_text.html",
+			want: []store.Facility{
+				{ID: "SRAC Weight Room", Name: "SRAC Weight Room", Count: 4, Capacity: 40, Updated: time.Date(2025, 9, 9, 16, 5, 0, 0, loc)},
+				{ID: "MAC Gym", Name: "MAC Gym", Count: 12, Capacity: 150, Updated: time.Date(2025, 9, 9, 16, 6, 0, 0, loc)},
+			},
+		},
 	}
 
-	bodyStr := string(bodyBytes)
-	t.Logf("=== RAW HTML RESPONSE ===")
-	t.Logf("Content-Type: %s", r.Header.Get("Content-Type"))
-	t.Logf("Response length: %d bytes", len(bodyStr))
-	t.Logf("First 1000 characters: %s", bodyStr[:min(1000, len(bodyStr))])
-	// Search for specific patterns in the HTML
-	t.Logf("=== SEARCHING FOR PATTERNS ===")
-	
-	// Look for any numbers that might be counts
-	countRegex := regexp.MustCompile(`\b(\d+)\b`)
-	countMatches := countRegex.FindAllString(bodyStr, -1)
-	t.Logf("All numbers found: %v", countMatches)
-	
-	// Look for time patterns
-	timeRegex := regexp.MustCompile(`\d{2}/\d{2}/\d{4}\s+\d{2}:\d{2}\s+[AP]M`)
-	timeMatches := timeRegex.FindAllString(bodyStr, -1)
-	t.Logf("Time patterns found: %v", timeMatches)
-	
-	// Look for "MAC Gym" or similar
-	macGymRegex := regexp.MustCompile(`(?i)(mac\s+gym|gym)`)
-	macGymMatches := macGymRegex.FindAllString(bodyStr, -1)
-	t.Logf("MAC Gym references found: %v", macGymMatches)
-	
-	// Look for JavaScript data
-	jsDataRegex := regexp.MustCompile(`LocationId.*?(\d+)`)
-	jsMatches := jsDataRegex.FindAllStringSubmatch(bodyStr, -1)
-	t.Logf("Location IDs found: %v", jsMatches)
-	t.Logf("Last 1000 characters: %s", bodyStr[max(0, len(bodyStr)-1000):])
-	// Try to parse the HTML for the data we need
-	status, count, updated := parseMacGymHTML(bodyStr)
-	
-	t.Logf("=== PARSED DATA ===")
-	t.Logf("Status: %s", status)
-	t.Logf("Count: %s", count)
-	t.Logf("Updated: %s", updated)
-
-	// Validate that we found the expected data
-	if status == "" {
-		t.Error("Could not find status in HTML")
-	}
-	if count == "" {
-		t.Error("Could not find count in HTML")
-	}
-	if updated == "" {
-		t.Error("Could not find updated time in HTML")
-	}
-
-	// Try to convert count to integer
-	if count != "" {
-		// Extract just the number from the count
-		re := regexp.MustCompile(`\d+`)
-		matches := re.FindAllString(count, -1)
-		if len(matches) > 0 {
-			t.Logf("Extracted count number: %s", matches[0])
-		}
+	for _, tc := range testCases {
+		t.Run(tc.name, func(t *testing.T) {
+			f, err := os.Open(tc.fixture)
+			if err != nil {
+				t.Fatalf("Failed to open test data: %v", err)
+			}
+			defer f.Close()
+
+			got, err := parseWidgetHTML(f, loc)
+			if err != nil {
+				t.Fatalf("parseWidgetHTML: %v", err)
+			}
+
+			if len(got) != len(tc.want) {
+				t.Fatalf("Expected %d locations, got %d: %+v", len(tc.want), len(got), got)
+			}
+			for i, want := range tc.want {
+				g := got[i]
+				if g.ID != want.ID || g.Name != want.Name || g.Closed != want.Closed || g.Count != want.Count || g.Capacity != want.Capacity {
+					t.Errorf("Location %d: expected %+v, got %+v", i, want, g)
+				}
+				if !g.Updated.Equal(want.Updated) {
+					t.Errorf("Location %d: expected updated %v, got %v", i, want.Updated, g.Updated)
+				}
+			}
+		})
 	}
 }
 
-func parseMacGymHTML(html string) (status, count, updated string) {
-	// Look for patterns in the HTML that match the expected format:
-	// MAC Gym (Open)
-	// Last Count: 10
-	// Updated: 09/09/2025 04:06 PM
-
-	// Try to find the status (Open/Closed)
-	statusRegex := regexp.MustCompile(`(?i)(open|closed)`)
-	if matches := statusRegex.FindStringSubmatch(html); len(matches) > 0 {
-		status = matches[1]
-	}
-
-	// Try to find the count
-	countRegex := regexp.MustCompile(`(?i)(?:last\s+count|count)[:\s]*(\d+)`)
-	if matches := countRegex.FindStringSubmatch(html); len(matches) > 0 {
-		count = matches[1]
+func TestParseWidgetHTMLWithoutLocations(t *testing.T) {
+	_, err := parseWidgetHTML(strings.NewReader("<html><body><p>Service unavailable</p></body></html>"), time.UTC)
+	if err == nil {
+		t.Error("Expected an error for a page without locations")
 	}
-
-	// Try to find the updated time
-	updatedRegex := regexp.MustCompile(`(?i)(?:updated|last\s+updated)[:\s]*(\d{2}/\d{2}/\d{4}\s+\d{2}:\d{2}\s+[AP]M)`)
-	if matches := updatedRegex.FindStringSubmatch(html); len(matches) > 0 {
-		updated = matches[1]
-	}
-
-	return status, count, updated
 }
 
-func min(a, b int) int {
-	if a < b {
-		return a
+func TestFetchMacGymWidget(t *testing.T) {
+	html, err := os.ReadFile("testdata/macgym_widget_multi.html")
+	if err != nil {
+		t.Fatalf("Failed to read test data: %v", err)
+	}
+	loc := mustLoadLA(t)
+
+	// The widget is recognised by its content even when mislabelled
+	for _, contentType := range []string{"text/html; charset=utf-8", "text/plain", ""} {
+		t.Run("content type "+contentType, func(t *testing.T) {
+			srv := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+				w.Header()["Content-Type"] = []string{contentType}
+				w.Write(html)
+			}))
+			defer srv.Close()
+
+			sc := New(Options{Doer: srv.Client(), Location: loc, MacGym: SourceOptions{URL: srv.URL}})
+
+			ctx, cancel := context.WithTimeout(context.Background(), 5*time.Second)
+			defer cancel()
+
+			snap, err := sc.FetchMacGym(ctx)
+			if err != nil {
+				t.Fatalf("FetchMacGym: %v", err)
+			}
+
+			if snap.InUse != 10 || snap.Capacity != 150 {
+				t.Errorf("Expected MAC Gym at 10/150, got %d/%d", snap.InUse, snap.Capacity)
+			}
+			if want := time.Date(2025, 9, 9, 16, 6, 0, 0, loc); !snap.RetrievedAt.Equal(want) {
+				t.Errorf("Expected RetrievedAt %v, got %v", want, snap.RetrievedAt)
+			}
+			if snap.Details != "MAC Gym: 10/150 in use" {
+				t.Errorf("Unexpected details %q", snap.Details)
+			}
+			if len(snap.Facilities) != 3 || !snap.Facilities[1].Courts || snap.Facilities[0].Courts {
+				t.Errorf("Expected all 3 facilities with only MAC Gym as courts, got %+v", snap.Facilities)
+			}
+		})
 	}
-	return b
 }
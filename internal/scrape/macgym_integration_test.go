@@ -2,72 +2,71 @@ package scrape
 
 import (
 	"context"
-	"log/slog"
+	"net/http"
+	"net/http/httptest"
 	"os"
 	"testing"
 	"time"
 
-	"github.com/sjsu-badminton/badminton-discord-bot/internal/config"
 	"github.com/sjsu-badminton/badminton-discord-bot/internal/store"
 )
 
 func TestMacGymDataFetching(t *testing.T) {
-	// Set up test logger
-	slog.SetDefault(slog.New(slog.NewTextHandler(os.Stdout, &slog.HandlerOptions{
-		Level: slog.LevelDebug,
-	})))
-
-	// Load configuration
-	cfg, err := config.Load()
+	data, err := os.ReadFile("testdata/macgym_sample.json")
 	if err != nil {
-		t.Fatalf("Failed to load config: %v", err)
+		t.Fatalf("Failed to read test data: %v", err)
 	}
 
-	t.Logf("Testing with Mac Gym URL: %s", cfg.MacGymURL)
-
-	// Test 1: Fetch real data from API
-	t.Run("FetchRealData", func(t *testing.T) {
-		ctx, cancel := context.WithTimeout(context.Background(), 30*time.Second)
-		defer cancel()
+	srv := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		if ua := r.Header.Get("User-Agent"); ua != "test-agent" {
+			t.Errorf("Expected the configured user agent, got %q", ua)
+		}
+		w.Header().Set("Content-Type", "application/json")
+		w.Write(data)
+	}))
+	defer srv.Close()
+
+	sc := New(Options{
+		Doer:      srv.Client(),
+		UserAgent: "test-agent",
+		MacGym:    SourceOptions{URL: srv.URL, Timeout: 5 * time.Second},
+	})
 
-		snap, err := FetchMacGym(ctx, cfg.MacGymURL)
+	// Test 1: Fetch data from the API
+	t.Run("FetchData", func(t *testing.T) {
+		snap, err := sc.FetchMacGym(context.Background())
 		if err != nil {
-			t.Logf("API fetch failed (expected if API is down): %v", err)
+			t.Fatalf("FetchMacGym: %v", err)
 		}
 
-		// Log all the data we got
-		t.Logf("=== MAC GYM DATA ANALYSIS ===")
-		t.Logf("RetrievedAt: %s", snap.RetrievedAt.Format(time.RFC3339))
-		t.Logf("Location: %s", snap.Location)
-		t.Logf("Capacity: %d", snap.Capacity)
-		t.Logf("InUse: %d", snap.InUse)
-		t.Logf("Details: %s", snap.Details)
-		
-		if snap.Raw != nil {
-			t.Logf("Raw data type: %T", snap.Raw)
-			t.Logf("Raw data: %+v", snap.Raw)
+		if snap.Location != "Mac Gym" {
+			t.Errorf("Expected location Mac Gym, got %q", snap.Location)
 		}
-
-		// Calculate percentage
-		if snap.Capacity > 0 {
-			percentage := float64(snap.InUse) / float64(snap.Capacity) * 100
-			available := snap.Capacity - snap.InUse
-			availability := float64(available) / float64(snap.Capacity) * 100
-			
-			t.Logf("=== CALCULATED VALUES ===")
-			t.Logf("Courts in use: %d/%d (%.1f%%)", snap.InUse, snap.Capacity, percentage)
-			t.Logf("Courts available: %d/%d (%.1f%%)", available, snap.Capacity, availability)
+		if snap.Capacity != 8 || snap.InUse != 6 {
+			t.Errorf("Expected 6/8 courts in use, got %d/%d", snap.InUse, snap.Capacity)
+		}
+		if want := time.Date(2024, 1, 15, 14, 30, 0, 0, time.UTC); !snap.RetrievedAt.Equal(want) {
+			t.Errorf("Expected RetrievedAt from lastUpdated %v, got %v", want, snap.RetrievedAt)
 		}
+		if snap.Details != "Mac Gym - Badminton Courts: 6/8 in use" {
+			t.Errorf("Unexpected details %q", snap.Details)
+		}
+	})
+
+	// Test 2: Server errors are reported rather than masked
+	t.Run("ServerError", func(t *testing.T) {
+		failing := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+			http.Error(w, "maintenance", http.StatusNotFound)
+		}))
+		defer failing.Close()
 
-		// Validate data structure
-		if snap.Capacity == 0 && snap.InUse == 0 {
-			t.Log("WARNING: No capacity data available - using fallback data")
-		} else {
-			t.Log("SUCCESS: Valid capacity data found")
+		sc := New(Options{Doer: failing.Client(), MacGym: SourceOptions{URL: failing.URL}})
+		if _, err := sc.FetchMacGym(context.Background()); err == nil {
+			t.Error("Expected an error for a 404 response")
 		}
 	})
 
-	// Test 2: Test fallback data generation
+	// Test 3: Test fallback data generation
 	t.Run("TestFallbackData", func(t *testing.T) {
 		fallback := CreateFallbackMacGymData()
 		
@@ -1,7 +1,11 @@
 package scrape
 
 import (
+    "context"
+    "errors"
     "fmt"
+    "net/http"
+    "net/http/httptest"
     "os"
     "strings"
     "testing"
@@ -113,7 +117,7 @@ func TestMacGymSnapshotCreation(t *testing.T) {
         },
     }
 
-    snap := createSnapshotFromResponse(response)
+    snap := New(Options{}).macGymSnapshot(jsonFacilities(response), response, time.Now())
     
     if snap.Location != "Mac Gym" {
         t.Errorf("Expected location 'Mac Gym', got '%s'", snap.Location)
@@ -132,39 +136,91 @@ func TestMacGymSnapshotCreation(t *testing.T) {
     }
 }
 
-// Helper function to create snapshot from response (extracted from main function)
-func createSnapshotFromResponse(response MacGymResponse) store.MacGymSnapshot {
-    snap := store.MacGymSnapshot{
-        RetrievedAt: time.Now(),
-        Location:    "Mac Gym",
-        Raw:         response,
-    }
-
-    for _, location := range response.Data {
-        locationName := strings.ToLower(location.LocationName)
-        
-        if strings.Contains(locationName, "badminton") || 
-           strings.Contains(locationName, "court") ||
-           strings.Contains(locationName, "gym") {
-            
-            snap.Capacity = location.MaxCapacity
-            snap.InUse = location.CurrentCount
-            snap.Details = fmt.Sprintf("%s: %d/%d in use", 
-                location.LocationName, location.CurrentCount, location.MaxCapacity)
-            
-            if location.LastUpdated != "" {
-                if t, err := time.Parse(time.RFC3339, location.LastUpdated); err == nil {
-                    snap.RetrievedAt = t
-                }
+func TestCourtFacilities(t *testing.T) {
+    facilities := []store.Facility{
+        {ID: "5633", Name: "Event Center Fitness Floor"},
+        {ID: "5634", Name: "MAC Gym"},
+        {ID: "5640", Name: "SRAC Court 1"},
+        {ID: "5641", Name: "SRAC Court 2"},
+    }
+
+    testCases := []struct {
+        name       string
+        facilities []store.Facility
+        ids        []string
+        want       []int
+    }{
+        {name: "guess by name", facilities: facilities, want: []int{1}},
+        {name: "configured IDs", facilities: facilities, ids: []string{"5640", "5641"}, want: []int{2, 3}},
+        {name: "configured name", facilities: facilities, ids: []string{"mac gym"}, want: []int{1}},
+        {name: "unknown IDs fall back to guessing", facilities: facilities, ids: []string{"9999"}, want: []int{1}},
+        {name: "first location without a match", facilities: facilities[:1], want: []int{0}},
+        {name: "no locations", want: nil},
+    }
+
+    for _, tc := range testCases {
+        t.Run(tc.name, func(t *testing.T) {
+            got := courtFacilities(tc.facilities, tc.ids)
+            if fmt.Sprint(got) != fmt.Sprint(tc.want) {
+                t.Errorf("Expected courts %v, got %v", tc.want, got)
             }
-            
-            break
-        }
+        })
+    }
+}
+
+func TestMacGymSnapshotSumsCourts(t *testing.T) {
+    updated := time.Date(2024, 1, 15, 14, 30, 0, 0, time.UTC)
+    facilities := []store.Facility{
+        {ID: "5634", Name: "MAC Gym", Count: 40, Capacity: 150},
+        {ID: "5640", Name: "SRAC Court 1", Count: 3, Capacity: 4, Updated: updated.Add(-time.Minute)},
+        {ID: "5641", Name: "SRAC Court 2", Count: 1, Capacity: 4, Updated: updated},
     }
 
-    if snap.Details == "" {
-        snap.Details = "Mac Gym status retrieved (no capacity data available)"
+    sc := New(Options{CourtLocationIDs: []string{"5640", "5641"}})
+    snap := sc.macGymSnapshot(facilities, nil, updated.Add(time.Hour))
+
+    if snap.InUse != 4 || snap.Capacity != 8 {
+        t.Errorf("Expected 4/8 in use, got %d/%d", snap.InUse, snap.Capacity)
+    }
+    if !snap.RetrievedAt.Equal(updated) {
+        t.Errorf("Expected the latest court update %v, got %v", updated, snap.RetrievedAt)
+    }
+    if snap.Details != "SRAC Court 1 + SRAC Court 2: 4/8 in use" {
+        t.Errorf("Unexpected details %q", snap.Details)
+    }
+    if len(snap.Facilities) != 3 || snap.Facilities[0].Courts || !snap.Facilities[1].Courts || !snap.Facilities[2].Courts {
+        t.Errorf("Expected every facility kept with the SRAC courts marked, got %+v", snap.Facilities)
+    }
+}
+
+func TestFetchMacGymNotModified(t *testing.T) {
+    data, err := os.ReadFile("testdata/macgym_sample.json")
+    if err != nil {
+        t.Fatalf("Failed to read test data: %v", err)
     }
 
-    return snap
+    srv := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+        if r.Header.Get("If-None-Match") == `"sample"` {
+            w.WriteHeader(http.StatusNotModified)
+            return
+        }
+        w.Header().Set("ETag", `"sample"`)
+        w.Header().Set("Content-Type", "application/json")
+        w.Write(data)
+    }))
+    defer srv.Close()
+
+    sc := New(Options{Doer: srv.Client(), MacGym: SourceOptions{URL: srv.URL}})
+
+    snap, err := sc.FetchMacGym(context.Background())
+    if err != nil {
+        t.Fatalf("First fetch: %v", err)
+    }
+    if snap.InUse != 6 || snap.Capacity != 8 {
+        t.Errorf("Unexpected snapshot %d/%d", snap.InUse, snap.Capacity)
+    }
+
+    if _, err := sc.FetchMacGym(context.Background()); !errors.Is(err, util.ErrNotModified) {
+        t.Errorf("Expected ErrNotModified on the second fetch, got %v", err)
+    }
 }
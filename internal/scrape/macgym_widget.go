@@ -0,0 +1,244 @@
+package scrape
+
+import (
+    "bytes"
+    "fmt"
+    "io"
+    "regexp"
+    "strconv"
+    "strings"
+    "time"
+
+    "github.com/PuerkitoBio/goquery"
+    "golang.org/x/net/html"
+
+    "github.com/sjsu-badminton/badminton-discord-bot/internal/store"
+)
+
+var (
+    // MAC Gym (Open), Aquatic Center (Closed)
+    widgetNameRe = regexp.MustCompile(`(?i)^(.*?)\s*\((open|closed)\)$`)
+    // Last Count: 10, Last Count: 10 / 150
+    widgetCountRe      = regexp.MustCompile(`(?i)last\s+count\s*:?\s*(\d+)(?:\s*(?:/|of)\s*(\d+))?`)
+    widgetCountLabelRe = regexp.MustCompile(`(?i)last\s+count`)
+    widgetCapacityRe   = regexp.MustCompile(`(?i)capacity\s*:?\s*(\d+)`)
+    widgetUpdatedRe    = regexp.MustCompile(`(?i)updated\s*:?\s*(.+)$`)
+)
+
+// widgetTimeFormats are the last-updated formats shown by the widget
+var widgetTimeFormats = []string{
+    "1/2/2006 3:04 PM",
+    "1/2/2006 3:04:05 PM",
+    "1/2/2006 15:04",
+}
+
+// widgetCapacityAttrs are data attributes that may carry a location's capacity
+var widgetCapacityAttrs = []string{"data-totalcapacity", "data-capacity", "data-maxcapacity"}
+
+// widgetIDAttrs are data attributes that may carry a location's ID
+var widgetIDAttrs = []string{"data-locationid", "data-location-id", "data-id"}
+
+// looksLikeHTML reports whether a response is markup rather than JSON, from
+// its content type or, when that is missing or generic, its first byte
+func looksLikeHTML(contentType string, body []byte) bool {
+    if strings.Contains(contentType, "text/html") {
+        return true
+    }
+    return bytes.HasPrefix(bytes.TrimSpace(body), []byte("<"))
+}
+
+// parseWidgetHTML extracts every location from the occupancy widget. Each
+// location is the largest element holding exactly one "Last Count", so the
+// parser doesn't depend on the widget's class names; data attributes fill in
+// anything the visible text leaves out. Locations without an ID attribute
+// use their name as ID.
+func parseWidgetHTML(body io.Reader, loc *time.Location) ([]store.Facility, error) {
+    doc, err := goquery.NewDocumentFromReader(body)
+    if err != nil {
+        return nil, fmt.Errorf("parsing widget HTML: %w", err)
+    }
+    doc.Find("head, script, style, noscript").Remove()
+
+    anchors := doc.Find("body *").FilterFunction(func(i int, s *goquery.Selection) bool {
+        return widgetCountLabelRe.MatchString(ownText(s))
+    })
+    if anchors.Length() == 0 {
+        anchors = doc.Find("[data-lastcount]")
+    }
+
+    var locations []store.Facility
+    seen := make(map[*html.Node]bool)
+    anchors.Each(func(i int, s *goquery.Selection) {
+        block := widgetBlock(s)
+        if seen[block.Get(0)] {
+            return
+        }
+        seen[block.Get(0)] = true
+
+        if l, ok := parseWidgetLocation(block, loc); ok {
+            locations = append(locations, l)
+        }
+    })
+
+    if len(locations) == 0 {
+        return nil, fmt.Errorf("no locations found in occupancy widget")
+    }
+    return locations, nil
+}
+
+// widgetBlock widens s to the largest ancestor that still describes only one
+// location
+func widgetBlock(s *goquery.Selection) *goquery.Selection {
+    block := s
+    for parent := s.Parent(); parent.Length() > 0 && goquery.NodeName(parent) != "html"; parent = parent.Parent() {
+        counts := len(widgetCountLabelRe.FindAllString(parent.Text(), -1))
+        if counts == 0 {
+            counts = parent.Find("[data-lastcount]").Length()
+        }
+        if counts > 1 {
+            break
+        }
+        block = parent
+    }
+    return block
+}
+
+// parseWidgetLocation reads one location's block. The name is the line
+// marked (Open) or (Closed), or failing that the first line that isn't a
+// count, capacity or time.
+func parseWidgetLocation(block *goquery.Selection, loc *time.Location) (store.Facility, bool) {
+    var l store.Facility
+    var firstLine string
+    haveCount := false
+
+    for _, line := range textLines(block) {
+        if m := widgetCountRe.FindStringSubmatch(line); m != nil {
+            l.Count, _ = strconv.Atoi(m[1])
+            haveCount = true
+            if m[2] != "" {
+                l.Capacity, _ = strconv.Atoi(m[2])
+            }
+            continue
+        }
+        if m := widgetUpdatedRe.FindStringSubmatch(line); m != nil {
+            l.Updated = parseWidgetTime(m[1], loc)
+            continue
+        }
+        if m := widgetCapacityRe.FindStringSubmatch(line); m != nil {
+            l.Capacity, _ = strconv.Atoi(m[1])
+            continue
+        }
+        if m := widgetNameRe.FindStringSubmatch(line); m != nil && l.Name == "" {
+            l.Name = strings.TrimSpace(m[1])
+            l.Closed = strings.EqualFold(m[2], "closed")
+            continue
+        }
+        if firstLine == "" {
+            firstLine = line
+        }
+    }
+    if l.Name == "" {
+        l.Name = firstLine
+    }
+
+    // Data attributes, on the block or the chart inside it
+    attrs := block.Find("[data-lastcount]").AddSelection(block).Filter("[data-lastcount]").First()
+    if attrs.Length() > 0 {
+        if v, ok := attrs.Attr("data-lastcount"); ok && !haveCount {
+            if n, err := strconv.Atoi(strings.TrimSpace(v)); err == nil {
+                l.Count = n
+                haveCount = true
+            }
+        }
+        if v, ok := attrs.Attr("data-isclosed"); ok && strings.EqualFold(strings.TrimSpace(v), "true") {
+            l.Closed = true
+        }
+        for _, attr := range widgetCapacityAttrs {
+            if l.Capacity > 0 {
+                break
+            }
+            if v, ok := attrs.Attr(attr); ok {
+                l.Capacity, _ = strconv.Atoi(strings.TrimSpace(v))
+            }
+        }
+    }
+
+    ids := block.Find("*").AddSelection(block)
+    for _, attr := range widgetIDAttrs {
+        if v, ok := ids.Filter("[" + attr + "]").First().Attr(attr); ok && strings.TrimSpace(v) != "" {
+            l.ID = strings.TrimSpace(v)
+            break
+        }
+    }
+    if l.ID == "" {
+        l.ID = l.Name
+    }
+
+    return l, haveCount && l.Name != ""
+}
+
+func parseWidgetTime(text string, loc *time.Location) time.Time {
+    text = strings.Join(strings.Fields(text), " ")
+    for _, format := range widgetTimeFormats {
+        if t, err := time.ParseInLocation(format, text, loc); err == nil {
+            return t
+        }
+    }
+    return time.Time{}
+}
+
+// ownText returns the text directly inside s, excluding its children
+func ownText(s *goquery.Selection) string {
+    var b strings.Builder
+    for n := s.Get(0).FirstChild; n != nil; n = n.NextSibling {
+        if n.Type == html.TextNode {
+            b.WriteString(n.Data)
+        }
+    }
+    return strings.TrimSpace(b.String())
+}
+
+// textLines returns the non-empty text nodes under s in document order, with
+// inline elements joined to their surrounding text
+func textLines(s *goquery.Selection) []string {
+    var lines []string
+    var cur strings.Builder
+    flush := func() {
+        if line := strings.Join(strings.Fields(cur.String()), " "); line != "" {
+            lines = append(lines, line)
+        }
+        cur.Reset()
+    }
+
+    var walk func(n *html.Node)
+    walk = func(n *html.Node) {
+        switch n.Type {
+        case html.TextNode:
+            cur.WriteString(n.Data)
+        case html.ElementNode:
+            inline := isInline(n.Data)
+            if !inline {
+                flush()
+            }
+            for c := n.FirstChild; c != nil; c = c.NextSibling {
+                walk(c)
+            }
+            if !inline {
+                flush()
+            }
+        }
+    }
+    for _, n := range s.Nodes {
+        walk(n)
+    }
+    flush()
+    return lines
+}
+
+func isInline(tag string) bool {
+    switch tag {
+    case "span", "strong", "b", "em", "i", "small", "a", "label", "font":
+        return true
+    }
+    return false
+}
@@ -0,0 +1,111 @@
+package scrape
+
+import (
+    "bytes"
+    _ "embed"
+    "encoding/json"
+    "fmt"
+    "os"
+
+    "github.com/andybalholm/cascadia"
+)
+
+//go:embed default_profile.json
+var defaultProfile []byte
+
+// Profile holds the CSS selectors the HTML schedule parser uses, so a markup
+// change on the fitness site only needs a new profile file
+type Profile struct {
+    // Rows are tried in order; the first that yields any event is used
+    Rows []string `json:"rows"`
+    // Title, Location, Date, Time and Link are looked up inside a row
+    Title    string `json:"title"`
+    Location string `json:"location"`
+    Date     string `json:"date"`
+    Time     string `json:"time"`
+    // Link is the registration link; its href becomes the event's
+    // RegisterURL
+    Link string `json:"link"`
+    // DayHeader matches elements labelling the day of the rows after them
+    DayHeader string `json:"dayHeader"`
+    // DefaultLocation is used for rows without a location
+    DefaultLocation string `json:"defaultLocation"`
+}
+
+// DefaultProfile returns the built-in selectors
+func DefaultProfile() *Profile {
+    var p Profile
+    if err := json.Unmarshal(defaultProfile, &p); err != nil {
+        panic(fmt.Sprintf("invalid built-in schedule profile: %v", err))
+    }
+    return &p
+}
+
+// LoadProfile reads a profile from a JSON file, or returns the built-in
+// profile when path is empty
+func LoadProfile(path string) (*Profile, error) {
+    if path == "" {
+        return DefaultProfile(), nil
+    }
+
+    data, err := os.ReadFile(path)
+    if err != nil {
+        return nil, fmt.Errorf("reading schedule profile: %w", err)
+    }
+    p, err := ParseProfile(data)
+    if err != nil {
+        return nil, fmt.Errorf("loading %s: %w", path, err)
+    }
+    return p, nil
+}
+
+// ParseProfile decodes a JSON profile. Fields it leaves out keep their
+// built-in selectors; unknown fields and invalid selectors are errors.
+func ParseProfile(data []byte) (*Profile, error) {
+    p := DefaultProfile()
+
+    dec := json.NewDecoder(bytes.NewReader(data))
+    dec.DisallowUnknownFields()
+    if err := dec.Decode(p); err != nil {
+        return nil, fmt.Errorf("decoding schedule profile: %w", err)
+    }
+
+    if err := p.validate(); err != nil {
+        return nil, err
+    }
+    return p, nil
+}
+
+// validate checks that every selector compiles, since goquery silently
+// matches nothing for an invalid one
+func (p *Profile) validate() error {
+    if len(p.Rows) == 0 {
+        return fmt.Errorf("rows: at least one selector is required")
+    }
+    for i, sel := range p.Rows {
+        if _, err := cascadia.Compile(sel); err != nil {
+            return fmt.Errorf("rows[%d]: %w", i, err)
+        }
+    }
+
+    fields := []struct {
+        name string
+        sel  string
+    }{
+        {"title", p.Title},
+        {"location", p.Location},
+        {"date", p.Date},
+        {"time", p.Time},
+        {"link", p.Link},
+        {"dayHeader", p.DayHeader},
+    }
+    for _, f := range fields {
+        if f.sel == "" {
+            continue
+        }
+        if _, err := cascadia.Compile(f.sel); err != nil {
+            return fmt.Errorf("%s: %w", f.name, err)
+        }
+    }
+    return nil
+}
@@ -0,0 +1,139 @@
+package scrape
+
+import (
+    "os"
+    "strings"
+    "testing"
+    "time"
+
+    "github.com/sjsu-badminton/badminton-discord-bot/internal/store"
+)
+
+func TestParseProfile(t *testing.T) {
+    p, err := ParseProfile([]byte(`{"rows": ["li.program"], "link": ""}`))
+    if err != nil {
+        t.Fatalf("Unexpected error: %v", err)
+    }
+
+    def := DefaultProfile()
+    if len(p.Rows) != 1 || p.Rows[0] != "li.program" {
+        t.Errorf("Expected rows [li.program], got %q", p.Rows)
+    }
+    if p.Title != def.Title || p.DayHeader != def.DayHeader || p.DefaultLocation != def.DefaultLocation {
+        t.Errorf("Expected omitted fields to keep the built-in selectors, got %+v", p)
+    }
+    if p.Link != "" {
+        t.Errorf("Expected an explicit empty link to disable it, got %q", p.Link)
+    }
+
+    testCases := []struct {
+        name    string
+        json    string
+        wantErr string
+    }{
+        {name: "not JSON", json: `rows: tr`, wantErr: "decoding"},
+        {name: "unknown field", json: `{"row": ["tr"]}`, wantErr: "unknown field"},
+        {name: "no rows", json: `{"rows": []}`, wantErr: "rows: at least one"},
+        {name: "bad row selector", json: `{"rows": ["tr", "div["]}`, wantErr: "rows[1]"},
+        {name: "bad field selector", json: `{"time": ".when >"}`, wantErr: "time:"},
+    }
+
+    for _, tc := range testCases {
+        t.Run(tc.name, func(t *testing.T) {
+            _, err := ParseProfile([]byte(tc.json))
+            if err == nil || !strings.Contains(err.Error(), tc.wantErr) {
+                t.Errorf("ParseProfile() error = %v, want one containing %q", err, tc.wantErr)
+            }
+        })
+    }
+}
+
+func TestParseHTMLScheduleProfile(t *testing.T) {
+    loc := mustLoadLA(t)
+    now := time.Date(2024, 1, 14, 12, 0, 0, 0, loc)
+
+    profile, err := ParseProfile([]byte(`{
+        "rows": ["li.program"],
+        "title": ".program-name",
+        "location": ".where",
+        "time": ".when",
+        "link": "a.signup",
+        "dayHeader": ".day-label",
+        "defaultLocation": "SRAC"
+    }`))
+    if err != nil {
+        t.Fatalf("Unexpected error: %v", err)
+    }
+
+    parse := func(p *Profile) []store.Event {
+        t.Helper()
+        f, err := os.Open("testdata/fitness_custom_markup.html")
+        if err != nil {
+            t.Fatalf("Failed to open fixture: %v", err)
+        }
+        defer f.Close()
+
+        events, err := ParseHTMLSchedule(f, HTMLOptions{Profile: p, Location: loc, Now: now, PageURL: "https://fitness.sjsu.edu/Facility/GetSchedule"})
+        if err != nil {
+            t.Fatalf("Unexpected error: %v", err)
+        }
+        return events
+    }
+
+    if events := parse(DefaultProfile()); len(events) != 0 {
+        t.Errorf("Expected the built-in profile to miss the custom markup, got %+v", events)
+    }
+
+    want := []struct {
+        title, location, register string
+        start                     time.Time
+    }{
+        {"Badminton Drop-In", "SRAC Court 3", "https://fitness.sjsu.edu/Program/Register/4411", time.Date(2024, 1, 15, 18, 0, 0, 0, loc)},
+        {"Badminton Tournament", "SRAC", "https://register.example.edu/events/77", time.Date(2024, 1, 17, 10, 0, 0, 0, loc)},
+    }
+    events := parse(profile)
+    if len(events) != len(want) {
+        t.Fatalf("Expected %d events, got %d: %+v", len(want), len(events), events)
+    }
+    for i, w := range want {
+        e := events[i]
+        if e.Title != w.title || e.Location != w.location || e.RegisterURL != w.register || !e.Start.Equal(w.start) {
+            t.Errorf("Event[%d] = %q at %q from %v, register %q; want %q at %q from %v, register %q",
+                i, e.Title, e.Location, e.Start, e.RegisterURL, w.title, w.location, w.start, w.register)
+        }
+    }
+}
+
+func TestRegisterURL(t *testing.T) {
+    loc := mustLoadLA(t)
+    now := time.Date(2024, 1, 14, 12, 0, 0, 0, loc)
+
+    testCases := []struct {
+        name string
+        link string
+        want string
+    }{
+        {name: "relative", link: `<a href="Register?id=5">Register</a>`, want: "https://fitness.sjsu.edu/Facility/Register?id=5"},
+        {name: "class", link: `<a class="register" href="https://example.edu/e/1">Sign up</a>`, want: "https://example.edu/e/1"},
+        {name: "script link", link: `<a class="register" href="javascript:void(0)">Sign up</a>`},
+        {name: "no link", link: `<a href="/Facility/Info">Info</a>`},
+    }
+
+    for _, tc := range testCases {
+        t.Run(tc.name, func(t *testing.T) {
+            page := `<h2>Monday, January 15, 2024</h2><div class="event"><h3 class="title">Badminton</h3>` +
+                `<span class="time">6:00 PM - 8:00 PM</span>` + tc.link + `</div>`
+
+            events, err := ParseHTMLSchedule(strings.NewReader(page), HTMLOptions{Location: loc, Now: now})
+            if err != nil {
+                t.Fatalf("Unexpected error: %v", err)
+            }
+            if len(events) != 1 {
+                t.Fatalf("Expected 1 event, got %d", len(events))
+            }
+            if events[0].RegisterURL != tc.want {
+                t.Errorf("Expected register URL %q, got %q", tc.want, events[0].RegisterURL)
+            }
+        })
+    }
+}
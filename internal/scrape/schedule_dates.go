@@ -0,0 +1,332 @@
+package scrape
+
+import (
+    "regexp"
+    "strconv"
+    "strings"
+    "time"
+
+    "github.com/PuerkitoBio/goquery"
+    "golang.org/x/net/html"
+
+    "github.com/sjsu-badminton/badminton-discord-bot/internal/util"
+)
+
+var (
+    // 9:00 AM - 10:30 AM, 9-10:30pm, 18:00 to 20:00, 11 - 1 PM
+    timeRangeRe = regexp.MustCompile(`(?i)\b(\d{1,2})(?::(\d{2}))?\s*([ap])?\.?m?\.?\s*(?:-|–|—|to)\s*(\d{1,2})(?::(\d{2}))?\s*([ap])?\.?m?\.?`)
+
+    isoDateRe     = regexp.MustCompile(`\b(\d{4})-(\d{1,2})-(\d{1,2})\b`)
+    numericDateRe = regexp.MustCompile(`\b(\d{1,2})/(\d{1,2})(?:/(\d{2}|\d{4}))?\b`)
+    monthDateRe   = regexp.MustCompile(`(?i)\b(jan|feb|mar|apr|may|jun|jul|aug|sep|oct|nov|dec)[a-z]*\.?\s+(\d{1,2})(?:st|nd|rd|th)?\b(?:,?\s+(\d{4}))?`)
+)
+
+// clock is a time of day parsed from schedule text
+type clock struct {
+    hour, minute int
+    meridiem     byte // 'a', 'p' or 0 when absent
+}
+
+func (c clock) hour24() int {
+    switch {
+    case c.meridiem == 'p' && c.hour != 12:
+        return c.hour + 12
+    case c.meridiem == 'a' && c.hour == 12:
+        return 0
+    default:
+        return c.hour
+    }
+}
+
+func (c clock) on(day time.Time, loc *time.Location) time.Time {
+    return time.Date(day.Year(), day.Month(), day.Day(), c.hour24(), c.minute, 0, 0, loc)
+}
+
+func flip(m byte) byte {
+    if m == 'a' {
+        return 'p'
+    }
+    return 'a'
+}
+
+// findTimeRange returns the first time range in text, or "" if there is none
+func findTimeRange(text string) string {
+    for _, m := range timeRangeRe.FindAllStringSubmatch(text, -1) {
+        if _, _, ok := matchClocks(m); ok {
+            return m[0]
+        }
+    }
+    return ""
+}
+
+// matchClocks validates a timeRangeRe match. A bare "15 - 2" is rejected
+// because without a colon or AM/PM it's more likely a date or a count.
+func matchClocks(m []string) (clock, clock, bool) {
+    if m[2] == "" && m[3] == "" && m[5] == "" && m[6] == "" {
+        return clock{}, clock{}, false
+    }
+
+    start, ok1 := newClock(m[1], m[2], m[3])
+    end, ok2 := newClock(m[4], m[5], m[6])
+    return start, end, ok1 && ok2
+}
+
+func newClock(hour, minute, meridiem string) (clock, bool) {
+    h, err := strconv.Atoi(hour)
+    if err != nil {
+        return clock{}, false
+    }
+
+    mm := 0
+    if minute != "" {
+        if mm, err = strconv.Atoi(minute); err != nil || mm > 59 {
+            return clock{}, false
+        }
+    }
+
+    c := clock{hour: h, minute: mm}
+    if meridiem != "" {
+        c.meridiem = strings.ToLower(meridiem)[0]
+        if h < 1 || h > 12 {
+            return clock{}, false
+        }
+    } else if h > 23 {
+        return clock{}, false
+    }
+    return c, true
+}
+
+// parseTimeRange parses a range like "9:00 AM - 10:30 AM" or "9:00-10:30" on
+// the given day. A missing AM/PM is taken from the other end of the range,
+// and ranges that end at or before they start are treated as overnight.
+func parseTimeRange(timeText string, day time.Time, loc *time.Location) (time.Time, time.Time, bool) {
+    if timeText == "" || day.IsZero() {
+        return time.Time{}, time.Time{}, false
+    }
+
+    for _, m := range timeRangeRe.FindAllStringSubmatch(timeText, -1) {
+        start, end, ok := matchClocks(m)
+        if !ok {
+            continue
+        }
+
+        switch {
+        case start.meridiem == 0 && end.meridiem != 0:
+            // "11 - 1 PM" means 11 AM, "9 - 11 PM" means 9 PM
+            start.meridiem = end.meridiem
+            if start.hour24() > end.hour24() {
+                start.meridiem = flip(end.meridiem)
+            }
+        case end.meridiem == 0 && start.meridiem != 0 && end.hour <= 12:
+            end.meridiem = start.meridiem
+            if end.hour24() <= start.hour24() {
+                end.meridiem = flip(start.meridiem)
+            }
+        }
+
+        startTime := start.on(day, loc)
+        endTime := end.on(day, loc)
+        if !endTime.After(startTime) {
+            endTime = endTime.AddDate(0, 0, 1)
+        }
+        return startTime, endTime, true
+    }
+
+    return time.Time{}, time.Time{}, false
+}
+
+// parseScheduleDate finds a calendar date in text such as "Monday, Jan 15",
+// "1/15/2024" or "2024-01-15". Dates without a year resolve to the year that
+// puts them closest to now. Text consisting of only a weekday name resolves
+// to that weekday's next occurrence, since schedules list the coming week.
+func parseScheduleDate(text string, now time.Time, loc *time.Location) (time.Time, bool) {
+    if m := isoDateRe.FindStringSubmatch(text); m != nil {
+        return makeDate(atoi(m[1]), atoi(m[2]), atoi(m[3]), loc)
+    }
+
+    if m := monthDateRe.FindStringSubmatch(text); m != nil {
+        month := monthNumber(m[1])
+        if m[3] != "" {
+            return makeDate(atoi(m[3]), month, atoi(m[2]), loc)
+        }
+        return nearestYear(month, atoi(m[2]), now, loc)
+    }
+
+    if m := numericDateRe.FindStringSubmatch(text); m != nil {
+        month, day := atoi(m[1]), atoi(m[2])
+        switch len(m[3]) {
+        case 4:
+            return makeDate(atoi(m[3]), month, day, loc)
+        case 2:
+            return makeDate(2000+atoi(m[3]), month, day, loc)
+        }
+        return nearestYear(month, day, now, loc)
+    }
+
+    name := strings.Trim(strings.TrimSpace(text), ".,:")
+    if wd, ok := util.ParseWeekday(name); ok && !strings.ContainsAny(name, " \t") {
+        today := util.StartOfDay(now.In(loc))
+        return today.AddDate(0, 0, (int(wd)-int(today.Weekday())+7)%7), true
+    }
+
+    return time.Time{}, false
+}
+
+func atoi(s string) int {
+    n, _ := strconv.Atoi(s)
+    return n
+}
+
+func monthNumber(prefix string) int {
+    months := []string{"jan", "feb", "mar", "apr", "may", "jun", "jul", "aug", "sep", "oct", "nov", "dec"}
+    prefix = strings.ToLower(prefix)
+    for i, m := range months {
+        if m == prefix {
+            return i + 1
+        }
+    }
+    return 0
+}
+
+// makeDate builds a date, rejecting out-of-range values instead of normalizing them
+func makeDate(year, month, day int, loc *time.Location) (time.Time, bool) {
+    if month < 1 || month > 12 || day < 1 {
+        return time.Time{}, false
+    }
+    d := time.Date(year, time.Month(month), day, 0, 0, 0, 0, loc)
+    if d.Month() != time.Month(month) || d.Day() != day {
+        return time.Time{}, false
+    }
+    return d, true
+}
+
+// nearestYear resolves a month and day to the year closest to now
+func nearestYear(month, day int, now time.Time, loc *time.Location) (time.Time, bool) {
+    var best time.Time
+    found := false
+    for _, y := range []int{now.Year() - 1, now.Year(), now.Year() + 1} {
+        d, ok := makeDate(y, month, day, loc)
+        if !ok {
+            continue
+        }
+        if !found || absDuration(d.Sub(now)) < absDuration(best.Sub(now)) {
+            best, found = d, true
+        }
+    }
+    return best, found
+}
+
+func absDuration(d time.Duration) time.Duration {
+    if d < 0 {
+        return -d
+    }
+    return d
+}
+
+// dayHeader is an element labelling the day of the entries that follow it
+type dayHeader struct {
+    pos  int
+    date time.Time
+}
+
+// scheduleDoc resolves which day each schedule entry in a document belongs to
+type scheduleDoc struct {
+    loc     *time.Location
+    now     time.Time
+    dateSel string // date inside an entry
+    order   map[*html.Node]int
+    headers []dayHeader // in document order
+}
+
+func newScheduleDoc(doc *goquery.Document, loc *time.Location, now time.Time, profile *Profile) *scheduleDoc {
+    p := &scheduleDoc{
+        loc:     loc,
+        now:     now,
+        dateSel: profile.Date,
+        order:   make(map[*html.Node]int),
+    }
+
+    doc.Find("*").Each(func(i int, s *goquery.Selection) {
+        p.order[s.Get(0)] = i
+    })
+
+    findAll(doc.Selection, profile.DayHeader).Each(func(i int, s *goquery.Selection) {
+        if d, ok := p.ownDate(s); ok {
+            p.headers = append(p.headers, dayHeader{pos: p.order[s.Get(0)], date: d})
+        }
+    })
+
+    return p
+}
+
+// ownDate parses the date carried by the element itself
+func (p *scheduleDoc) ownDate(s *goquery.Selection) (time.Time, bool) {
+    if v, ok := s.Attr("data-date"); ok {
+        if d, ok := parseScheduleDate(v, p.now, p.loc); ok {
+            return d, true
+        }
+    }
+    return parseScheduleDate(strings.TrimSpace(s.Text()), p.now, p.loc)
+}
+
+// dateFor finds the day a schedule entry belongs to, in order of preference:
+// a date on the entry itself, the header of the table column it sits in, or
+// the nearest day header preceding it in the document.
+func (p *scheduleDoc) dateFor(s *goquery.Selection) (time.Time, bool) {
+    if v, ok := s.Attr("data-date"); ok {
+        if d, ok := parseScheduleDate(v, p.now, p.loc); ok {
+            return d, true
+        }
+    }
+
+    if dateEl := findAll(s, p.dateSel).First(); dateEl.Length() > 0 {
+        if d, ok := p.ownDate(dateEl); ok {
+            return d, true
+        }
+    }
+
+    if d, ok := p.columnDate(s); ok {
+        return d, true
+    }
+
+    pos, ok := p.order[s.Get(0)]
+    if !ok {
+        return time.Time{}, false
+    }
+    for i := len(p.headers) - 1; i >= 0; i-- {
+        if p.headers[i].pos < pos {
+            return p.headers[i].date, true
+        }
+    }
+    return time.Time{}, false
+}
+
+// columnDate returns the date in the header of the table column containing
+// s, for weekly grids where each column is a day
+func (p *scheduleDoc) columnDate(s *goquery.Selection) (time.Time, bool) {
+    if goquery.NodeName(s) == "tr" {
+        return time.Time{}, false
+    }
+
+    cell := s.Closest("td")
+    if cell.Length() == 0 {
+        return time.Time{}, false
+    }
+
+    table := cell.Closest("table")
+    header := table.Find("thead tr").First()
+    if header.Length() == 0 {
+        header = table.Find("tr").First()
+    }
+
+    cells := header.Children()
+    if cells.Length() < 2 || cells.Length() != cells.Filter("th").Length() {
+        return time.Time{}, false
+    }
+
+    idx := cell.Index()
+    if idx >= cells.Length() {
+        return time.Time{}, false
+    }
+    return p.ownDate(cells.Eq(idx))
+}
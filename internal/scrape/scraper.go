@@ -0,0 +1,125 @@
+package scrape
+
+import (
+    "context"
+    "crypto/sha256"
+    "net/http"
+    "net/http/cookiejar"
+    "sync"
+    "time"
+
+    "github.com/sjsu-badminton/badminton-discord-bot/internal/classify"
+    "github.com/sjsu-badminton/badminton-discord-bot/internal/util"
+)
+
+const (
+    defaultMacGymTimeout  = 30 * time.Second
+    defaultFitnessTimeout = 60 * time.Second
+)
+
+// SourceOptions configures how one source is fetched
+type SourceOptions struct {
+    URL string
+    // Timeout bounds a whole fetch, including retries; a ranged fitness
+    // fetch applies it to each page
+    Timeout time.Duration
+    // Header is sent with every request to the source
+    Header http.Header
+}
+
+// Options configures a Scraper
+type Options struct {
+    // Doer sends requests; nil uses an http.Client with a 10 second timeout
+    Doer util.Doer
+    // UserAgent defaults to util.DefaultUserAgent
+    UserAgent string
+    // Location interprets times shown without a zone; defaults to time.Local
+    Location *time.Location
+
+    // Breaker configures the circuit breakers shared by every source
+    Breaker util.BreakerOptions
+
+    // CourtLocationIDs selects the Mac Gym locations, by ID or name, whose
+    // counts make up badminton court occupancy; empty guesses by name
+    CourtLocationIDs []string
+
+    // Rules decide which schedule entries are badminton events; nil uses
+    // classify.Default
+    Rules *classify.Rules
+    // Profile holds the fitness schedule HTML selectors; nil uses
+    // DefaultProfile
+    Profile *Profile
+
+    MacGym  SourceOptions
+    Fitness SourceOptions
+    // FitnessQuery fetches the fitness schedule by date range
+    FitnessQuery ScheduleQuery
+}
+
+// Scraper fetches Mac Gym occupancy and the fitness schedule. Each source has
+// its own HTTP client so conditional request caches and headers stay apart,
+// while circuit breakers are kept per host across both.
+type Scraper struct {
+    loc         *time.Location
+    courtIDs    []string
+    rules       *classify.Rules
+    profile     *Profile
+    breakers    *util.Breakers
+    macGym      *util.Client
+    macGymOpts  SourceOptions
+    fitness     *util.Client
+    fitnessOpts SourceOptions
+    query       ScheduleQuery
+
+    mu           sync.Mutex
+    scheduleHash [sha256.Size]byte // of the last ranged fetch's pages
+}
+
+// New returns a Scraper for the sources in opts
+func New(opts Options) *Scraper {
+    if opts.MacGym.Timeout <= 0 {
+        opts.MacGym.Timeout = defaultMacGymTimeout
+    }
+    if opts.Fitness.Timeout <= 0 {
+        opts.Fitness.Timeout = defaultFitnessTimeout
+    }
+
+    if opts.Location == nil {
+        opts.Location = time.Local
+    }
+
+    if opts.Rules == nil {
+        opts.Rules = classify.Default()
+    }
+    if opts.Profile == nil {
+        opts.Profile = DefaultProfile()
+    }
+
+    // Session and anti-forgery cookies for the fitness site; New never fails
+    // without a PublicSuffixList
+    jar, _ := cookiejar.New(nil)
+
+    breakers := util.NewBreakers(opts.Breaker)
+    return &Scraper{
+        loc:         opts.Location,
+        courtIDs:    opts.CourtLocationIDs,
+        rules:       opts.Rules,
+        profile:     opts.Profile,
+        breakers:    breakers,
+        macGym:      util.NewClient(opts.Doer, util.ClientOptions{UserAgent: opts.UserAgent, Header: opts.MacGym.Header, Breakers: breakers}),
+        macGymOpts:  opts.MacGym,
+        fitness:     util.NewClient(opts.Doer, util.ClientOptions{UserAgent: opts.UserAgent, Header: opts.Fitness.Header, Breakers: breakers, Jar: jar}),
+        fitnessOpts: opts.Fitness,
+        query:       opts.FitnessQuery.withDefaults(),
+    }
+}
+
+// Breakers returns the circuit breaker state of every host scraped so far
+func (sc *Scraper) Breakers() []util.BreakerStatus {
+    return sc.breakers.Statuses()
+}
+
+// withTimeout bounds ctx by a source's timeout
+func withTimeout(ctx context.Context, opts SourceOptions) (context.Context, context.CancelFunc) {
+    return context.WithTimeout(ctx, opts.Timeout)
+}
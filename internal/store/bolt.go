@@ -0,0 +1,629 @@
+package store
+
+import (
+    "bytes"
+    "encoding/binary"
+    "encoding/json"
+    "fmt"
+    "log/slog"
+    "os"
+    "path/filepath"
+    "time"
+
+    bolt "go.etcd.io/bbolt"
+)
+
+var (
+    bucketMeta          = []byte("meta")
+    bucketMac           = []byte("mac")
+    bucketEvents        = []byte("events")
+    bucketSubs          = []byte("subs")
+    bucketHistory       = []byte("history")
+    bucketSources       = []byte("sources")
+    bucketReminders     = []byte("reminders")
+    bucketRemindersSent = []byte("reminders_sent")
+    bucketQuietHours    = []byte("quiet_hours")
+    bucketDigests       = []byte("digests")
+    bucketChannels      = []byte("alert_channels")
+
+    keySchemaVersion = []byte("schema_version")
+    keyLatest        = []byte("latest")
+)
+
+// migrations are applied in order; the schema version is the number applied.
+// Append new migrations to the end and never edit released ones.
+var migrations = []func(tx *bolt.Tx) error{
+    // 1: initial buckets
+    func(tx *bolt.Tx) error {
+        for _, b := range [][]byte{bucketMac, bucketEvents, bucketSubs} {
+            if _, err := tx.CreateBucketIfNotExists(b); err != nil {
+                return err
+            }
+        }
+        return nil
+    },
+    // 2: occupancy history keyed by big-endian unix nanoseconds
+    func(tx *bolt.Tx) error {
+        _, err := tx.CreateBucketIfNotExists(bucketHistory)
+        return err
+    },
+    // 3: per-source last refresh times
+    func(tx *bolt.Tx) error {
+        _, err := tx.CreateBucketIfNotExists(bucketSources)
+        return err
+    },
+    // 4: event reminder opt-ins and reminders already sent
+    func(tx *bolt.Tx) error {
+        for _, b := range [][]byte{bucketReminders, bucketRemindersSent} {
+            if _, err := tx.CreateBucketIfNotExists(b); err != nil {
+                return err
+            }
+        }
+        return nil
+    },
+    // 5: subscriptions become records with a direction and arming state,
+    // replacing bare thresholds
+    func(tx *bolt.Tx) error {
+        b := tx.Bucket(bucketSubs)
+        subs := make(map[string]Subscription)
+        err := b.ForEach(func(k, v []byte) error {
+            var threshold int
+            if err := json.Unmarshal(v, &threshold); err != nil {
+                return nil // already a record
+            }
+            subs[string(k)] = NewSubscription(string(k), threshold, Above)
+            return nil
+        })
+        if err != nil {
+            return err
+        }
+        for userID, sub := range subs {
+            data, err := json.Marshal(sub)
+            if err != nil {
+                return err
+            }
+            if err := b.Put([]byte(userID), data); err != nil {
+                return err
+            }
+        }
+        return nil
+    },
+    // 6: per-user quiet hours
+    func(tx *bolt.Tx) error {
+        _, err := tx.CreateBucketIfNotExists(bucketQuietHours)
+        return err
+    },
+    // 7: digest frequencies
+    func(tx *bolt.Tx) error {
+        _, err := tx.CreateBucketIfNotExists(bucketDigests)
+        return err
+    },
+    // 8: per-guild alert channels
+    func(tx *bolt.Tx) error {
+        _, err := tx.CreateBucketIfNotExists(bucketChannels)
+        return err
+    },
+}
+
+// BoltStore is a file-backed store. It keeps the working set in an embedded
+// MemoryStore and writes every mutation through to a bbolt database.
+type BoltStore struct {
+    *MemoryStore
+    db *bolt.DB
+}
+
+// OpenBolt opens (or creates) the database at path, migrates it and loads its
+// contents. Occupancy history older than retention is not loaded.
+func OpenBolt(path string, retention time.Duration) (*BoltStore, error) {
+    if dir := filepath.Dir(path); dir != "" {
+        if err := os.MkdirAll(dir, 0o755); err != nil {
+            return nil, fmt.Errorf("creating store directory: %w", err)
+        }
+    }
+
+    db, err := bolt.Open(path, 0o600, &bolt.Options{Timeout: 5 * time.Second})
+    if err != nil {
+        return nil, fmt.Errorf("opening bolt database: %w", err)
+    }
+
+    s := &BoltStore{
+        MemoryStore: NewMemoryStore(),
+        db:          db,
+    }
+    s.SetHistoryRetention(retention)
+
+    if err := s.migrate(); err != nil {
+        db.Close()
+        return nil, fmt.Errorf("migrating bolt database: %w", err)
+    }
+
+    if err := s.load(); err != nil {
+        db.Close()
+        return nil, fmt.Errorf("loading bolt database: %w", err)
+    }
+
+    slog.Info("Opened bolt store",
+        "path", path,
+        "events", s.GetEventCount(),
+        "subscribers", s.GetSubscriberCount(),
+        "historyReadings", len(s.history))
+
+    return s, nil
+}
+
+// SchemaVersion returns the number of migrations applied to the database
+func (s *BoltStore) SchemaVersion() (int, error) {
+    var version int
+    err := s.db.View(func(tx *bolt.Tx) error {
+        version = schemaVersion(tx)
+        return nil
+    })
+    return version, err
+}
+
+func schemaVersion(tx *bolt.Tx) int {
+    b := tx.Bucket(bucketMeta)
+    if b == nil {
+        return 0
+    }
+    v := b.Get(keySchemaVersion)
+    if len(v) != 8 {
+        return 0
+    }
+    return int(binary.BigEndian.Uint64(v))
+}
+
+func (s *BoltStore) migrate() error {
+    return s.db.Update(func(tx *bolt.Tx) error {
+        meta, err := tx.CreateBucketIfNotExists(bucketMeta)
+        if err != nil {
+            return err
+        }
+
+        current := schemaVersion(tx)
+        if current > len(migrations) {
+            return fmt.Errorf("database schema version %d is newer than supported version %d", current, len(migrations))
+        }
+
+        for i := current; i < len(migrations); i++ {
+            if err := migrations[i](tx); err != nil {
+                return fmt.Errorf("migration %d: %w", i+1, err)
+            }
+            slog.Info("Applied store migration", "version", i+1)
+        }
+
+        v := make([]byte, 8)
+        binary.BigEndian.PutUint64(v, uint64(len(migrations)))
+        return meta.Put(keySchemaVersion, v)
+    })
+}
+
+// load fills the in-memory working set from the database without firing alerts
+func (s *BoltStore) load() error {
+    m := s.MemoryStore
+    m.mu.Lock()
+    defer m.mu.Unlock()
+
+    return s.db.View(func(tx *bolt.Tx) error {
+        if v := tx.Bucket(bucketMac).Get(keyLatest); v != nil {
+            if err := json.Unmarshal(v, &m.mac); err != nil {
+                return fmt.Errorf("decoding snapshot: %w", err)
+            }
+        }
+
+        err := tx.Bucket(bucketEvents).ForEach(func(k, v []byte) error {
+            var e Event
+            if err := json.Unmarshal(v, &e); err != nil {
+                return fmt.Errorf("decoding event %s: %w", k, err)
+            }
+            m.events[e.ID] = e
+            return nil
+        })
+        if err != nil {
+            return err
+        }
+
+        err = tx.Bucket(bucketSubs).ForEach(func(k, v []byte) error {
+            var sub Subscription
+            if err := json.Unmarshal(v, &sub); err != nil {
+                return fmt.Errorf("decoding subscription %s: %w", k, err)
+            }
+            m.subs[string(k)] = sub
+            return nil
+        })
+        if err != nil {
+            return err
+        }
+
+        err = tx.Bucket(bucketSources).ForEach(func(k, v []byte) error {
+            var at time.Time
+            if err := json.Unmarshal(v, &at); err != nil {
+                return fmt.Errorf("decoding source %s: %w", k, err)
+            }
+            m.sources[string(k)] = at
+            return nil
+        })
+        if err != nil {
+            return err
+        }
+
+        err = tx.Bucket(bucketReminders).ForEach(func(k, v []byte) error {
+            var lead time.Duration
+            if err := json.Unmarshal(v, &lead); err != nil {
+                return fmt.Errorf("decoding reminder %s: %w", k, err)
+            }
+            m.reminders[string(k)] = lead
+            return nil
+        })
+        if err != nil {
+            return err
+        }
+
+        err = tx.Bucket(bucketRemindersSent).ForEach(func(k, v []byte) error {
+            var start time.Time
+            if err := json.Unmarshal(v, &start); err != nil {
+                return fmt.Errorf("decoding sent reminder %s: %w", k, err)
+            }
+            m.remindersSent[string(k)] = start
+            return nil
+        })
+        if err != nil {
+            return err
+        }
+
+        err = tx.Bucket(bucketQuietHours).ForEach(func(k, v []byte) error {
+            var r ClockRange
+            if err := json.Unmarshal(v, &r); err != nil {
+                return fmt.Errorf("decoding quiet hours %s: %w", k, err)
+            }
+            m.quiet[string(k)] = r
+            return nil
+        })
+        if err != nil {
+            return err
+        }
+
+        err = tx.Bucket(bucketDigests).ForEach(func(k, v []byte) error {
+            m.digests[string(k)] = DigestFrequency(v)
+            return nil
+        })
+        if err != nil {
+            return err
+        }
+
+        err = tx.Bucket(bucketChannels).ForEach(func(k, v []byte) error {
+            m.channels[string(k)] = string(v)
+            return nil
+        })
+        if err != nil {
+            return err
+        }
+
+        // Keys are time-ordered, so readings load oldest first
+        return tx.Bucket(bucketHistory).ForEach(func(k, v []byte) error {
+            var snap MacGymSnapshot
+            if err := json.Unmarshal(v, &snap); err != nil {
+                return fmt.Errorf("decoding history reading: %w", err)
+            }
+            m.appendHistory(snap)
+            return nil
+        })
+    })
+}
+
+// historyKey encodes t so that byte order matches time order
+func historyKey(t time.Time) []byte {
+    k := make([]byte, 8)
+    binary.BigEndian.PutUint64(k, uint64(t.UnixNano()))
+    return k
+}
+
+// put JSON-encodes v under key in bucket
+func (s *BoltStore) put(bucket, key []byte, v any) error {
+    data, err := json.Marshal(v)
+    if err != nil {
+        return fmt.Errorf("encoding %s/%s: %w", bucket, key, err)
+    }
+    return s.db.Update(func(tx *bolt.Tx) error {
+        return tx.Bucket(bucket).Put(key, data)
+    })
+}
+
+// SetMac updates the snapshot and persists it along with its history entry
+// and any subscription state changes
+func (s *BoltStore) SetMac(snap MacGymSnapshot) {
+    recorded, changed := s.MemoryStore.setMac(snap)
+    s.putSubscriptions(changed)
+
+    // Raw payloads are for debugging only and can be large; don't persist them
+    snap.Raw = nil
+    data, err := json.Marshal(snap)
+    if err != nil {
+        slog.Error("Failed to encode Mac Gym snapshot", "error", err)
+        return
+    }
+
+    // History only needs the court totals, matching what appendHistory keeps
+    reading := snap
+    reading.Facilities = nil
+    readingData, err := json.Marshal(reading)
+    if err != nil {
+        slog.Error("Failed to encode Mac Gym reading", "error", err)
+        return
+    }
+
+    // Keep what the memory store kept: its oldest reading reflects both the
+    // retention window and the entry cap
+    s.mu.RLock()
+    cutoff := snap.RetrievedAt.Add(-s.retention)
+    if len(s.history) > 0 && s.history[0].RetrievedAt.After(cutoff) {
+        cutoff = s.history[0].RetrievedAt
+    }
+    s.mu.RUnlock()
+
+    err = s.db.Update(func(tx *bolt.Tx) error {
+        if err := tx.Bucket(bucketMac).Put(keyLatest, data); err != nil {
+            return err
+        }
+        if !recorded {
+            return nil
+        }
+
+        h := tx.Bucket(bucketHistory)
+        if err := h.Put(historyKey(snap.RetrievedAt), readingData); err != nil {
+            return err
+        }
+
+        // Prune readings that have aged out of the retention window or fall
+        // beyond the entry cap. Keys are collected first because deleting
+        // while iterating skips entries.
+        var expired [][]byte
+        end := historyKey(cutoff)
+        c := h.Cursor()
+        for k, _ := c.First(); k != nil && bytes.Compare(k, end) < 0; k, _ = c.Next() {
+            expired = append(expired, append([]byte(nil), k...))
+        }
+        for _, k := range expired {
+            if err := h.Delete(k); err != nil {
+                return err
+            }
+        }
+        return nil
+    })
+    if err != nil {
+        slog.Error("Failed to persist Mac Gym snapshot", "error", err)
+    }
+}
+
+// UpsertEvents updates the events and persists them
+func (s *BoltStore) UpsertEvents(es []Event) {
+    s.MemoryStore.UpsertEvents(es)
+
+    if err := s.putEvents(es); err != nil {
+        slog.Error("Failed to persist events", "count", len(es), "error", err)
+    }
+}
+
+// ApplyRefresh merges a source listing and persists every event it touched,
+// deleting those replaced by a moved event
+func (s *BoltStore) ApplyRefresh(r Refresh) ChangeSet {
+    changes, touched, removed := s.MemoryStore.applyRefresh(r)
+
+    if err := s.putEvents(touched); err != nil {
+        slog.Error("Failed to persist refreshed events", "source", r.Source, "error", err)
+    }
+    if err := s.deleteEvents(removed); err != nil {
+        slog.Error("Failed to delete moved events", "source", r.Source, "error", err)
+    }
+    if err := s.put(bucketSources, []byte(r.Source), r.At); err != nil {
+        slog.Error("Failed to persist source refresh time", "source", r.Source, "error", err)
+    }
+
+    return changes
+}
+
+// PruneEvents removes ended events from memory and disk
+func (s *BoltStore) PruneEvents(now time.Time, retention time.Duration) []Event {
+    pruned := s.MemoryStore.PruneEvents(now, retention)
+    if len(pruned) == 0 {
+        return pruned
+    }
+
+    ids := make([]string, len(pruned))
+    for i, e := range pruned {
+        ids[i] = e.ID
+    }
+    if err := s.deleteEvents(ids); err != nil {
+        slog.Error("Failed to delete pruned events", "count", len(pruned), "error", err)
+    }
+
+    return pruned
+}
+
+// deleteEvents removes events from the events bucket in one transaction
+func (s *BoltStore) deleteEvents(ids []string) error {
+    if len(ids) == 0 {
+        return nil
+    }
+    return s.db.Update(func(tx *bolt.Tx) error {
+        b := tx.Bucket(bucketEvents)
+        for _, id := range ids {
+            if err := b.Delete([]byte(id)); err != nil {
+                return err
+            }
+        }
+        return nil
+    })
+}
+
+// putEvents writes events to the events bucket in one transaction
+func (s *BoltStore) putEvents(es []Event) error {
+    return s.db.Update(func(tx *bolt.Tx) error {
+        b := tx.Bucket(bucketEvents)
+        for _, e := range es {
+            data, err := json.Marshal(e)
+            if err != nil {
+                return fmt.Errorf("encoding event %s: %w", e.ID, err)
+            }
+            if err := b.Put([]byte(e.ID), data); err != nil {
+                return err
+            }
+        }
+        return nil
+    })
+}
+
+// Subscribe adds a subscription and persists it
+func (s *BoltStore) Subscribe(sub Subscription) {
+    // Persist the stored copy, which may have been disarmed
+    s.putSubscriptions([]Subscription{s.MemoryStore.subscribe(sub)})
+}
+
+// SetQuietHours sets or clears a user's quiet hours and persists it
+func (s *BoltStore) SetQuietHours(userID string, r ClockRange) {
+    s.MemoryStore.SetQuietHours(userID, r)
+
+    var err error
+    if r.IsZero() {
+        err = s.db.Update(func(tx *bolt.Tx) error {
+            return tx.Bucket(bucketQuietHours).Delete([]byte(userID))
+        })
+    } else {
+        err = s.put(bucketQuietHours, []byte(userID), r)
+    }
+    if err != nil {
+        slog.Error("Failed to persist quiet hours", "userID", userID, "error", err)
+    }
+}
+
+// SetDigest sets or clears a user's digest frequency and persists it
+func (s *BoltStore) SetDigest(userID string, f DigestFrequency) {
+    s.MemoryStore.SetDigest(userID, f)
+
+    err := s.db.Update(func(tx *bolt.Tx) error {
+        b := tx.Bucket(bucketDigests)
+        if f == "" {
+            return b.Delete([]byte(userID))
+        }
+        return b.Put([]byte(userID), []byte(f))
+    })
+    if err != nil {
+        slog.Error("Failed to persist digest frequency", "userID", userID, "error", err)
+    }
+}
+
+// putSubscriptions writes subscriptions to the subs bucket in one transaction
+func (s *BoltStore) putSubscriptions(subs []Subscription) {
+    if len(subs) == 0 {
+        return
+    }
+
+    err := s.db.Update(func(tx *bolt.Tx) error {
+        b := tx.Bucket(bucketSubs)
+        for _, sub := range subs {
+            data, err := json.Marshal(sub)
+            if err != nil {
+                return fmt.Errorf("encoding subscription %s: %w", sub.Key(), err)
+            }
+            if err := b.Put([]byte(sub.Key()), data); err != nil {
+                return err
+            }
+        }
+        return nil
+    })
+    if err != nil {
+        slog.Error("Failed to persist subscriptions", "count", len(subs), "error", err)
+    }
+}
+
+// Unsubscribe removes a subscription and deletes it from disk
+func (s *BoltStore) Unsubscribe(key string) {
+    s.MemoryStore.Unsubscribe(key)
+
+    err := s.db.Update(func(tx *bolt.Tx) error {
+        return tx.Bucket(bucketSubs).Delete([]byte(key))
+    })
+    if err != nil {
+        slog.Error("Failed to delete subscription", "key", key, "error", err)
+    }
+}
+
+// SetAlertChannel sets or clears a guild's alert channel and persists it
+func (s *BoltStore) SetAlertChannel(guildID, channelID string) {
+    s.MemoryStore.SetAlertChannel(guildID, channelID)
+
+    err := s.db.Update(func(tx *bolt.Tx) error {
+        b := tx.Bucket(bucketChannels)
+        if channelID == "" {
+            return b.Delete([]byte(guildID))
+        }
+        return b.Put([]byte(guildID), []byte(channelID))
+    })
+    if err != nil {
+        slog.Error("Failed to persist guild alert channel", "guildID", guildID, "error", err)
+    }
+}
+
+// SetReminderLead opts a user in to reminders and persists it
+func (s *BoltStore) SetReminderLead(userID string, lead time.Duration) {
+    s.MemoryStore.SetReminderLead(userID, lead)
+
+    if err := s.put(bucketReminders, []byte(userID), lead); err != nil {
+        slog.Error("Failed to persist reminder opt-in", "userID", userID, "error", err)
+    }
+}
+
+// ClearReminders opts a user out of reminders and deletes it from disk
+func (s *BoltStore) ClearReminders(userID string) {
+    s.MemoryStore.ClearReminders(userID)
+
+    err := s.db.Update(func(tx *bolt.Tx) error {
+        return tx.Bucket(bucketReminders).Delete([]byte(userID))
+    })
+    if err != nil {
+        slog.Error("Failed to delete reminder opt-in", "userID", userID, "error", err)
+    }
+}
+
+// DueReminders claims due reminders and persists the sent markers, so a
+// restart never repeats a reminder
+func (s *BoltStore) DueReminders(now time.Time) []Reminder {
+    due, sent, expired := s.MemoryStore.dueReminders(now)
+    if len(sent) == 0 && len(expired) == 0 {
+        return due
+    }
+
+    starts := make(map[string]time.Time, len(due))
+    for _, r := range due {
+        starts[reminderKey(r.UserID, r.Event.ID)] = r.Event.Start
+    }
+
+    err := s.db.Update(func(tx *bolt.Tx) error {
+        b := tx.Bucket(bucketRemindersSent)
+        for _, key := range expired {
+            if err := b.Delete([]byte(key)); err != nil {
+                return err
+            }
+        }
+        for _, key := range sent {
+            data, err := json.Marshal(starts[key])
+            if err != nil {
+                return fmt.Errorf("encoding sent reminder %s: %w", key, err)
+            }
+            if err := b.Put([]byte(key), data); err != nil {
+                return err
+            }
+        }
+        return nil
+    })
+    if err != nil {
+        slog.Error("Failed to persist sent reminders", "sent", len(sent), "expired", len(expired), "error", err)
+    }
+
+    return due
+}
+
+// Close closes the underlying database
+func (s *BoltStore) Close() error {
+    return s.db.Close()
+}
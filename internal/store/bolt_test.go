@@ -0,0 +1,180 @@
+package store
+
+import (
+    "path/filepath"
+    "testing"
+    "time"
+
+    bolt "go.etcd.io/bbolt"
+)
+
+func TestBoltStorePersistence(t *testing.T) {
+    path := filepath.Join(t.TempDir(), "bot.db")
+
+    s, err := OpenBolt(path, DefaultHistoryRetention)
+    if err != nil {
+        t.Fatalf("Failed to open bolt store: %v", err)
+    }
+
+    start := time.Now().Add(time.Hour).Truncate(time.Second)
+    event := Event{
+        ID:        "persisted-event",
+        Title:     "Badminton Open Play",
+        Location:  "Mac Gym",
+        Start:     start,
+        End:       start.Add(2 * time.Hour),
+        SourceURL: "https://test.com",
+        Tags:      []string{"badminton"},
+    }
+
+    s.UpsertEvents([]Event{event})
+    s.Subscribe(NewSubscription("user123", 5, Above))
+    s.Subscribe(NewSubscription("user456", 3, Below))
+    s.Unsubscribe("user456")
+    s.SetMac(MacGymSnapshot{
+        RetrievedAt: start,
+        Location:    "Mac Gym",
+        Capacity:    8,
+        InUse:       4,
+        Details:     "4/8 in use",
+        Facilities: []Facility{
+            {ID: "5634", Name: "MAC Gym", Count: 4, Capacity: 8, Courts: true},
+            {ID: "5633", Name: "Event Center", Count: 20, Capacity: 120, Closed: true},
+        },
+        Raw: map[string]int{"ignored": 1},
+    })
+
+    if err := s.Close(); err != nil {
+        t.Fatalf("Failed to close bolt store: %v", err)
+    }
+
+    // Reopen and verify everything survived
+    s, err = OpenBolt(path, DefaultHistoryRetention)
+    if err != nil {
+        t.Fatalf("Failed to reopen bolt store: %v", err)
+    }
+    defer s.Close()
+
+    if s.GetEventCount() != 1 {
+        t.Errorf("Expected 1 event after reopen, got %d", s.GetEventCount())
+    }
+
+    upcoming := s.ListUpcoming(time.Now(), 1)
+    if len(upcoming) != 1 || upcoming[0].Title != event.Title || !upcoming[0].Start.Equal(start) {
+        t.Errorf("Unexpected upcoming events after reopen: %+v", upcoming)
+    }
+
+    subs := s.Subscribers()
+    if len(subs) != 1 || subs["user123"].Threshold != 5 || subs["user123"].Direction != Above {
+        t.Errorf("Expected only user123 with threshold 5, got %v", subs)
+    }
+
+    mac := s.GetMac()
+    if mac.InUse != 4 || mac.Capacity != 8 || !mac.RetrievedAt.Equal(start) {
+        t.Errorf("Unexpected snapshot after reopen: %+v", mac)
+    }
+
+    if mac.Raw != nil {
+        t.Errorf("Expected raw payload not to be persisted, got %v", mac.Raw)
+    }
+
+    if len(mac.Facilities) != 2 || mac.Facilities[1].Name != "Event Center" || !mac.Facilities[1].Closed || !mac.Facilities[0].Courts {
+        t.Errorf("Expected both facilities to be persisted, got %+v", mac.Facilities)
+    }
+    if history := s.History(start.Add(-time.Minute), start.Add(time.Minute)); len(history) != 1 || history[0].Facilities != nil {
+        t.Errorf("Expected one history reading without facilities, got %+v", history)
+    }
+}
+
+func TestBoltStoreMigrations(t *testing.T) {
+    path := filepath.Join(t.TempDir(), "bot.db")
+
+    s, err := OpenBolt(path, DefaultHistoryRetention)
+    if err != nil {
+        t.Fatalf("Failed to open bolt store: %v", err)
+    }
+
+    version, err := s.SchemaVersion()
+    if err != nil {
+        t.Fatalf("Failed to read schema version: %v", err)
+    }
+
+    if version != len(migrations) {
+        t.Errorf("Expected schema version %d, got %d", len(migrations), version)
+    }
+    s.Close()
+
+    // Reopening an up-to-date database must not fail or re-run migrations
+    s, err = OpenBolt(path, DefaultHistoryRetention)
+    if err != nil {
+        t.Fatalf("Failed to reopen bolt store: %v", err)
+    }
+    s.Close()
+}
+
+func TestOpenBackends(t *testing.T) {
+    testCases := []struct {
+        name    string
+        backend string
+        wantErr bool
+    }{
+        {"default", "", false},
+        {"memory", "memory", false},
+        {"bolt", "bolt", false},
+        {"unknown", "postgres", true},
+    }
+
+    for _, tc := range testCases {
+        t.Run(tc.name, func(t *testing.T) {
+            s, err := Open(Options{Backend: tc.backend, Path: filepath.Join(t.TempDir(), "bot.db")})
+            if tc.wantErr {
+                if err == nil {
+                    t.Error("Expected error but got none")
+                }
+                return
+            }
+            if err != nil {
+                t.Fatalf("Unexpected error: %v", err)
+            }
+            s.Close()
+        })
+    }
+}
+
+func TestBoltStoreHistoryCap(t *testing.T) {
+    path := filepath.Join(t.TempDir(), "bot.db")
+
+    s, err := OpenBolt(path, DefaultHistoryRetention)
+    if err != nil {
+        t.Fatalf("Failed to open bolt store: %v", err)
+    }
+    s.historyCap = 3
+
+    start := time.Now().Truncate(time.Minute)
+    for i := 0; i < 5; i++ {
+        s.SetMac(MacGymSnapshot{RetrievedAt: start.Add(time.Duration(i) * time.Minute), Capacity: 8, InUse: i})
+    }
+    if err := s.Close(); err != nil {
+        t.Fatalf("Failed to close bolt store: %v", err)
+    }
+
+    s, err = OpenBolt(path, DefaultHistoryRetention)
+    if err != nil {
+        t.Fatalf("Failed to reopen bolt store: %v", err)
+    }
+    defer s.Close()
+
+    got := s.History(start, start.Add(time.Hour))
+    if len(got) != 3 || got[0].InUse != 2 || got[2].InUse != 4 {
+        t.Fatalf("Expected the newest 3 readings to persist, got %+v", got)
+    }
+
+    var stored int
+    s.db.View(func(tx *bolt.Tx) error {
+        stored = tx.Bucket(bucketHistory).Stats().KeyN
+        return nil
+    })
+    if stored != 3 {
+        t.Errorf("Expected 3 readings on disk, got %d", stored)
+    }
+}
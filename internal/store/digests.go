@@ -0,0 +1,55 @@
+package store
+
+import (
+    "log/slog"
+    "sort"
+)
+
+// DigestFrequency is how often a user receives the scheduled digest
+type DigestFrequency string
+
+const (
+    DigestDaily  DigestFrequency = "daily"
+    DigestWeekly DigestFrequency = "weekly"
+)
+
+// Valid reports whether f is a known frequency
+func (f DigestFrequency) Valid() bool {
+    return f == DigestDaily || f == DigestWeekly
+}
+
+// SetDigest subscribes a user to the digest at frequency f. An empty
+// frequency unsubscribes them.
+func (m *MemoryStore) SetDigest(userID string, f DigestFrequency) {
+    m.mu.Lock()
+    defer m.mu.Unlock()
+
+    if f == "" {
+        delete(m.digests, userID)
+    } else {
+        m.digests[userID] = f
+    }
+    slog.Info("User set digest frequency", "userID", userID, "frequency", f)
+}
+
+// Digest returns the user's digest frequency, or "" if they don't get one
+func (m *MemoryStore) Digest(userID string) DigestFrequency {
+    m.mu.RLock()
+    defer m.mu.RUnlock()
+    return m.digests[userID]
+}
+
+// DigestSubscribers returns the users receiving the digest at frequency f, sorted
+func (m *MemoryStore) DigestSubscribers(f DigestFrequency) []string {
+    m.mu.RLock()
+    defer m.mu.RUnlock()
+
+    var users []string
+    for userID, freq := range m.digests {
+        if freq == f {
+            users = append(users, userID)
+        }
+    }
+    sort.Strings(users)
+    return users
+}
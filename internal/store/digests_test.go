@@ -0,0 +1,37 @@
+package store
+
+import (
+    "path/filepath"
+    "testing"
+)
+
+func TestBoltDigestPersistence(t *testing.T) {
+    path := filepath.Join(t.TempDir(), "bot.db")
+
+    s, err := OpenBolt(path, DefaultHistoryRetention)
+    if err != nil {
+        t.Fatalf("Failed to open bolt store: %v", err)
+    }
+
+    s.SetDigest("user1", DigestDaily)
+    s.SetDigest("user2", DigestWeekly)
+    s.SetDigest("user3", DigestDaily)
+    s.SetDigest("user3", "")
+    s.Close()
+
+    s, err = OpenBolt(path, DefaultHistoryRetention)
+    if err != nil {
+        t.Fatalf("Failed to reopen bolt store: %v", err)
+    }
+    defer s.Close()
+
+    if got := s.DigestSubscribers(DigestDaily); len(got) != 1 || got[0] != "user1" {
+        t.Errorf("Expected only user1 on the daily digest, got %v", got)
+    }
+    if got := s.Digest("user2"); got != DigestWeekly {
+        t.Errorf("Expected user2 on the weekly digest, got %q", got)
+    }
+    if got := s.Digest("user3"); got != "" {
+        t.Errorf("Expected user3's digest to stay off, got %q", got)
+    }
+}
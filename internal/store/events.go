@@ -0,0 +1,242 @@
+package store
+
+import (
+    "log/slog"
+    "slices"
+    "sort"
+    "strings"
+    "time"
+)
+
+// Refresh is a complete listing of one source's events
+type Refresh struct {
+    Source string
+    Events []Event
+    At     time.Time // when the listing was fetched
+
+    // Until bounds the window the listing covers. Events from the source that
+    // start between At and Until but are missing from the listing are marked
+    // cancelled. A zero Until disables cancellation.
+    Until time.Time
+}
+
+// ChangeSet describes how a refresh changed the stored events
+type ChangeSet struct {
+    Added     []Event
+    Changed   []Change
+    Cancelled []Event
+}
+
+// Change is an event a refresh changed, along with how it looked before
+type Change struct {
+    Event
+    Previous Event
+}
+
+// Moved reports whether the event was rescheduled or changed rooms
+func (c Change) Moved() bool {
+    return !c.Start.Equal(c.Previous.Start) || !c.End.Equal(c.Previous.End) || c.Location != c.Previous.Location
+}
+
+// Empty reports whether the refresh changed nothing
+func (c ChangeSet) Empty() bool {
+    return len(c.Added) == 0 && len(c.Changed) == 0 && len(c.Cancelled) == 0
+}
+
+// ApplyRefresh merges a full listing from a source into the store and
+// reports what was added, changed or cancelled
+func (m *MemoryStore) ApplyRefresh(r Refresh) ChangeSet {
+    changes, _, _ := m.applyRefresh(r)
+    return changes
+}
+
+// applyRefresh also returns every event it wrote and the IDs of events it
+// removed, for persistence
+func (m *MemoryStore) applyRefresh(r Refresh) (ChangeSet, []Event, []string) {
+    m.mu.Lock()
+    defer m.mu.Unlock()
+
+    var changes ChangeSet
+    var touched []Event
+    var removed []string
+    seen := make(map[string]bool, len(r.Events))
+    for _, e := range r.Events {
+        seen[e.ID] = true
+    }
+
+    // Event IDs hash the time and location, so a rescheduled event or one
+    // moved to another room comes back under a new ID. Pair such events with
+    // the missing event they replace before anything is marked cancelled.
+    moved := m.matchMoves(r, seen)
+
+    for _, e := range r.Events {
+        e.Source = r.Source
+        e.LastSeen = r.At
+
+        old, exists := m.events[e.ID]
+        prev, wasMoved := moved[e.ID]
+        switch {
+        case wasMoved:
+            e.FirstSeen = prev.FirstSeen
+            delete(m.events, prev.ID)
+            removed = append(removed, prev.ID)
+            changes.Changed = append(changes.Changed, Change{Event: e, Previous: prev})
+            slog.Info("Event moved", "id", e.ID, "previous_id", prev.ID, "title", e.Title,
+                "start", e.Start, "previous_start", prev.Start, "location", e.Location)
+        case !exists:
+            e.FirstSeen = r.At
+            changes.Added = append(changes.Added, e)
+            slog.Info("Added new event", "id", e.ID, "title", e.Title, "start", e.Start)
+        case old.Cancelled || old.SourceURL != e.SourceURL || old.RegisterURL != e.RegisterURL || !slices.Equal(old.Tags, e.Tags):
+            e.FirstSeen = old.FirstSeen
+            changes.Changed = append(changes.Changed, Change{Event: e, Previous: old})
+            slog.Info("Event changed", "id", e.ID, "title", e.Title, "reinstated", old.Cancelled)
+        default:
+            e.FirstSeen = old.FirstSeen
+        }
+
+        m.events[e.ID] = e
+        touched = append(touched, e)
+    }
+
+    if !r.Until.IsZero() {
+        for id, e := range m.events {
+            if e.Source != r.Source || e.Cancelled || seen[id] {
+                continue
+            }
+            if e.Start.Before(r.At) || e.Start.After(r.Until) {
+                continue // already started, or outside what this listing covers
+            }
+
+            e.Cancelled = true
+            e.CancelledAt = r.At
+            m.events[id] = e
+            changes.Cancelled = append(changes.Cancelled, e)
+            touched = append(touched, e)
+            slog.Info("Event cancelled", "id", id, "title", e.Title, "start", e.Start)
+        }
+    }
+
+    m.sources[r.Source] = r.At
+
+    sortByStart(changes.Added)
+    sort.Slice(changes.Changed, func(i, j int) bool {
+        return changes.Changed[i].Start.Before(changes.Changed[j].Start)
+    })
+    sortByStart(changes.Cancelled)
+
+    slog.Info("Applied event refresh",
+        "source", r.Source,
+        "listed", len(r.Events),
+        "added", len(changes.Added),
+        "changed", len(changes.Changed),
+        "cancelled", len(changes.Cancelled),
+        "total", len(m.events))
+
+    return changes, touched, removed
+}
+
+// matchMoves pairs listed events that are new to the store with stored events
+// from the same source that the listing no longer has and would otherwise be
+// cancelled. A pair shares a title and a day; the closest start wins. It
+// returns the replaced event keyed by the new event's ID. The caller must
+// hold the lock.
+func (m *MemoryStore) matchMoves(r Refresh, seen map[string]bool) map[string]Event {
+    if r.Until.IsZero() {
+        return nil // without a window, a missing event is not known to be gone
+    }
+
+    var missing []Event
+    for id, e := range m.events {
+        if e.Source != r.Source || e.Cancelled || seen[id] {
+            continue
+        }
+        if e.Start.Before(r.At) || e.Start.After(r.Until) {
+            continue
+        }
+        missing = append(missing, e)
+    }
+    if len(missing) == 0 {
+        return nil
+    }
+    sortByStart(missing)
+
+    moved := make(map[string]Event)
+    used := make(map[string]bool, len(missing))
+    for _, e := range r.Events {
+        if _, exists := m.events[e.ID]; exists {
+            continue
+        }
+
+        best := -1
+        var bestGap time.Duration
+        for i, old := range missing {
+            if used[old.ID] || !strings.EqualFold(strings.TrimSpace(old.Title), strings.TrimSpace(e.Title)) || !sameDay(old.Start, e.Start) {
+                continue
+            }
+            gap := e.Start.Sub(old.Start).Abs()
+            if best < 0 || gap < bestGap {
+                best, bestGap = i, gap
+            }
+        }
+        if best >= 0 {
+            used[missing[best].ID] = true
+            moved[e.ID] = missing[best]
+        }
+    }
+    return moved
+}
+
+// sameDay reports whether a and b fall on the same calendar day in a's zone
+func sameDay(a, b time.Time) bool {
+    b = b.In(a.Location())
+    return a.Year() == b.Year() && a.YearDay() == b.YearDay()
+}
+
+// PruneEvents removes events that ended before now-retention and returns them
+func (m *MemoryStore) PruneEvents(now time.Time, retention time.Duration) []Event {
+    m.mu.Lock()
+    defer m.mu.Unlock()
+
+    cutoff := now.Add(-retention)
+    var pruned []Event
+    for id, e := range m.events {
+        if e.End.Before(cutoff) {
+            delete(m.events, id)
+            pruned = append(pruned, e)
+        }
+    }
+
+    if len(pruned) > 0 {
+        slog.Info("Pruned ended events", "count", len(pruned), "remaining", len(m.events))
+    }
+    return pruned
+}
+
+// SourceLastSeen returns when source last delivered a full refresh
+func (m *MemoryStore) SourceLastSeen(source string) time.Time {
+    m.mu.RLock()
+    defer m.mu.RUnlock()
+    return m.sources[source]
+}
+
+// SourceEventCount returns how many stored events, cancelled or not, came
+// from source
+func (m *MemoryStore) SourceEventCount(source string) int {
+    m.mu.RLock()
+    defer m.mu.RUnlock()
+
+    n := 0
+    for _, e := range m.events {
+        if e.Source == source {
+            n++
+        }
+    }
+    return n
+}
+
+func sortByStart(es []Event) {
+    sort.Slice(es, func(i, j int) bool {
+        return es[i].Start.Before(es[j].Start)
+    })
+}
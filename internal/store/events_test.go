@@ -0,0 +1,230 @@
+package store
+
+import (
+    "path/filepath"
+    "testing"
+    "time"
+)
+
+func testEvent(title string, start time.Time) Event {
+    end := start.Add(2 * time.Hour)
+    return Event{
+        ID:        HashKey(title, start, end, "Mac Gym"),
+        Title:     title,
+        Location:  "Mac Gym",
+        Start:     start,
+        End:       end,
+        SourceURL: "https://test.com",
+        Tags:      []string{"badminton"},
+    }
+}
+
+func TestApplyRefreshLifecycle(t *testing.T) {
+    store := NewMemoryStore()
+    now := time.Date(2024, 1, 15, 12, 0, 0, 0, time.UTC)
+
+    openPlay := testEvent("Open Play", now.Add(6*time.Hour))
+    practice := testEvent("Club Practice", now.Add(30*time.Hour))
+    lessons := testEvent("Lessons", now.Add(50*time.Hour))
+    beyond := testEvent("Tournament", now.Add(10*24*time.Hour))
+
+    // Event from another source must never be cancelled by this one
+    other := testEvent("Other Source", now.Add(8*time.Hour))
+    store.ApplyRefresh(Refresh{Source: "other", Events: []Event{other}, At: now})
+
+    first := store.ApplyRefresh(Refresh{
+        Source: "fitness",
+        Events: []Event{openPlay, practice, lessons, beyond},
+        At:     now,
+        Until:  beyond.Start,
+    })
+    if len(first.Added) != 4 || len(first.Changed) != 0 || len(first.Cancelled) != 0 {
+        t.Fatalf("Unexpected first change set: %+v", first)
+    }
+
+    // Practice disappears, lessons gains a tag, the tournament is beyond the
+    // second listing's window
+    later := now.Add(30 * time.Minute)
+    lessons.Tags = []string{"badminton", "lessons"}
+    second := store.ApplyRefresh(Refresh{
+        Source: "fitness",
+        Events: []Event{openPlay, lessons},
+        At:     later,
+        Until:  lessons.Start,
+    })
+
+    if len(second.Added) != 0 {
+        t.Errorf("Expected no additions, got %+v", second.Added)
+    }
+    if len(second.Changed) != 1 || second.Changed[0].ID != lessons.ID {
+        t.Errorf("Expected lessons to be changed, got %+v", second.Changed)
+    }
+    if len(second.Cancelled) != 1 || second.Cancelled[0].ID != practice.ID {
+        t.Fatalf("Expected practice to be cancelled, got %+v", second.Cancelled)
+    }
+    if !second.Cancelled[0].CancelledAt.Equal(later) {
+        t.Errorf("Expected CancelledAt %v, got %v", later, second.Cancelled[0].CancelledAt)
+    }
+
+    upcoming := store.ListUpcoming(now, 30)
+    if len(upcoming) != 4 {
+        t.Errorf("Expected 4 upcoming events without the cancelled one, got %d", len(upcoming))
+    }
+    for _, e := range upcoming {
+        if e.ID == practice.ID {
+            t.Error("Cancelled event should not be listed as upcoming")
+        }
+        if e.ID == openPlay.ID && (!e.FirstSeen.Equal(now) || !e.LastSeen.Equal(later)) {
+            t.Errorf("Expected first/last seen %v/%v, got %v/%v", now, later, e.FirstSeen, e.LastSeen)
+        }
+    }
+
+    // Practice comes back: reinstated as a change, not a new event
+    third := store.ApplyRefresh(Refresh{
+        Source: "fitness",
+        Events: []Event{openPlay, practice, lessons},
+        At:     later.Add(30 * time.Minute),
+        Until:  lessons.Start,
+    })
+    if len(third.Changed) != 1 || third.Changed[0].ID != practice.ID || third.Changed[0].Cancelled {
+        t.Errorf("Expected practice to be reinstated, got %+v", third.Changed)
+    }
+
+    if got := store.SourceLastSeen("fitness"); !got.Equal(later.Add(30 * time.Minute)) {
+        t.Errorf("Unexpected source last seen time %v", got)
+    }
+}
+
+func TestApplyRefreshWithoutWindowNeverCancels(t *testing.T) {
+    store := NewMemoryStore()
+    now := time.Date(2024, 1, 15, 12, 0, 0, 0, time.UTC)
+
+    store.ApplyRefresh(Refresh{Source: "fitness", Events: []Event{testEvent("Open Play", now.Add(time.Hour))}, At: now})
+    changes := store.ApplyRefresh(Refresh{Source: "fitness", At: now.Add(time.Minute)})
+
+    if len(changes.Cancelled) != 0 {
+        t.Errorf("Expected no cancellations from an empty listing, got %+v", changes.Cancelled)
+    }
+}
+
+func TestApplyRefreshMoves(t *testing.T) {
+    now := time.Date(2024, 1, 15, 12, 0, 0, 0, time.UTC)
+    openPlay := testEvent("Open Play", now.Add(6*time.Hour))
+
+    moved := func(start time.Time, location string) Event {
+        e := testEvent("Open Play", start)
+        e.Location = location
+        e.ID = HashKey(e.Title, e.Start, e.End, e.Location)
+        return e
+    }
+
+    testCases := []struct {
+        name        string
+        listed      Event
+        wantMoved   bool
+        wantAdded   int
+        wantDropped int
+    }{
+        {name: "rescheduled same day", listed: moved(now.Add(7*time.Hour), "Mac Gym"), wantMoved: true},
+        {name: "new room", listed: moved(openPlay.Start, "Event Center"), wantMoved: true},
+        {name: "title case only", listed: func() Event { e := moved(now.Add(7*time.Hour), "Mac Gym"); e.Title = "OPEN PLAY"; return e }(), wantMoved: true},
+        {name: "another day", listed: moved(now.Add(30*time.Hour), "Mac Gym"), wantAdded: 1, wantDropped: 1},
+    }
+
+    for _, tc := range testCases {
+        t.Run(tc.name, func(t *testing.T) {
+            store := NewMemoryStore()
+            until := now.Add(48 * time.Hour)
+            store.ApplyRefresh(Refresh{Source: "fitness", Events: []Event{openPlay}, At: now, Until: until})
+
+            later := now.Add(time.Hour)
+            changes := store.ApplyRefresh(Refresh{Source: "fitness", Events: []Event{tc.listed}, At: later, Until: until})
+
+            if len(changes.Added) != tc.wantAdded || len(changes.Cancelled) != tc.wantDropped {
+                t.Errorf("Expected %d added and %d cancelled, got %+v", tc.wantAdded, tc.wantDropped, changes)
+            }
+            if !tc.wantMoved {
+                if len(changes.Changed) != 0 {
+                    t.Errorf("Expected no changes, got %+v", changes.Changed)
+                }
+                return
+            }
+
+            if len(changes.Changed) != 1 {
+                t.Fatalf("Expected one change, got %+v", changes)
+            }
+            c := changes.Changed[0]
+            if c.ID != tc.listed.ID || c.Previous.ID != openPlay.ID || !c.Moved() {
+                t.Errorf("Expected a move from %s to %s, got %+v", openPlay.ID, tc.listed.ID, c)
+            }
+            if !c.FirstSeen.Equal(now) {
+                t.Errorf("Expected the moved event to keep FirstSeen %v, got %v", now, c.FirstSeen)
+            }
+            if store.GetEventCount() != 1 {
+                t.Errorf("Expected the old event to be replaced, got %d events", store.GetEventCount())
+            }
+        })
+    }
+}
+
+func TestPruneEvents(t *testing.T) {
+    store := NewMemoryStore()
+    now := time.Date(2024, 1, 15, 12, 0, 0, 0, time.UTC)
+
+    old := testEvent("Old", now.Add(-10*24*time.Hour))
+    recent := testEvent("Recent", now.Add(-24*time.Hour))
+    future := testEvent("Future", now.Add(24*time.Hour))
+    store.UpsertEvents([]Event{old, recent, future})
+
+    pruned := store.PruneEvents(now, 7*24*time.Hour)
+    if len(pruned) != 1 || pruned[0].ID != old.ID {
+        t.Errorf("Expected only the old event to be pruned, got %+v", pruned)
+    }
+    if store.GetEventCount() != 2 {
+        t.Errorf("Expected 2 events after pruning, got %d", store.GetEventCount())
+    }
+}
+
+func TestBoltEventLifecyclePersistence(t *testing.T) {
+    path := filepath.Join(t.TempDir(), "bot.db")
+    now := time.Now().Truncate(time.Second)
+
+    s, err := OpenBolt(path, DefaultHistoryRetention)
+    if err != nil {
+        t.Fatalf("Failed to open bolt store: %v", err)
+    }
+
+    keep := testEvent("Keep", now.Add(2*time.Hour))
+    drop := testEvent("Drop", now.Add(time.Hour))
+    old := testEvent("Old", now.Add(-30*24*time.Hour))
+
+    s.UpsertEvents([]Event{old})
+    s.ApplyRefresh(Refresh{Source: "fitness", Events: []Event{keep, drop}, At: now, Until: keep.Start})
+    s.ApplyRefresh(Refresh{Source: "fitness", Events: []Event{keep}, At: now.Add(time.Minute), Until: keep.Start})
+    // Keep moves to another room: its old ID must be gone from disk too
+    kept := keep
+    kept.Location = "Event Center"
+    kept.ID = HashKey(kept.Title, kept.Start, kept.End, kept.Location)
+    s.ApplyRefresh(Refresh{Source: "fitness", Events: []Event{kept}, At: now.Add(time.Minute), Until: keep.Start})
+    s.PruneEvents(now, 7*24*time.Hour)
+    s.Close()
+
+    s, err = OpenBolt(path, DefaultHistoryRetention)
+    if err != nil {
+        t.Fatalf("Failed to reopen bolt store: %v", err)
+    }
+    defer s.Close()
+
+    if s.GetEventCount() != 2 {
+        t.Errorf("Expected 2 events after reopen (pruned one removed), got %d", s.GetEventCount())
+    }
+
+    upcoming := s.ListUpcoming(now, 1)
+    if len(upcoming) != 1 || upcoming[0].ID != kept.ID {
+        t.Errorf("Expected only the kept event upcoming after reopen, got %+v", upcoming)
+    }
+
+    if got := s.SourceLastSeen("fitness"); !got.Equal(now.Add(time.Minute)) {
+        t.Errorf("Expected persisted source last seen %v, got %v", now.Add(time.Minute), got)
+    }
+}
@@ -0,0 +1,84 @@
+package store
+
+import (
+    "sort"
+    "time"
+)
+
+const (
+    // DefaultHistoryRetention is how long occupancy readings are kept
+    DefaultHistoryRetention = 28 * 24 * time.Hour
+
+    // maxHistoryEntries caps the history regardless of retention
+    // (30 days of one reading per minute)
+    maxHistoryEntries = 30 * 24 * 60
+)
+
+// SetHistoryRetention changes how long occupancy readings are kept
+func (m *MemoryStore) SetHistoryRetention(d time.Duration) {
+    m.mu.Lock()
+    defer m.mu.Unlock()
+
+    if d <= 0 {
+        d = DefaultHistoryRetention
+    }
+    m.retention = d
+    if len(m.history) > 0 {
+        m.pruneHistory(m.history[len(m.history)-1].RetrievedAt)
+    }
+}
+
+// History returns the occupancy readings retrieved in [since, until), oldest first
+func (m *MemoryStore) History(since, until time.Time) []MacGymSnapshot {
+    m.mu.RLock()
+    defer m.mu.RUnlock()
+
+    lo := sort.Search(len(m.history), func(i int) bool {
+        return !m.history[i].RetrievedAt.Before(since)
+    })
+    hi := sort.Search(len(m.history), func(i int) bool {
+        return !m.history[i].RetrievedAt.Before(until)
+    })
+    if lo >= hi {
+        return nil
+    }
+
+    out := make([]MacGymSnapshot, hi-lo)
+    copy(out, m.history[lo:hi])
+    return out
+}
+
+// appendHistory records a reading. Readings without capacity data or that
+// repeat the latest timestamp are skipped. Callers must hold m.mu.
+func (m *MemoryStore) appendHistory(s MacGymSnapshot) bool {
+    if s.RetrievedAt.IsZero() || s.Capacity == 0 {
+        return false
+    }
+    if n := len(m.history); n > 0 && !s.RetrievedAt.After(m.history[n-1].RetrievedAt) {
+        return false
+    }
+
+    // Raw payloads are for debugging only and other facilities aren't
+    // forecast; don't keep weeks of them around
+    s.Raw = nil
+    s.Facilities = nil
+    m.history = append(m.history, s)
+    m.pruneHistory(s.RetrievedAt)
+    return true
+}
+
+// pruneHistory drops readings older than the retention window ending at now
+// and enforces the entry cap. Callers must hold m.mu.
+func (m *MemoryStore) pruneHistory(now time.Time) {
+    cutoff := now.Add(-m.retention)
+    drop := sort.Search(len(m.history), func(i int) bool {
+        return !m.history[i].RetrievedAt.Before(cutoff)
+    })
+    if over := len(m.history) - drop - m.historyCap; over > 0 {
+        drop += over
+    }
+    if drop > 0 {
+        // Re-slicing is enough: append reallocates and releases the head
+        m.history = m.history[drop:]
+    }
+}
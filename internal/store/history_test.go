@@ -0,0 +1,82 @@
+package store
+
+import (
+    "path/filepath"
+    "testing"
+    "time"
+)
+
+func TestHistoryRecording(t *testing.T) {
+    store := NewMemoryStore()
+    store.SetHistoryRetention(time.Hour)
+
+    start := time.Date(2024, 1, 15, 14, 0, 0, 0, time.UTC)
+    for i := 0; i < 5; i++ {
+        store.SetMac(MacGymSnapshot{
+            RetrievedAt: start.Add(time.Duration(i) * 20 * time.Minute),
+            Capacity:    8,
+            InUse:       i,
+            Raw:         "payload",
+        })
+    }
+
+    // Duplicate timestamp and missing capacity are not recorded
+    store.SetMac(MacGymSnapshot{RetrievedAt: start.Add(80 * time.Minute), Capacity: 8, InUse: 7})
+    store.SetMac(MacGymSnapshot{RetrievedAt: start.Add(90 * time.Minute)})
+
+    all := store.History(time.Time{}, start.Add(24*time.Hour))
+
+    // The reading at 0 minutes falls outside the one hour retention
+    if len(all) != 4 {
+        t.Fatalf("Expected 4 readings within retention, got %d", len(all))
+    }
+
+    if all[0].InUse != 1 || all[3].InUse != 4 {
+        t.Errorf("Unexpected readings: %+v", all)
+    }
+
+    for _, r := range all {
+        if r.Raw != nil {
+            t.Errorf("Expected raw payload to be dropped from history, got %v", r.Raw)
+        }
+    }
+
+    window := store.History(start.Add(40*time.Minute), start.Add(80*time.Minute))
+    if len(window) != 2 {
+        t.Errorf("Expected 2 readings in [40m, 80m), got %d", len(window))
+    }
+}
+
+func TestBoltHistoryPersistence(t *testing.T) {
+    path := filepath.Join(t.TempDir(), "bot.db")
+
+    s, err := OpenBolt(path, 2*time.Hour)
+    if err != nil {
+        t.Fatalf("Failed to open bolt store: %v", err)
+    }
+
+    start := time.Date(2024, 1, 15, 14, 0, 0, 0, time.UTC)
+    for i := 0; i < 6; i++ {
+        s.SetMac(MacGymSnapshot{
+            RetrievedAt: start.Add(time.Duration(i) * time.Hour),
+            Capacity:    8,
+            InUse:       i,
+        })
+    }
+    s.Close()
+
+    s, err = OpenBolt(path, 2*time.Hour)
+    if err != nil {
+        t.Fatalf("Failed to reopen bolt store: %v", err)
+    }
+    defer s.Close()
+
+    history := s.History(time.Time{}, start.Add(24*time.Hour))
+    if len(history) != 3 {
+        t.Fatalf("Expected 3 persisted readings within retention, got %d", len(history))
+    }
+
+    if history[0].InUse != 3 || history[2].InUse != 5 {
+        t.Errorf("Unexpected persisted readings: %+v", history)
+    }
+}
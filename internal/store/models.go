@@ -17,7 +17,24 @@ type MacGymSnapshot struct {
     Capacity    int
     InUse       int
     Details     string
-    Raw         any
+    // Facilities is every location reported by the occupancy source
+    Facilities []Facility
+    Raw        any
+}
+
+// Facility is one location's occupancy as reported by the occupancy source
+type Facility struct {
+    ID    string
+    Name  string
+    Count int
+    // Capacity is 0 when the source doesn't report one
+    Capacity int
+    Closed   bool
+    // Updated is when the source last counted, or zero if unknown
+    Updated time.Time
+    // Courts marks the locations counted as badminton courts in the
+    // snapshot's Capacity and InUse
+    Courts bool
 }
 
 type Event struct {
@@ -27,23 +44,51 @@ type Event struct {
     Start       time.Time
     End         time.Time
     SourceURL   string
+    RegisterURL string // registration page, when the listing links one
     Tags        []string
     RetrievedAt time.Time
+
+    // Lifecycle, maintained by ApplyRefresh
+    Source      string    // scraper that reported the event
+    FirstSeen   time.Time
+    LastSeen    time.Time
+    Cancelled   bool      // missing from a later full refresh of its source
+    CancelledAt time.Time
 }
 
 type MemoryStore struct {
-    mu         sync.RWMutex
-    mac        MacGymSnapshot
-    events     map[string]Event
-    subs       map[string]int // userID -> threshold
-    lastAlert  time.Time      // for debouncing alerts
+    mu            sync.RWMutex
+    mac           MacGymSnapshot
+    events        map[string]Event
+    subs          map[string]Subscription // Subscription.Key() -> subscription
+    cooldown      time.Duration           // minimum time between alerts per subscriber
+    notifier      Notifier
+    channels      map[string]string       // guildID -> alert channel ID
+    history       []MacGymSnapshot // oldest first, bounded by retention
+    retention     time.Duration
+    historyCap    int // most readings kept, whatever the retention
+    sources       map[string]time.Time       // source -> last full refresh
+    reminders     map[string]time.Duration   // userID -> reminder lead time
+    remindersSent map[string]time.Time       // reminderKey -> event start
+    quiet         map[string]ClockRange      // userID -> quiet hours
+    digests       map[string]DigestFrequency // userID -> digest frequency
+    loc           *time.Location             // zone of active windows and quiet hours
 }
 
 func NewMemoryStore() *MemoryStore {
     return &MemoryStore{
-        events:    make(map[string]Event),
-        subs:      make(map[string]int),
-        lastAlert: time.Time{},
+        events:        make(map[string]Event),
+        subs:          make(map[string]Subscription),
+        cooldown:      DefaultAlertCooldown,
+        retention:     DefaultHistoryRetention,
+        historyCap:    maxHistoryEntries,
+        sources:       make(map[string]time.Time),
+        reminders:     make(map[string]time.Duration),
+        remindersSent: make(map[string]time.Time),
+        quiet:         make(map[string]ClockRange),
+        digests:       make(map[string]DigestFrequency),
+        channels:      make(map[string]string),
+        loc:           time.Local,
     }
 }
 
@@ -58,13 +103,26 @@ func HashKey(title string, start, end time.Time, loc string) string {
     return hex.EncodeToString(h[:])
 }
 
+// SetNotifier registers the receiver for threshold alerts
+func (m *MemoryStore) SetNotifier(n Notifier) {
+    m.mu.Lock()
+    defer m.mu.Unlock()
+    m.notifier = n
+}
+
 // SetMac updates the Mac Gym snapshot
 func (m *MemoryStore) SetMac(s MacGymSnapshot) {
+    m.setMac(s)
+}
+
+// setMac updates the snapshot, records it in the history and dispatches
+// alerts. It reports whether the snapshot was appended to the history and
+// which subscriptions changed state, for persistence.
+func (m *MemoryStore) setMac(s MacGymSnapshot) (bool, []Subscription) {
     m.mu.Lock()
-    defer m.mu.Unlock()
     
-    oldSnapshot := m.mac
     m.mac = s
+    recorded := m.appendHistory(s)
     
     slog.Info("Updated Mac Gym snapshot", 
         "capacity", s.Capacity,
@@ -72,7 +130,18 @@ func (m *MemoryStore) SetMac(s MacGymSnapshot) {
         "details", s.Details)
     
     // Check for threshold alerts
-    m.checkThresholdAlerts(oldSnapshot, s)
+    alerts, changed := m.checkThresholdAlerts(s)
+    notifier := m.notifier
+    m.mu.Unlock()
+    
+    // Deliver outside the lock so slow notifiers don't block readers
+    if notifier != nil {
+        for _, a := range alerts {
+            notifier.NotifyThreshold(a)
+        }
+    }
+    
+    return recorded, changed
 }
 
 // GetMac returns a copy of the current Mac Gym snapshot
@@ -87,6 +156,7 @@ func (m *MemoryStore) GetMac() MacGymSnapshot {
         Capacity:    m.mac.Capacity,
         InUse:       m.mac.InUse,
         Details:     m.mac.Details,
+        Facilities:  append([]Facility(nil), m.mac.Facilities...),
         Raw:         m.mac.Raw,
     }
 }
@@ -125,7 +195,7 @@ func (m *MemoryStore) ListUpcoming(now time.Time, days int) []Event {
     var upcoming []Event
     
     for _, e := range m.events {
-        if e.End.After(now) && e.Start.Before(cutoff) {
+        if !e.Cancelled && e.End.After(now) && e.Start.Before(cutoff) {
             upcoming = append(upcoming, e)
         }
     }
@@ -138,62 +208,107 @@ func (m *MemoryStore) ListUpcoming(now time.Time, days int) []Event {
     return upcoming
 }
 
-// Subscribe adds a user to the alert subscription list
-func (m *MemoryStore) Subscribe(userID string, threshold int) {
+// Subscribe adds or replaces a user's alert subscription. A subscription
+// whose condition already holds starts disarmed, so it only alerts on a
+// fresh crossing.
+func (m *MemoryStore) Subscribe(sub Subscription) {
+    m.subscribe(sub)
+}
+
+// subscribe stores the subscription and returns the stored copy
+func (m *MemoryStore) subscribe(sub Subscription) Subscription {
     m.mu.Lock()
     defer m.mu.Unlock()
     
-    m.subs[userID] = threshold
-    slog.Info("User subscribed to alerts", "userID", userID, "threshold", threshold)
+    if m.mac.Capacity > 0 && sub.triggered(m.mac.InUse) {
+        sub.Armed = false
+    }
+    m.subs[sub.Key()] = sub
+    slog.Info("Subscribed to alerts",
+        "key", sub.Key(),
+        "threshold", sub.Threshold,
+        "direction", sub.Direction,
+        "active", sub.Active.String(),
+        "armed", sub.Armed)
+    return sub
 }
 
-// Unsubscribe removes a user from the alert subscription list
-func (m *MemoryStore) Unsubscribe(userID string) {
+// Unsubscribe removes the subscription stored under key: a user ID, or
+// RoleKey for a role subscription
+func (m *MemoryStore) Unsubscribe(key string) {
     m.mu.Lock()
     defer m.mu.Unlock()
     
-    delete(m.subs, userID)
-    slog.Info("User unsubscribed from alerts", "userID", userID)
+    delete(m.subs, key)
+    slog.Info("Unsubscribed from alerts", "key", key)
 }
 
-// Subscribers returns a copy of the subscribers map
-func (m *MemoryStore) Subscribers() map[string]int {
+// Subscribers returns a copy of the subscriptions keyed by Subscription.Key
+func (m *MemoryStore) Subscribers() map[string]Subscription {
     m.mu.RLock()
     defer m.mu.RUnlock()
     
-    subs := make(map[string]int, len(m.subs))
+    subs := make(map[string]Subscription, len(m.subs))
     for k, v := range m.subs {
         subs[k] = v
     }
     return subs
 }
 
-// checkThresholdAlerts checks if occupancy thresholds have been crossed
-func (m *MemoryStore) checkThresholdAlerts(old, new MacGymSnapshot) {
-    if new.Capacity == 0 {
-        return // No capacity data available
+// checkThresholdAlerts applies a reading to every subscription and returns
+// the alerts for thresholds that have just been crossed, along with the
+// subscriptions whose arming state changed
+func (m *MemoryStore) checkThresholdAlerts(snap MacGymSnapshot) ([]ThresholdAlert, []Subscription) {
+    if snap.Capacity == 0 {
+        return nil, nil // No capacity data available
     }
     
-    // Debounce alerts (max once per minute)
-    if time.Since(m.lastAlert) < time.Minute {
-        return
+    at := snap.RetrievedAt
+    if at.IsZero() {
+        at = time.Now()
     }
+    local := at.In(m.loc)
     
-    for userID, threshold := range m.subs {
-        oldCrossed := old.InUse >= threshold
-        newCrossed := new.InUse >= threshold
+    var alerts []ThresholdAlert
+    var changed []Subscription
+    
+    for key, sub := range m.subs {
+        if !sub.alerts() {
+            continue
+        }
         
-        // Alert if threshold was just crossed
-        if !oldCrossed && newCrossed {
-            m.lastAlert = time.Now()
+        // Hold crossings while muted; the subscription stays armed and alerts
+        // once it's unmuted if the condition still holds
+        if sub.Armed && sub.triggered(snap.InUse) && sub.muted(local, m.quiet[sub.UserID]) {
+            continue
+        }
+        
+        fire, stateChanged := sub.update(snap.InUse, at, m.cooldown)
+        if !stateChanged {
+            continue
+        }
+        m.subs[key] = sub
+        changed = append(changed, sub)
+        
+        if fire {
             slog.Info("Threshold crossed", 
-                "userID", userID, 
-                "threshold", threshold, 
-                "current", new.InUse, 
-                "capacity", new.Capacity)
-            // Note: Actual alert sending would be handled by the Discord client
+                "key", key, 
+                "threshold", sub.Threshold, 
+                "direction", sub.Direction,
+                "current", snap.InUse, 
+                "capacity", snap.Capacity)
+            alerts = append(alerts, ThresholdAlert{
+                UserID:    sub.UserID,
+                RoleID:    sub.RoleID,
+                GuildID:   sub.GuildID,
+                Threshold: sub.Threshold,
+                Direction: sub.Direction,
+                Snapshot:  snap,
+            })
         }
     }
+    
+    return alerts, changed
 }
 
 // GetEventCount returns the total number of events in the store
@@ -209,3 +324,8 @@ func (m *MemoryStore) GetSubscriberCount() int {
     defer m.mu.RUnlock()
     return len(m.subs)
 }
+
+// Close releases store resources; the memory store holds none
+func (m *MemoryStore) Close() error {
+    return nil
+}
@@ -78,14 +78,14 @@ func TestMemoryStore(t *testing.T) {
     }
 
     // Test subscriptions
-    store.Subscribe("user123", 5)
+    store.Subscribe(NewSubscription("user123", 5, Above))
     if store.GetSubscriberCount() != 1 {
         t.Errorf("Expected 1 subscriber, got %d", store.GetSubscriberCount())
     }
 
     subs := store.Subscribers()
-    if subs["user123"] != 5 {
-        t.Errorf("Expected threshold 5 for user123, got %d", subs["user123"])
+    if subs["user123"].Threshold != 5 {
+        t.Errorf("Expected threshold 5 for user123, got %d", subs["user123"].Threshold)
     }
 
     store.Unsubscribe("user123")
@@ -126,3 +126,34 @@ func TestEventDeduplication(t *testing.T) {
         t.Errorf("Expected 1 event after deduplication, got %d", store.GetEventCount())
     }
 }
+
+type recordingNotifier struct {
+    alerts []ThresholdAlert
+}
+
+func (r *recordingNotifier) NotifyThreshold(a ThresholdAlert) {
+    r.alerts = append(r.alerts, a)
+}
+
+func TestThresholdAlertsNotify(t *testing.T) {
+    store := NewMemoryStore()
+    n := &recordingNotifier{}
+    store.SetNotifier(n)
+    
+    store.Subscribe(NewSubscription("user123", 5, Above))
+    
+    store.SetMac(MacGymSnapshot{RetrievedAt: time.Now(), Capacity: 8, InUse: 3})
+    if len(n.alerts) != 0 {
+        t.Fatalf("Expected no alerts below threshold, got %d", len(n.alerts))
+    }
+    
+    store.SetMac(MacGymSnapshot{RetrievedAt: time.Now(), Capacity: 8, InUse: 6})
+    if len(n.alerts) != 1 {
+        t.Fatalf("Expected 1 alert after crossing threshold, got %d", len(n.alerts))
+    }
+    
+    a := n.alerts[0]
+    if a.UserID != "user123" || a.Threshold != 5 || a.Direction != Above || a.Snapshot.InUse != 6 {
+        t.Errorf("Unexpected alert: %+v", a)
+    }
+}
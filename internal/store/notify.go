@@ -0,0 +1,18 @@
+package store
+
+// ThresholdAlert is emitted when a subscriber's occupancy threshold is
+// crossed. Role alerts have a RoleID and GuildID instead of a UserID.
+type ThresholdAlert struct {
+    UserID    string
+    RoleID    string
+    GuildID   string
+    Threshold int
+    Direction Direction
+    Snapshot  MacGymSnapshot
+}
+
+// Notifier receives alerts detected by the store. Implementations must not
+// call back into the store synchronously from Notify methods that block.
+type Notifier interface {
+    NotifyThreshold(a ThresholdAlert)
+}
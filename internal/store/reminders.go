@@ -0,0 +1,99 @@
+package store
+
+import (
+    "log/slog"
+    "sort"
+    "time"
+)
+
+// Reminder is an event start reminder that is due for a user
+type Reminder struct {
+    UserID string
+    Event  Event
+    Lead   time.Duration // how long before the start the user asked to be reminded
+}
+
+// reminderKey identifies the reminder for one user and event. Event IDs hash
+// the start and end times, so a rescheduled event gets a fresh reminder.
+func reminderKey(userID, eventID string) string {
+    return userID + "|" + eventID
+}
+
+// SetReminderLead opts a user in to event start reminders lead before each event
+func (m *MemoryStore) SetReminderLead(userID string, lead time.Duration) {
+    m.mu.Lock()
+    defer m.mu.Unlock()
+
+    m.reminders[userID] = lead
+    slog.Info("User opted in to event reminders", "userID", userID, "lead", lead)
+}
+
+// ClearReminders opts a user out of event start reminders
+func (m *MemoryStore) ClearReminders(userID string) {
+    m.mu.Lock()
+    defer m.mu.Unlock()
+
+    delete(m.reminders, userID)
+    slog.Info("User opted out of event reminders", "userID", userID)
+}
+
+// ReminderLead returns the user's reminder lead time, if they opted in
+func (m *MemoryStore) ReminderLead(userID string) (time.Duration, bool) {
+    m.mu.RLock()
+    defer m.mu.RUnlock()
+
+    lead, ok := m.reminders[userID]
+    return lead, ok
+}
+
+// DueReminders returns the reminders that are due at now and marks them sent,
+// so each user is reminded of an event at most once no matter how often the
+// events are refreshed. Cancelled and pruned events are never reminded of.
+// Reminders are claimed before delivery; a failed delivery is not retried.
+func (m *MemoryStore) DueReminders(now time.Time) []Reminder {
+    due, _, _ := m.dueReminders(now)
+    return due
+}
+
+// dueReminders also returns the sent markers it added and the expired ones it
+// removed, for persistence
+func (m *MemoryStore) dueReminders(now time.Time) (due []Reminder, sent, expired []string) {
+    m.mu.Lock()
+    defer m.mu.Unlock()
+
+    // Once an event has started it can't be due again, so its markers can go
+    for key, start := range m.remindersSent {
+        if !start.After(now) {
+            delete(m.remindersSent, key)
+            expired = append(expired, key)
+        }
+    }
+
+    if len(m.reminders) == 0 {
+        return nil, nil, expired
+    }
+
+    for _, e := range m.events {
+        if e.Cancelled || !e.Start.After(now) {
+            continue
+        }
+        for userID, lead := range m.reminders {
+            if now.Before(e.Start.Add(-lead)) {
+                continue
+            }
+            key := reminderKey(userID, e.ID)
+            if _, done := m.remindersSent[key]; done {
+                continue
+            }
+            m.remindersSent[key] = e.Start
+            sent = append(sent, key)
+            due = append(due, Reminder{UserID: userID, Event: e, Lead: lead})
+        }
+    }
+
+    sort.Slice(due, func(i, j int) bool {
+        return due[i].Event.Start.Before(due[j].Event.Start)
+    })
+
+    return due, sent, expired
+}
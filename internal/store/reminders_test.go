@@ -0,0 +1,90 @@
+package store
+
+import (
+    "path/filepath"
+    "testing"
+    "time"
+)
+
+func TestDueReminders(t *testing.T) {
+    store := NewMemoryStore()
+    now := time.Date(2024, 1, 15, 12, 0, 0, 0, time.UTC)
+
+    soon := testEvent("Open Play", now.Add(20*time.Minute))
+    later := testEvent("Club Practice", now.Add(90*time.Minute))
+    store.ApplyRefresh(Refresh{Source: "fitness", Events: []Event{soon, later}, At: now})
+
+    store.SetReminderLead("user30", 30*time.Minute)
+    store.SetReminderLead("user120", 2*time.Hour)
+
+    due := store.DueReminders(now)
+    if len(due) != 3 {
+        t.Fatalf("Expected 3 due reminders, got %+v", due)
+    }
+    if due[0].Event.ID != soon.ID || due[2].Event.ID != later.ID {
+        t.Errorf("Expected reminders sorted by event start, got %+v", due)
+    }
+
+    // Refreshing the same listing must not make reminders fire again
+    store.ApplyRefresh(Refresh{Source: "fitness", Events: []Event{soon, later}, At: now.Add(time.Minute)})
+    if due := store.DueReminders(now.Add(time.Minute)); len(due) != 0 {
+        t.Errorf("Expected no repeated reminders, got %+v", due)
+    }
+
+    // A reminder for a cancelled event never fires
+    store.ApplyRefresh(Refresh{Source: "fitness", Events: []Event{soon}, At: now.Add(2 * time.Minute), Until: later.Start})
+    if due := store.DueReminders(now.Add(time.Hour)); len(due) != 0 {
+        t.Errorf("Expected no reminder for a cancelled event, got %+v", due)
+    }
+
+    store.ClearReminders("user120")
+    if _, ok := store.ReminderLead("user120"); ok {
+        t.Error("Expected reminders to be cleared")
+    }
+    if lead, ok := store.ReminderLead("user30"); !ok || lead != 30*time.Minute {
+        t.Errorf("Expected 30m lead for user30, got %v (ok=%v)", lead, ok)
+    }
+
+    // Sent markers are dropped once the event has started
+    store.DueReminders(later.Start.Add(time.Minute))
+    if len(store.remindersSent) != 0 {
+        t.Errorf("Expected sent markers to expire, got %v", store.remindersSent)
+    }
+}
+
+func TestBoltRemindersPersistence(t *testing.T) {
+    path := filepath.Join(t.TempDir(), "bot.db")
+    now := time.Now().Truncate(time.Second)
+
+    s, err := OpenBolt(path, DefaultHistoryRetention)
+    if err != nil {
+        t.Fatalf("Failed to open bolt store: %v", err)
+    }
+
+    soon := testEvent("Open Play", now.Add(20*time.Minute))
+    s.UpsertEvents([]Event{soon})
+    s.SetReminderLead("user1", time.Hour)
+    s.SetReminderLead("user2", time.Hour)
+    s.ClearReminders("user2")
+
+    if due := s.DueReminders(now); len(due) != 1 {
+        t.Fatalf("Expected 1 due reminder, got %+v", due)
+    }
+    s.Close()
+
+    s, err = OpenBolt(path, DefaultHistoryRetention)
+    if err != nil {
+        t.Fatalf("Failed to reopen bolt store: %v", err)
+    }
+    defer s.Close()
+
+    if lead, ok := s.ReminderLead("user1"); !ok || lead != time.Hour {
+        t.Errorf("Expected persisted 1h lead, got %v (ok=%v)", lead, ok)
+    }
+    if _, ok := s.ReminderLead("user2"); ok {
+        t.Error("Expected cleared reminder to stay cleared after reopen")
+    }
+    if due := s.DueReminders(now.Add(time.Minute)); len(due) != 0 {
+        t.Errorf("Expected reminder not to fire again after restart, got %+v", due)
+    }
+}
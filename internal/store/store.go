@@ -0,0 +1,73 @@
+package store
+
+import (
+    "fmt"
+    "time"
+)
+
+// Store is the storage boundary shared by the scheduler and the Discord handlers
+type Store interface {
+    SetNotifier(n Notifier)
+    SetMac(s MacGymSnapshot)
+    GetMac() MacGymSnapshot
+    UpsertEvents(es []Event)
+    ApplyRefresh(r Refresh) ChangeSet
+    PruneEvents(now time.Time, retention time.Duration) []Event
+    SourceLastSeen(source string) time.Time
+    SourceEventCount(source string) int
+    ListUpcoming(now time.Time, days int) []Event
+    Subscribe(sub Subscription)
+    Unsubscribe(key string)
+    Subscribers() map[string]Subscription
+    SetAlertChannel(guildID, channelID string)
+    AlertChannel(guildID string) string
+    SetQuietHours(userID string, r ClockRange)
+    QuietHours(userID string) ClockRange
+    SetDigest(userID string, f DigestFrequency)
+    Digest(userID string) DigestFrequency
+    DigestSubscribers(f DigestFrequency) []string
+    GetEventCount() int
+    GetSubscriberCount() int
+    SetReminderLead(userID string, lead time.Duration)
+    ClearReminders(userID string)
+    ReminderLead(userID string) (time.Duration, bool)
+    DueReminders(now time.Time) []Reminder
+    History(since, until time.Time) []MacGymSnapshot
+    Close() error
+}
+
+// Options selects and configures the store returned by Open
+type Options struct {
+    Backend          string // "memory" or "bolt"
+    Path             string // database file for the bolt backend
+    HistoryRetention time.Duration
+    AlertCooldown    time.Duration
+    Location         *time.Location // zone of active windows and quiet hours
+}
+
+// Open returns the store for the configured backend
+func Open(opts Options) (Store, error) {
+    switch opts.Backend {
+    case "", "memory":
+        m := NewMemoryStore()
+        m.SetHistoryRetention(opts.HistoryRetention)
+        m.SetAlertCooldown(opts.AlertCooldown)
+        m.SetLocation(opts.Location)
+        return m, nil
+    case "bolt":
+        b, err := OpenBolt(opts.Path, opts.HistoryRetention)
+        if err != nil {
+            return nil, err
+        }
+        b.SetAlertCooldown(opts.AlertCooldown)
+        b.SetLocation(opts.Location)
+        return b, nil
+    default:
+        return nil, fmt.Errorf("unknown store backend: %s", opts.Backend)
+    }
+}
+
+var (
+    _ Store = (*MemoryStore)(nil)
+    _ Store = (*BoltStore)(nil)
+)
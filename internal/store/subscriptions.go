@@ -0,0 +1,189 @@
+package store
+
+import (
+    "log/slog"
+    "time"
+)
+
+// Direction is which way occupancy has to move through a threshold to alert
+type Direction string
+
+const (
+    // Above alerts when courts in use rise to the threshold or more
+    Above Direction = "above"
+    // Below alerts when courts in use drop below the threshold
+    Below Direction = "below"
+)
+
+// DefaultHysteresis is how many courts a reading has to move back past the
+// threshold before a subscription can alert again. With a threshold of 6
+// above, readings bouncing between 5 and 6 alert once; it takes a reading of
+// 4 to re-arm.
+const DefaultHysteresis = 1
+
+// DefaultAlertCooldown is the minimum time between two alerts to the same
+// subscriber
+const DefaultAlertCooldown = 5 * time.Minute
+
+// Subscription is a user's or role's occupancy alert. Role subscriptions are
+// posted once to their guild's alert channel with a role mention.
+type Subscription struct {
+    UserID     string
+    RoleID     string
+    GuildID    string // guild the role belongs to
+    Threshold  int
+    Direction  Direction
+    Hysteresis int  // courts in the dead band between alerting and re-arming
+    Armed      bool // the next crossing alerts; cleared when it does
+    LastAlert  time.Time
+    Active     Window // when alerts may be sent; zero means any time
+}
+
+// NewSubscription returns an armed subscription with the default hysteresis
+func NewSubscription(userID string, threshold int, dir Direction) Subscription {
+    if dir != Below {
+        dir = Above
+    }
+    return Subscription{
+        UserID:     userID,
+        Threshold:  threshold,
+        Direction:  dir,
+        Hysteresis: DefaultHysteresis,
+        Armed:      true,
+    }
+}
+
+// NewRoleSubscription returns an armed subscription for a guild role
+func NewRoleSubscription(guildID, roleID string, threshold int, dir Direction) Subscription {
+    sub := NewSubscription("", threshold, dir)
+    sub.RoleID = roleID
+    sub.GuildID = guildID
+    return sub
+}
+
+// RoleKey is the key a role subscription is stored under
+func RoleKey(roleID string) string {
+    return "role:" + roleID
+}
+
+// Key returns the key the subscription is stored under: the user ID, or
+// RoleKey for role subscriptions
+func (s Subscription) Key() string {
+    if s.RoleID != "" {
+        return RoleKey(s.RoleID)
+    }
+    return s.UserID
+}
+
+// alerts reports whether the subscription fires occupancy alerts at all. A
+// threshold of zero is a general subscription with no occupancy alert.
+func (s Subscription) alerts() bool {
+    return s.Threshold > 0
+}
+
+// triggered reports whether inUse is on the alerting side of the threshold
+func (s Subscription) triggered(inUse int) bool {
+    if s.Direction == Below {
+        return inUse < s.Threshold
+    }
+    return inUse >= s.Threshold
+}
+
+// rearms reports whether inUse is far enough back past the threshold, beyond
+// the hysteresis band, for the subscription to alert again
+func (s Subscription) rearms(inUse int) bool {
+    if s.Direction == Below {
+        return inUse >= s.Threshold+s.Hysteresis
+    }
+    return inUse < s.Threshold-s.Hysteresis
+}
+
+// muted reports whether alerts must be held at local time t, either because
+// it's outside the subscription's active window or in the user's quiet hours
+func (s Subscription) muted(t time.Time, quiet ClockRange) bool {
+    return !s.Active.Contains(t) || quiet.Contains(t)
+}
+
+// update applies a reading taken at to the subscription's arming state and
+// reports whether it should alert and whether the state changed. A crossing
+// within cooldown of the last alert leaves the subscription armed, so it
+// alerts on the first reading after the cooldown if the condition still holds.
+func (s *Subscription) update(inUse int, at time.Time, cooldown time.Duration) (fire, changed bool) {
+    switch {
+    case s.Armed && s.triggered(inUse):
+        if !s.LastAlert.IsZero() && at.Sub(s.LastAlert) < cooldown {
+            return false, false
+        }
+        s.Armed = false
+        s.LastAlert = at
+        return true, true
+    case !s.Armed && s.rearms(inUse):
+        s.Armed = true
+        return false, true
+    }
+    return false, false
+}
+
+// SetAlertCooldown changes the minimum time between alerts to one subscriber
+func (m *MemoryStore) SetAlertCooldown(d time.Duration) {
+    m.mu.Lock()
+    defer m.mu.Unlock()
+
+    if d <= 0 {
+        d = DefaultAlertCooldown
+    }
+    m.cooldown = d
+}
+
+// SetLocation sets the time zone that active windows and quiet hours are in
+func (m *MemoryStore) SetLocation(loc *time.Location) {
+    m.mu.Lock()
+    defer m.mu.Unlock()
+
+    if loc == nil {
+        loc = time.Local
+    }
+    m.loc = loc
+}
+
+// SetQuietHours sets the daily range during which a user gets no occupancy
+// alerts. A zero range clears it.
+func (m *MemoryStore) SetQuietHours(userID string, r ClockRange) {
+    m.mu.Lock()
+    defer m.mu.Unlock()
+
+    if r.IsZero() {
+        delete(m.quiet, userID)
+    } else {
+        m.quiet[userID] = r
+    }
+    slog.Info("User set quiet hours", "userID", userID, "quietHours", r.String())
+}
+
+// QuietHours returns a user's quiet hours, or a zero range if none are set
+func (m *MemoryStore) QuietHours(userID string) ClockRange {
+    m.mu.RLock()
+    defer m.mu.RUnlock()
+    return m.quiet[userID]
+}
+
+// SetAlertChannel sets the channel role alerts are posted to in a guild. An
+// empty channel ID clears it.
+func (m *MemoryStore) SetAlertChannel(guildID, channelID string) {
+    m.mu.Lock()
+    defer m.mu.Unlock()
+
+    if channelID == "" {
+        delete(m.channels, guildID)
+    } else {
+        m.channels[guildID] = channelID
+    }
+    slog.Info("Set guild alert channel", "guildID", guildID, "channelID", channelID)
+}
+
+// AlertChannel returns a guild's alert channel, or "" if none is set
+func (m *MemoryStore) AlertChannel(guildID string) string {
+    m.mu.RLock()
+    defer m.mu.RUnlock()
+    return m.channels[guildID]
+}
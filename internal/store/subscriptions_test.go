@@ -0,0 +1,320 @@
+package store
+
+import (
+    "encoding/binary"
+    "path/filepath"
+    "testing"
+    "time"
+
+    bolt "go.etcd.io/bbolt"
+)
+
+func TestSubscriptionHysteresis(t *testing.T) {
+    testCases := []struct {
+        name      string
+        sub       Subscription
+        readings  []int
+        fireAfter []int // indexes of readings that alert
+    }{
+        {
+            name:      "above fires once while oscillating at threshold",
+            sub:       NewSubscription("u", 6, Above),
+            readings:  []int{3, 6, 5, 6, 5, 6},
+            fireAfter: []int{1},
+        },
+        {
+            name:      "above re-arms below the band",
+            sub:       NewSubscription("u", 6, Above),
+            readings:  []int{3, 6, 4, 6},
+            fireAfter: []int{1, 3},
+        },
+        {
+            name:      "below fires when courts free up",
+            sub:       NewSubscription("u", 4, Below),
+            readings:  []int{6, 3, 4, 3, 5, 2},
+            fireAfter: []int{1, 5},
+        },
+        {
+            name:      "zero hysteresis behaves like a plain crossing",
+            sub:       Subscription{UserID: "u", Threshold: 6, Direction: Above, Armed: true},
+            readings:  []int{6, 5, 6},
+            fireAfter: []int{0, 2},
+        },
+    }
+
+    for _, tc := range testCases {
+        t.Run(tc.name, func(t *testing.T) {
+            sub := tc.sub
+            start := time.Date(2024, 1, 15, 12, 0, 0, 0, time.UTC)
+            var fired []int
+            for i, r := range tc.readings {
+                // Readings are spaced well beyond the cooldown
+                at := start.Add(time.Duration(i) * time.Hour)
+                if fire, _ := sub.update(r, at, DefaultAlertCooldown); fire {
+                    fired = append(fired, i)
+                }
+            }
+            if len(fired) != len(tc.fireAfter) {
+                t.Fatalf("Expected alerts at %v, got %v", tc.fireAfter, fired)
+            }
+            for i := range fired {
+                if fired[i] != tc.fireAfter[i] {
+                    t.Fatalf("Expected alerts at %v, got %v", tc.fireAfter, fired)
+                }
+            }
+        })
+    }
+}
+
+func TestAlertCooldownPerSubscriber(t *testing.T) {
+    store := NewMemoryStore()
+    store.SetAlertCooldown(10 * time.Minute)
+    n := &recordingNotifier{}
+    store.SetNotifier(n)
+
+    start := time.Date(2024, 1, 15, 12, 0, 0, 0, time.UTC)
+    reading := func(offset time.Duration, inUse int) {
+        store.SetMac(MacGymSnapshot{RetrievedAt: start.Add(offset), Capacity: 8, InUse: inUse})
+    }
+
+    store.Subscribe(NewSubscription("busy5", 5, Above))
+    store.Subscribe(NewSubscription("busy6", 6, Above))
+    store.Subscribe(NewSubscription("quiet3", 3, Below))
+
+    reading(0, 4)
+    if len(n.alerts) != 0 {
+        t.Fatalf("Expected no alerts at 4 in use, got %+v", n.alerts)
+    }
+
+    // One snapshot crossing two thresholds must alert both subscribers
+    reading(2*time.Minute, 7)
+    if got := alertedUsers(n.alerts); len(got) != 2 || !got["busy5"] || !got["busy6"] {
+        t.Fatalf("Expected busy5 and busy6 to be alerted, got %+v", n.alerts)
+    }
+    n.alerts = nil
+
+    // Another subscriber's recent alert doesn't hold back quiet3
+    reading(4*time.Minute, 2)
+    if got := alertedUsers(n.alerts); len(got) != 1 || !got["quiet3"] {
+        t.Fatalf("Expected only quiet3 to be alerted, got %+v", n.alerts)
+    }
+    n.alerts = nil
+
+    // busy5 re-arms at 2 but crossing again within its cooldown is held
+    reading(6*time.Minute, 6)
+    if len(n.alerts) != 0 {
+        t.Fatalf("Expected alerts within the cooldown to be held, got %+v", n.alerts)
+    }
+
+    // Once the cooldown has passed the held crossing alerts
+    reading(13*time.Minute, 6)
+    if got := alertedUsers(n.alerts); len(got) != 2 || !got["busy5"] || !got["busy6"] {
+        t.Fatalf("Expected busy5 and busy6 to be alerted after the cooldown, got %+v", n.alerts)
+    }
+
+    if last := store.Subscribers()["busy5"].LastAlert; !last.Equal(start.Add(13 * time.Minute)) {
+        t.Errorf("Expected busy5 last alert at %v, got %v", start.Add(13*time.Minute), last)
+    }
+}
+
+func TestAlertsRespectActiveWindowAndQuietHours(t *testing.T) {
+    store := NewMemoryStore()
+    store.SetLocation(time.UTC)
+    n := &recordingNotifier{}
+    store.SetNotifier(n)
+
+    evenings := NewSubscription("evenings", 6, Above)
+    evenings.Active = Window{Days: weekdays, Hours: ClockRange{17 * 60, 22 * 60}}
+    store.Subscribe(evenings)
+    store.Subscribe(NewSubscription("sleeper", 6, Above))
+    store.SetQuietHours("sleeper", ClockRange{22 * 60, 8 * 60})
+
+    // Monday 7am: busy, but evenings is outside its window and sleeper is in quiet hours
+    monday := time.Date(2024, 1, 15, 0, 0, 0, 0, time.UTC)
+    store.SetMac(MacGymSnapshot{RetrievedAt: monday.Add(7 * time.Hour), Capacity: 8, InUse: 7})
+    if len(n.alerts) != 0 {
+        t.Fatalf("Expected muted alerts to be held, got %+v", n.alerts)
+    }
+
+    // 9am: sleeper's quiet hours are over and it's still busy
+    store.SetMac(MacGymSnapshot{RetrievedAt: monday.Add(9 * time.Hour), Capacity: 8, InUse: 7})
+    if got := alertedUsers(n.alerts); len(got) != 1 || !got["sleeper"] {
+        t.Fatalf("Expected only sleeper to be alerted, got %+v", n.alerts)
+    }
+    n.alerts = nil
+
+    // 6pm: the evening window opens with the condition still holding
+    store.SetMac(MacGymSnapshot{RetrievedAt: monday.Add(18 * time.Hour), Capacity: 8, InUse: 6})
+    if got := alertedUsers(n.alerts); len(got) != 1 || !got["evenings"] {
+        t.Fatalf("Expected only evenings to be alerted, got %+v", n.alerts)
+    }
+
+    store.SetQuietHours("sleeper", ClockRange{})
+    if !store.QuietHours("sleeper").IsZero() {
+        t.Error("Expected quiet hours to be cleared")
+    }
+}
+
+func TestBoltQuietHoursPersistence(t *testing.T) {
+    path := filepath.Join(t.TempDir(), "bot.db")
+
+    s, err := OpenBolt(path, DefaultHistoryRetention)
+    if err != nil {
+        t.Fatalf("Failed to open bolt store: %v", err)
+    }
+
+    evenings := NewSubscription("user1", 6, Above)
+    evenings.Active = Window{Days: []time.Weekday{time.Monday, time.Wednesday}, Hours: ClockRange{17 * 60, 22 * 60}}
+    s.Subscribe(evenings)
+    s.SetQuietHours("user1", ClockRange{22 * 60, 7 * 60})
+    s.SetQuietHours("user2", ClockRange{23 * 60, 6 * 60})
+    s.SetQuietHours("user2", ClockRange{})
+    s.Close()
+
+    s, err = OpenBolt(path, DefaultHistoryRetention)
+    if err != nil {
+        t.Fatalf("Failed to reopen bolt store: %v", err)
+    }
+    defer s.Close()
+
+    if got := s.QuietHours("user1"); got != (ClockRange{22 * 60, 7 * 60}) {
+        t.Errorf("Expected persisted quiet hours, got %+v", got)
+    }
+    if got := s.QuietHours("user2"); !got.IsZero() {
+        t.Errorf("Expected cleared quiet hours to stay cleared, got %+v", got)
+    }
+    if got := s.Subscribers()["user1"].Active.String(); got != "Mon, Wed, 17:00–22:00" {
+        t.Errorf("Expected persisted active window, got %q", got)
+    }
+}
+
+func TestRoleSubscriptions(t *testing.T) {
+    store := NewMemoryStore()
+    n := &recordingNotifier{}
+    store.SetNotifier(n)
+
+    store.Subscribe(NewSubscription("user1", 6, Above))
+    store.Subscribe(NewRoleSubscription("guild1", "role1", 6, Above))
+
+    if got := len(store.Subscribers()); got != 2 {
+        t.Fatalf("Expected user and role subscriptions to be kept apart, got %d", got)
+    }
+
+    store.SetMac(MacGymSnapshot{RetrievedAt: time.Now(), Capacity: 8, InUse: 7})
+    if len(n.alerts) != 2 {
+        t.Fatalf("Expected a user alert and a role alert, got %+v", n.alerts)
+    }
+    for _, a := range n.alerts {
+        if a.RoleID != "" && (a.RoleID != "role1" || a.GuildID != "guild1" || a.UserID != "") {
+            t.Errorf("Unexpected role alert %+v", a)
+        }
+    }
+
+    store.Unsubscribe(RoleKey("role1"))
+    if _, ok := store.Subscribers()["user1"]; !ok || len(store.Subscribers()) != 1 {
+        t.Errorf("Expected only the role subscription to be removed, got %v", store.Subscribers())
+    }
+}
+
+func TestBoltRoleSubscriptionsAndChannels(t *testing.T) {
+    path := filepath.Join(t.TempDir(), "bot.db")
+
+    s, err := OpenBolt(path, DefaultHistoryRetention)
+    if err != nil {
+        t.Fatalf("Failed to open bolt store: %v", err)
+    }
+
+    s.Subscribe(NewRoleSubscription("guild1", "role1", 3, Below))
+    s.SetAlertChannel("guild1", "channel1")
+    s.SetAlertChannel("guild2", "channel2")
+    s.SetAlertChannel("guild2", "")
+    s.Close()
+
+    s, err = OpenBolt(path, DefaultHistoryRetention)
+    if err != nil {
+        t.Fatalf("Failed to reopen bolt store: %v", err)
+    }
+    defer s.Close()
+
+    sub, ok := s.Subscribers()[RoleKey("role1")]
+    if !ok || sub.GuildID != "guild1" || sub.Threshold != 3 || sub.Direction != Below {
+        t.Errorf("Expected persisted role subscription, got %+v", sub)
+    }
+    if got := s.AlertChannel("guild1"); got != "channel1" {
+        t.Errorf("Expected guild1 alert channel, got %q", got)
+    }
+    if got := s.AlertChannel("guild2"); got != "" {
+        t.Errorf("Expected guild2 alert channel to stay cleared, got %q", got)
+    }
+}
+
+func alertedUsers(alerts []ThresholdAlert) map[string]bool {
+    users := make(map[string]bool)
+    for _, a := range alerts {
+        users[a.UserID] = true
+    }
+    return users
+}
+
+func TestSubscribeWhileTriggeredStartsDisarmed(t *testing.T) {
+    store := NewMemoryStore()
+    n := &recordingNotifier{}
+    store.SetNotifier(n)
+
+    store.SetMac(MacGymSnapshot{RetrievedAt: time.Now(), Capacity: 8, InUse: 2})
+    store.Subscribe(NewSubscription("user123", 4, Below))
+
+    if store.Subscribers()["user123"].Armed {
+        t.Error("Expected subscription to start disarmed when its condition already holds")
+    }
+
+    store.SetMac(MacGymSnapshot{RetrievedAt: time.Now().Add(time.Minute), Capacity: 8, InUse: 1})
+    if len(n.alerts) != 0 {
+        t.Errorf("Expected no alert without a fresh crossing, got %+v", n.alerts)
+    }
+}
+
+func TestBoltSubscriptionMigration(t *testing.T) {
+    path := filepath.Join(t.TempDir(), "bot.db")
+
+    // Build a version 4 database holding a bare threshold
+    db, err := bolt.Open(path, 0o600, nil)
+    if err != nil {
+        t.Fatalf("Failed to create database: %v", err)
+    }
+    err = db.Update(func(tx *bolt.Tx) error {
+        for _, m := range migrations[:4] {
+            if err := m(tx); err != nil {
+                return err
+            }
+        }
+        meta, err := tx.CreateBucketIfNotExists(bucketMeta)
+        if err != nil {
+            return err
+        }
+        v := make([]byte, 8)
+        binary.BigEndian.PutUint64(v, 4)
+        if err := meta.Put(keySchemaVersion, v); err != nil {
+            return err
+        }
+        return tx.Bucket(bucketSubs).Put([]byte("user123"), []byte("5"))
+    })
+    db.Close()
+    if err != nil {
+        t.Fatalf("Failed to seed database: %v", err)
+    }
+
+    s, err := OpenBolt(path, DefaultHistoryRetention)
+    if err != nil {
+        t.Fatalf("Failed to open migrated store: %v", err)
+    }
+    defer s.Close()
+
+    sub, ok := s.Subscribers()["user123"]
+    if !ok {
+        t.Fatal("Expected subscription to survive migration")
+    }
+    if sub.Threshold != 5 || sub.Direction != Above || sub.Hysteresis != DefaultHysteresis || !sub.Armed {
+        t.Errorf("Unexpected migrated subscription: %+v", sub)
+    }
+}
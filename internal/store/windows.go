@@ -0,0 +1,203 @@
+package store
+
+import (
+    "fmt"
+    "sort"
+    "strings"
+    "time"
+
+    "github.com/sjsu-badminton/badminton-discord-bot/internal/util"
+)
+
+// ClockRange is a daily time-of-day range in minutes since midnight, with an
+// exclusive end. A range whose end is before its start wraps past midnight,
+// e.g. 22:00-07:00. The zero value is no range.
+type ClockRange struct {
+    Start int
+    End   int
+}
+
+// IsZero reports whether the range is unset
+func (r ClockRange) IsZero() bool {
+    return r.Start == r.End
+}
+
+// Contains reports whether t's time of day, in t's location, is in the range
+func (r ClockRange) Contains(t time.Time) bool {
+    if r.IsZero() {
+        return false
+    }
+    m := t.Hour()*60 + t.Minute()
+    if r.Start < r.End {
+        return m >= r.Start && m < r.End
+    }
+    return m >= r.Start || m < r.End
+}
+
+func (r ClockRange) String() string {
+    if r.IsZero() {
+        return "all day"
+    }
+    return fmt.Sprintf("%02d:%02d–%02d:%02d", r.Start/60, r.Start%60, r.End/60, r.End%60)
+}
+
+// ParseClockRange parses ranges like "17:00-22:00", "17-22" or "5pm-10pm".
+// "24:00" is accepted as an end meaning midnight.
+func ParseClockRange(s string) (ClockRange, error) {
+    s = strings.ToLower(strings.Join(strings.Fields(s), ""))
+    for _, sep := range []string{"–", "to"} {
+        s = strings.ReplaceAll(s, sep, "-")
+    }
+
+    parts := strings.Split(s, "-")
+    if len(parts) != 2 {
+        return ClockRange{}, fmt.Errorf("invalid time range %q, expected e.g. 17:00-22:00", s)
+    }
+
+    start, err := parseClockMinutes(parts[0])
+    if err != nil {
+        return ClockRange{}, err
+    }
+    end, err := parseClockMinutes(parts[1])
+    if err != nil {
+        return ClockRange{}, err
+    }
+
+    r := ClockRange{Start: start % (24 * 60), End: end % (24 * 60)}
+    if r.IsZero() {
+        return ClockRange{}, fmt.Errorf("time range %q is empty", s)
+    }
+    return r, nil
+}
+
+func parseClockMinutes(s string) (int, error) {
+    if s == "24:00" || s == "24" {
+        return 24 * 60, nil
+    }
+    for _, layout := range []string{"15:04", "15", "3pm", "3:04pm"} {
+        if t, err := time.Parse(layout, s); err == nil {
+            return t.Hour()*60 + t.Minute(), nil
+        }
+    }
+    return 0, fmt.Errorf("invalid time %q", s)
+}
+
+// Window is when a subscription may alert. Days are checked against the local
+// date of the reading; no days means every day, and a zero Hours means all day.
+type Window struct {
+    Days  []time.Weekday
+    Hours ClockRange
+}
+
+// IsZero reports whether the window is unrestricted
+func (w Window) IsZero() bool {
+    return len(w.Days) == 0 && w.Hours.IsZero()
+}
+
+// Contains reports whether t, in t's location, falls in the window
+func (w Window) Contains(t time.Time) bool {
+    if len(w.Days) > 0 && !containsWeekday(w.Days, t.Weekday()) {
+        return false
+    }
+    return w.Hours.IsZero() || w.Hours.Contains(t)
+}
+
+func (w Window) String() string {
+    if w.IsZero() {
+        return "any time"
+    }
+    return formatDays(w.Days) + ", " + w.Hours.String()
+}
+
+var (
+    weekdays = []time.Weekday{time.Monday, time.Tuesday, time.Wednesday, time.Thursday, time.Friday}
+    weekends = []time.Weekday{time.Sunday, time.Saturday}
+)
+
+// ParseDays parses "weekdays", "weekends", "daily", or a comma separated list
+// of days and day ranges such as "mon,wed,fri" or "mon-thu". Every day is
+// returned as nil.
+func ParseDays(s string) ([]time.Weekday, error) {
+    s = strings.ToLower(strings.TrimSpace(s))
+    switch s {
+    case "", "daily", "everyday", "every day", "all":
+        return nil, nil
+    case "weekdays":
+        return append([]time.Weekday(nil), weekdays...), nil
+    case "weekends":
+        return append([]time.Weekday(nil), weekends...), nil
+    }
+
+    seen := make(map[time.Weekday]bool)
+    for _, part := range strings.FieldsFunc(s, func(r rune) bool { return r == ',' || r == ' ' }) {
+        from, to, isRange := strings.Cut(part, "-")
+        first, ok := util.ParseWeekday(from)
+        if !ok {
+            return nil, fmt.Errorf("invalid day %q", from)
+        }
+        last := first
+        if isRange {
+            if last, ok = util.ParseWeekday(to); !ok {
+                return nil, fmt.Errorf("invalid day %q", to)
+            }
+        }
+        for d := first; ; d = (d + 1) % 7 {
+            seen[d] = true
+            if d == last {
+                break
+            }
+        }
+    }
+
+    if len(seen) == 0 {
+        return nil, fmt.Errorf("no days in %q", s)
+    }
+    if len(seen) == 7 {
+        return nil, nil
+    }
+
+    days := make([]time.Weekday, 0, len(seen))
+    for d := range seen {
+        days = append(days, d)
+    }
+    sort.Slice(days, func(i, j int) bool { return days[i] < days[j] })
+    return days, nil
+}
+
+func containsWeekday(days []time.Weekday, d time.Weekday) bool {
+    for _, x := range days {
+        if x == d {
+            return true
+        }
+    }
+    return false
+}
+
+func sameDays(a, b []time.Weekday) bool {
+    if len(a) != len(b) {
+        return false
+    }
+    for _, d := range a {
+        if !containsWeekday(b, d) {
+            return false
+        }
+    }
+    return true
+}
+
+func formatDays(days []time.Weekday) string {
+    switch {
+    case len(days) == 0:
+        return "every day"
+    case sameDays(days, weekdays):
+        return "weekdays"
+    case sameDays(days, weekends):
+        return "weekends"
+    }
+
+    names := make([]string, len(days))
+    for i, d := range days {
+        names[i] = d.String()[:3]
+    }
+    return strings.Join(names, ", ")
+}
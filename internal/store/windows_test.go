@@ -0,0 +1,101 @@
+package store
+
+import (
+    "testing"
+    "time"
+)
+
+func TestParseClockRange(t *testing.T) {
+    testCases := []struct {
+        input    string
+        expected ClockRange
+        ok       bool
+    }{
+        {"17:00-22:00", ClockRange{17 * 60, 22 * 60}, true},
+        {"17-22", ClockRange{17 * 60, 22 * 60}, true},
+        {"5pm - 10:30pm", ClockRange{17 * 60, 22*60 + 30}, true},
+        {"22:00–07:00", ClockRange{22 * 60, 7 * 60}, true},
+        {"9am to 5pm", ClockRange{9 * 60, 17 * 60}, true},
+        {"18:00-24:00", ClockRange{18 * 60, 0}, true},
+        {"10-10", ClockRange{}, false},
+        {"25:00-26:00", ClockRange{}, false},
+        {"evening", ClockRange{}, false},
+    }
+
+    for _, tc := range testCases {
+        t.Run(tc.input, func(t *testing.T) {
+            r, err := ParseClockRange(tc.input)
+            if (err == nil) != tc.ok {
+                t.Fatalf("ParseClockRange(%q) error = %v, want ok=%v", tc.input, err, tc.ok)
+            }
+            if r != tc.expected {
+                t.Errorf("ParseClockRange(%q) = %+v, want %+v", tc.input, r, tc.expected)
+            }
+        })
+    }
+}
+
+func TestParseDays(t *testing.T) {
+    testCases := []struct {
+        input    string
+        expected string
+        ok       bool
+    }{
+        {"weekdays", "weekdays", true},
+        {"Weekends", "weekends", true},
+        {"daily", "every day", true},
+        {"mon,wed,fri", "Mon, Wed, Fri", true},
+        {"mon-fri", "weekdays", true},
+        {"fri-mon", "Sun, Mon, Fri, Sat", true},
+        {"sun-sat", "every day", true},
+        {"mon,someday", "", false},
+    }
+
+    for _, tc := range testCases {
+        t.Run(tc.input, func(t *testing.T) {
+            days, err := ParseDays(tc.input)
+            if (err == nil) != tc.ok {
+                t.Fatalf("ParseDays(%q) error = %v, want ok=%v", tc.input, err, tc.ok)
+            }
+            if tc.ok && formatDays(days) != tc.expected {
+                t.Errorf("ParseDays(%q) = %s, want %s", tc.input, formatDays(days), tc.expected)
+            }
+        })
+    }
+}
+
+func TestWindowContains(t *testing.T) {
+    evenings := Window{Days: weekdays, Hours: ClockRange{17 * 60, 22 * 60}}
+    overnight := ClockRange{22 * 60, 7 * 60}
+
+    // Monday 2024-01-15
+    at := func(day, hour, minute int) time.Time {
+        return time.Date(2024, 1, 15+day, hour, minute, 0, 0, time.UTC)
+    }
+
+    testCases := []struct {
+        name     string
+        contains bool
+        expected bool
+    }{
+        {"weekday evening", evenings.Contains(at(0, 18, 0)), true},
+        {"weekday morning", evenings.Contains(at(0, 7, 0)), false},
+        {"window end is exclusive", evenings.Contains(at(0, 22, 0)), false},
+        {"weekend evening", evenings.Contains(at(5, 18, 0)), false},
+        {"zero window is always open", Window{}.Contains(at(5, 3, 0)), true},
+        {"overnight late", overnight.Contains(at(0, 23, 30)), true},
+        {"overnight early", overnight.Contains(at(0, 6, 59)), true},
+        {"overnight daytime", overnight.Contains(at(0, 12, 0)), false},
+        {"zero range contains nothing", ClockRange{}.Contains(at(0, 12, 0)), false},
+    }
+
+    for _, tc := range testCases {
+        if tc.contains != tc.expected {
+            t.Errorf("%s: got %v, want %v", tc.name, tc.contains, tc.expected)
+        }
+    }
+
+    if got := evenings.String(); got != "weekdays, 17:00–22:00" {
+        t.Errorf("Unexpected window string %q", got)
+    }
+}
@@ -0,0 +1,198 @@
+package util
+
+import (
+    "errors"
+    "fmt"
+    "log/slog"
+    "sort"
+    "sync"
+    "time"
+)
+
+// ErrCircuitOpen is returned instead of making a request to a host whose
+// circuit breaker is open
+var ErrCircuitOpen = errors.New("circuit breaker open")
+
+const (
+    defaultBreakerThreshold   = 3
+    defaultBreakerCooldown    = 5 * time.Minute
+    defaultBreakerMaxCooldown = time.Hour
+)
+
+// BreakerState is the state of one host's circuit breaker
+type BreakerState int
+
+const (
+    // BreakerClosed lets every request through
+    BreakerClosed BreakerState = iota
+    // BreakerOpen rejects requests until the cooldown has passed
+    BreakerOpen
+    // BreakerHalfOpen lets a single trial request through
+    BreakerHalfOpen
+)
+
+func (s BreakerState) String() string {
+    switch s {
+    case BreakerClosed:
+        return "closed"
+    case BreakerOpen:
+        return "open"
+    case BreakerHalfOpen:
+        return "half-open"
+    }
+    return fmt.Sprintf("BreakerState(%d)", int(s))
+}
+
+// BreakerOptions configures when breakers open and for how long
+type BreakerOptions struct {
+    // Threshold is the number of failed requests in a row that opens a
+    // breaker; defaults to 3
+    Threshold int
+    // Cooldown is how long a breaker stays open before a trial request.
+    // It doubles each time the trial fails, up to MaxCooldown. Defaults to
+    // 5 minutes and an hour.
+    Cooldown    time.Duration
+    MaxCooldown time.Duration
+}
+
+// BreakerStatus describes one host's circuit breaker
+type BreakerStatus struct {
+    Host     string
+    State    BreakerState
+    Failures int
+    // Cooldown is how long the breaker stays open the next time it opens
+    // from half-open
+    Cooldown  time.Duration
+    OpenedAt  time.Time
+    RetryAt   time.Time
+    LastError string
+}
+
+// Breakers keeps a circuit breaker per host, so a dead upstream fails fast
+// instead of being retried on every scheduled scrape
+type Breakers struct {
+    opts BreakerOptions
+    now  func() time.Time
+
+    mu    sync.Mutex
+    hosts map[string]*BreakerStatus
+}
+
+// NewBreakers returns an empty set of breakers, filling in defaults for any
+// unset options
+func NewBreakers(opts BreakerOptions) *Breakers {
+    if opts.Threshold < 1 {
+        opts.Threshold = defaultBreakerThreshold
+    }
+    if opts.Cooldown <= 0 {
+        opts.Cooldown = defaultBreakerCooldown
+    }
+    if opts.MaxCooldown < opts.Cooldown {
+        opts.MaxCooldown = defaultBreakerMaxCooldown
+        if opts.MaxCooldown < opts.Cooldown {
+            opts.MaxCooldown = opts.Cooldown
+        }
+    }
+    return &Breakers{
+        opts:  opts,
+        now:   time.Now,
+        hosts: make(map[string]*BreakerStatus),
+    }
+}
+
+// host returns the breaker for host; b.mu must be held
+func (b *Breakers) host(host string) *BreakerStatus {
+    s, ok := b.hosts[host]
+    if !ok {
+        s = &BreakerStatus{Host: host, Cooldown: b.opts.Cooldown}
+        b.hosts[host] = s
+    }
+    return s
+}
+
+// Allow reports whether a request to host may be made. An open breaker
+// whose cooldown has passed moves to half-open and allows one trial
+// request; others are rejected with an error wrapping ErrCircuitOpen.
+func (b *Breakers) Allow(host string) error {
+    b.mu.Lock()
+    defer b.mu.Unlock()
+
+    s := b.host(host)
+    switch s.State {
+    case BreakerOpen:
+        if b.now().Before(s.RetryAt) {
+            return fmt.Errorf("%w for %s until %s", ErrCircuitOpen, host, s.RetryAt.Format(time.Kitchen))
+        }
+        s.State = BreakerHalfOpen
+        slog.Info("Circuit breaker half-open, sending trial request", "host", host)
+        return nil
+    case BreakerHalfOpen:
+        return fmt.Errorf("%w for %s while a trial request is in flight", ErrCircuitOpen, host)
+    }
+    return nil
+}
+
+// Success records a request the host answered, closing its breaker
+func (b *Breakers) Success(host string) {
+    b.mu.Lock()
+    defer b.mu.Unlock()
+
+    s := b.host(host)
+    if s.State != BreakerClosed {
+        slog.Info("Circuit breaker closed", "host", host, "failures", s.Failures)
+    }
+    *s = BreakerStatus{Host: host, Cooldown: b.opts.Cooldown}
+}
+
+// Failure records a request the host failed. The breaker opens once
+// Threshold requests in a row have failed; a failed trial request reopens
+// it with the cooldown doubled, so a host that stays down is tried less
+// and less often.
+func (b *Breakers) Failure(host string, err error) {
+    b.mu.Lock()
+    defer b.mu.Unlock()
+
+    s := b.host(host)
+    s.Failures++
+    if err != nil {
+        s.LastError = err.Error()
+    }
+
+    switch {
+    case s.State == BreakerHalfOpen:
+        s.Cooldown *= 2
+        if s.Cooldown > b.opts.MaxCooldown {
+            s.Cooldown = b.opts.MaxCooldown
+        }
+        b.open(s)
+    case s.State == BreakerClosed && s.Failures >= b.opts.Threshold:
+        b.open(s)
+    }
+}
+
+// open trips s for its current cooldown; b.mu must be held
+func (b *Breakers) open(s *BreakerStatus) {
+    now := b.now()
+    s.State = BreakerOpen
+    s.OpenedAt = now
+    s.RetryAt = now.Add(s.Cooldown)
+    slog.Warn("Circuit breaker opened",
+        "host", s.Host,
+        "failures", s.Failures,
+        "cooldown", s.Cooldown,
+        "retryAt", s.RetryAt,
+        "error", s.LastError)
+}
+
+// Statuses returns every host's breaker, sorted by host
+func (b *Breakers) Statuses() []BreakerStatus {
+    b.mu.Lock()
+    defer b.mu.Unlock()
+
+    out := make([]BreakerStatus, 0, len(b.hosts))
+    for _, s := range b.hosts {
+        out = append(out, *s)
+    }
+    sort.Slice(out, func(i, j int) bool { return out[i].Host < out[j].Host })
+    return out
+}
@@ -0,0 +1,93 @@
+package util
+
+import (
+    "errors"
+    "testing"
+    "time"
+)
+
+func TestBreakerTransitions(t *testing.T) {
+    now := time.Date(2024, 1, 15, 12, 0, 0, 0, time.UTC)
+    b := NewBreakers(BreakerOptions{Threshold: 2, Cooldown: time.Minute, MaxCooldown: 3 * time.Minute})
+    b.now = func() time.Time { return now }
+
+    const host = "example.com"
+    fail := errors.New("connection refused")
+
+    status := func() BreakerStatus {
+        t.Helper()
+        for _, s := range b.Statuses() {
+            if s.Host == host {
+                return s
+            }
+        }
+        t.Fatalf("no breaker for %s", host)
+        return BreakerStatus{}
+    }
+
+    steps := []struct {
+        name      string
+        advance   time.Duration
+        action    func()
+        wantAllow bool
+        wantState BreakerState
+        wantRetry time.Duration // from now, when open
+    }{
+        {name: "closed allows", wantAllow: true, wantState: BreakerClosed},
+        {name: "below threshold", action: func() { b.Failure(host, fail) }, wantAllow: true, wantState: BreakerClosed},
+        {name: "threshold opens", action: func() { b.Failure(host, fail) }, wantAllow: false, wantState: BreakerOpen, wantRetry: time.Minute},
+        {name: "still cooling down", advance: 30 * time.Second, wantAllow: false, wantState: BreakerOpen, wantRetry: 30 * time.Second},
+        {name: "trial after cooldown", advance: 30 * time.Second, wantAllow: true, wantState: BreakerHalfOpen},
+        {name: "failed trial doubles cooldown", action: func() { b.Failure(host, fail) }, wantAllow: false, wantState: BreakerOpen, wantRetry: 2 * time.Minute},
+        {name: "second trial", advance: 2 * time.Minute, wantAllow: true, wantState: BreakerHalfOpen},
+        {name: "cooldown capped", action: func() { b.Failure(host, fail) }, wantAllow: false, wantState: BreakerOpen, wantRetry: 3 * time.Minute},
+        {name: "third trial", advance: 3 * time.Minute, wantAllow: true, wantState: BreakerHalfOpen},
+        {name: "success closes", action: func() { b.Success(host) }, wantAllow: true, wantState: BreakerClosed},
+    }
+
+    for _, step := range steps {
+        now = now.Add(step.advance)
+        if step.action != nil {
+            step.action()
+        }
+
+        err := b.Allow(host)
+        if allowed := err == nil; allowed != step.wantAllow {
+            t.Fatalf("%s: Allow() = %v, want allowed %v", step.name, err, step.wantAllow)
+        }
+        if err != nil && !errors.Is(err, ErrCircuitOpen) {
+            t.Fatalf("%s: Allow() = %v, want ErrCircuitOpen", step.name, err)
+        }
+
+        s := status()
+        if s.State != step.wantState {
+            t.Fatalf("%s: state = %v, want %v", step.name, s.State, step.wantState)
+        }
+        if s.State == BreakerOpen && s.RetryAt.Sub(now) != step.wantRetry {
+            t.Fatalf("%s: retry in %v, want %v", step.name, s.RetryAt.Sub(now), step.wantRetry)
+        }
+    }
+
+    if s := status(); s.Failures != 0 || s.Cooldown != time.Minute {
+        t.Errorf("after closing: failures %d, cooldown %v; want reset", s.Failures, s.Cooldown)
+    }
+}
+
+func TestBreakerHalfOpenAllowsOneTrial(t *testing.T) {
+    now := time.Date(2024, 1, 15, 12, 0, 0, 0, time.UTC)
+    b := NewBreakers(BreakerOptions{Threshold: 1, Cooldown: time.Minute})
+    b.now = func() time.Time { return now }
+
+    b.Failure("a.example", errors.New("timeout"))
+    now = now.Add(time.Minute)
+
+    if err := b.Allow("a.example"); err != nil {
+        t.Fatalf("first request after cooldown should be allowed: %v", err)
+    }
+    if err := b.Allow("a.example"); !errors.Is(err, ErrCircuitOpen) {
+        t.Errorf("second request during trial = %v, want ErrCircuitOpen", err)
+    }
+    if err := b.Allow("b.example"); err != nil {
+        t.Errorf("other hosts should be unaffected: %v", err)
+    }
+}
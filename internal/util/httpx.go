@@ -1,64 +1,374 @@
 package util
 
 import (
+    "bytes"
     "context"
     "encoding/json"
+    "errors"
     "fmt"
     "io"
     "log/slog"
     "net/http"
+    "net/url"
+    "strings"
+    "sync"
     "time"
 )
 
-var client = &http.Client{ Timeout: 10 * time.Second }
+// maxCachedBody bounds the size of a response kept for conditional requests
+const maxCachedBody = 4 << 20
+
+// ErrNotModified is returned by scrapers when a conditional request shows
+// the source hasn't changed since it was last fetched
+var ErrNotModified = errors.New("not modified")
+
+// DefaultUserAgent identifies the bot to the sites it scrapes
+const DefaultUserAgent = "sjsu-badminton-bot/1.0"
+
+// defaultRequestTimeout bounds each request made by a Client built without a Doer
+const defaultRequestTimeout = 10 * time.Second
+
+const (
+    // maxAttempts is how many times Get tries a request before giving up
+    maxAttempts      = 3
+    defaultRetryWait = 250 * time.Millisecond
+)
 
 type Doer interface{ Do(*http.Request) (*http.Response, error) }
 
-func Get(ctx context.Context, url string) (*http.Response, error) {
-    req, err := http.NewRequestWithContext(ctx, http.MethodGet, url, nil)
+// cachedResponse is the last successful response for a URL along with the
+// validators needed to revalidate it
+type cachedResponse struct {
+    etag         string
+    lastModified string
+    header       http.Header
+    body         []byte
+}
+
+// ClientOptions customises the requests a Client sends
+type ClientOptions struct {
+    // UserAgent defaults to DefaultUserAgent
+    UserAgent string
+    // Header is sent with every request, overriding the default Accept header
+    Header http.Header
+    // Breakers, if set, stops requests to hosts that keep failing. Clients
+    // may share one set so every request to a host counts.
+    Breakers *Breakers
+    // Jar, if set, keeps cookies between requests, whatever the Doer
+    Jar http.CookieJar
+}
+
+// Client issues requests with retries, failing fast while a host's
+// circuit breaker is open. When a server sends an ETag or
+// Last-Modified header the response to a Get is cached by URL, later Gets
+// are made conditional, and a 304 Not Modified is answered from the cache.
+type Client struct {
+    doer      Doer
+    userAgent string
+    header    http.Header
+    breakers  *Breakers
+    jar       http.CookieJar
+    backoff   time.Duration
+
+    mu    sync.Mutex
+    cache map[string]*cachedResponse
+}
+
+// NewClient returns a Client that sends requests through d, or through an
+// http.Client with a 10 second timeout if d is nil
+func NewClient(d Doer, opts ClientOptions) *Client {
+    if d == nil {
+        d = &http.Client{Timeout: defaultRequestTimeout}
+    }
+    if opts.UserAgent == "" {
+        opts.UserAgent = DefaultUserAgent
+    }
+    return &Client{
+        doer:      d,
+        userAgent: opts.UserAgent,
+        header:    opts.Header.Clone(),
+        breakers:  opts.Breakers,
+        jar:       opts.Jar,
+        backoff:   defaultRetryWait,
+        cache:     make(map[string]*cachedResponse),
+    }
+}
+
+// Get fetches url, retrying network errors and server errors. If the server
+// reports the cached copy is still current, the returned response has status
+// 304 and the cached headers and body, so callers can skip re-processing it.
+// Requests to a host whose breaker is open fail with ErrCircuitOpen.
+func (c *Client) Get(ctx context.Context, url string) (*http.Response, error) {
+    return c.GetWithHeader(ctx, url, nil)
+}
+
+// GetWithHeader is Get with header added to the client's headers. The cached
+// copy is still keyed by url alone.
+func (c *Client) GetWithHeader(ctx context.Context, url string, header http.Header) (*http.Response, error) {
+    req, err := c.newRequest(ctx, http.MethodGet, url, nil)
+    if err != nil {
+        return nil, err
+    }
+    for k, v := range header {
+        req.Header[k] = v
+    }
+
+    cached := c.cached(url)
+    if cached != nil {
+        if cached.etag != "" {
+            req.Header.Set("If-None-Match", cached.etag)
+        }
+        if cached.lastModified != "" {
+            req.Header.Set("If-Modified-Since", cached.lastModified)
+        }
+    }
+
+    resp, err := c.send(ctx, req)
+    if err != nil {
+        return nil, err
+    }
+
+    if resp.StatusCode == http.StatusNotModified && cached != nil {
+        resp.Body.Close()
+        slog.Debug("Resource not modified, using cached copy", "url", url)
+        return cachedHTTPResponse(req, cached), nil
+    }
+
+    if resp.StatusCode >= 400 {
+        resp.Body.Close()
+        return nil, fmt.Errorf("HTTP %d: %s", resp.StatusCode, resp.Status)
+    }
+
+    if resp.StatusCode == http.StatusOK {
+        if err := c.store(url, resp); err != nil {
+            return nil, err
+        }
+    }
+
+    return resp, nil
+}
+
+// Send makes an unconditional request, retrying network errors and server
+// errors like Get. A non-nil form is sent as a URL-encoded body; header is
+// added to the client's headers. Error statuses are returned as errors.
+func (c *Client) Send(ctx context.Context, method, url string, form url.Values, header http.Header) (*http.Response, error) {
+    var body io.Reader
+    if form != nil {
+        body = strings.NewReader(form.Encode())
+    }
+
+    req, err := c.newRequest(ctx, method, url, body)
+    if err != nil {
+        return nil, err
+    }
+    if form != nil {
+        req.Header.Set("Content-Type", "application/x-www-form-urlencoded")
+    }
+    for k, v := range header {
+        req.Header[k] = v
+    }
+
+    resp, err := c.send(ctx, req)
+    if err != nil {
+        return nil, err
+    }
+    if resp.StatusCode >= 400 {
+        resp.Body.Close()
+        return nil, fmt.Errorf("HTTP %d: %s", resp.StatusCode, resp.Status)
+    }
+    return resp, nil
+}
+
+// newRequest builds a request carrying the client's headers and cookies
+func (c *Client) newRequest(ctx context.Context, method, url string, body io.Reader) (*http.Request, error) {
+    req, err := http.NewRequestWithContext(ctx, method, url, body)
     if err != nil {
         return nil, fmt.Errorf("creating request: %w", err)
     }
-    
-    req.Header.Set("User-Agent", "sjsu-badminton-bot/1.0")
+
+    req.Header.Set("User-Agent", c.userAgent)
     req.Header.Set("Accept", "application/json, text/html, */*")
+    for k, v := range c.header {
+        req.Header[k] = v
+    }
+    if c.jar != nil {
+        for _, cookie := range c.jar.Cookies(req.URL) {
+            req.AddCookie(cookie)
+        }
+    }
+    return req, nil
+}
 
-    var resp *http.Response
-    backoff := 250 * time.Millisecond
-    
-    for i := 0; i < 3; i++ {
-        resp, err = client.Do(req)
-        if err == nil && resp.StatusCode < 500 { 
-            break 
+// send checks the host's breaker, makes the request with retries, records
+// the outcome on the breaker and keeps any cookies set
+func (c *Client) send(ctx context.Context, req *http.Request) (*http.Response, error) {
+    if c.breakers != nil {
+        if err := c.breakers.Allow(req.URL.Host); err != nil {
+            return nil, err
         }
-        
-        if resp != nil {
+    }
+
+    resp, err := c.do(ctx, req)
+    if c.breakers != nil {
+        switch {
+        case err != nil:
+            c.breakers.Failure(req.URL.Host, err)
+        case resp.StatusCode == http.StatusTooManyRequests:
+            c.breakers.Failure(req.URL.Host, fmt.Errorf("HTTP %d: %s", resp.StatusCode, resp.Status))
+        default:
+            c.breakers.Success(req.URL.Host)
+        }
+    }
+    if err != nil {
+        return nil, err
+    }
+
+    if c.jar != nil {
+        if cookies := resp.Cookies(); len(cookies) > 0 {
+            c.jar.SetCookies(req.URL, cookies)
+        }
+    }
+    return resp, nil
+}
+
+// do sends req, retrying network errors and server errors with exponential
+// backoff. Waits between attempts end early when ctx is done.
+func (c *Client) do(ctx context.Context, req *http.Request) (*http.Response, error) {
+    backoff := c.backoff
+    for attempt := 1; ; attempt++ {
+        resp, err := c.doer.Do(req)
+        if err == nil && resp.StatusCode < 500 {
+            return resp, nil
+        }
+        if err == nil {
             resp.Body.Close()
+            err = fmt.Errorf("HTTP %d: %s", resp.StatusCode, resp.Status)
+        }
+        if attempt >= maxAttempts {
+            return nil, fmt.Errorf("request failed after retries: %w", err)
         }
-        
-        slog.Warn("HTTP request failed, retrying", 
-            "attempt", i+1, 
-            "url", url, 
+
+        slog.Warn("HTTP request failed, retrying",
+            "attempt", attempt,
+            "url", req.URL.String(),
             "error", err,
-            "status", func() int {
-                if resp != nil { return resp.StatusCode }
-                return 0
-            }())
-        
-        time.Sleep(backoff)
+            "retryIn", backoff)
+
+        if serr := SleepContext(ctx, backoff); serr != nil {
+            return nil, fmt.Errorf("request abandoned after %d attempts (%v): %w", attempt, err, serr)
+        }
         backoff *= 2
+
+        // The previous attempt consumed the body
+        if req.GetBody != nil {
+            body, berr := req.GetBody()
+            if berr != nil {
+                return nil, fmt.Errorf("rewinding request body: %w", berr)
+            }
+            req.Body = body
+        }
+    }
+}
+
+// SleepContext waits for d, returning ctx's error if it is done first
+func SleepContext(ctx context.Context, d time.Duration) error {
+    t := time.NewTimer(d)
+    defer t.Stop()
+
+    select {
+    case <-ctx.Done():
+        return ctx.Err()
+    case <-t.C:
+        return nil
     }
-    
-    if err != nil { 
-        return nil, fmt.Errorf("request failed after retries: %w", err) 
+}
+
+func (c *Client) cached(url string) *cachedResponse {
+    c.mu.Lock()
+    defer c.mu.Unlock()
+    return c.cache[url]
+}
+
+// store caches a response that carries validators, replacing its body with
+// the buffered copy. Responses without validators or too large to keep drop
+// any cache entry so the next request is unconditional.
+func (c *Client) store(url string, resp *http.Response) error {
+    etag := resp.Header.Get("ETag")
+    lastModified := resp.Header.Get("Last-Modified")
+    if etag == "" && lastModified == "" {
+        c.forget(url)
+        return nil
     }
-    
-    if resp.StatusCode >= 400 { 
+
+    body, err := io.ReadAll(io.LimitReader(resp.Body, maxCachedBody+1))
+    if err != nil {
         resp.Body.Close()
-        return nil, fmt.Errorf("HTTP %d: %s", resp.StatusCode, resp.Status) 
+        c.forget(url)
+        return fmt.Errorf("reading response: %w", err)
+    }
+    if len(body) > maxCachedBody {
+        // Too large to keep; hand back what was read followed by the rest
+        resp.Body = struct {
+            io.Reader
+            io.Closer
+        }{io.MultiReader(bytes.NewReader(body), resp.Body), resp.Body}
+        c.forget(url)
+        return nil
+    }
+
+    resp.Body.Close()
+    resp.Body = io.NopCloser(bytes.NewReader(body))
+
+    c.mu.Lock()
+    c.cache[url] = &cachedResponse{
+        etag:         etag,
+        lastModified: lastModified,
+        header:       resp.Header.Clone(),
+        body:         body,
+    }
+    c.mu.Unlock()
+    return nil
+}
+
+func (c *Client) forget(url string) {
+    c.mu.Lock()
+    delete(c.cache, url)
+    c.mu.Unlock()
+}
+
+// Retain drops the cached responses of every URL not in keep, for callers
+// whose URLs change over time, like a date range in the query string
+func (c *Client) Retain(keep map[string]bool) {
+    c.mu.Lock()
+    defer c.mu.Unlock()
+
+    for url := range c.cache {
+        if !keep[url] {
+            delete(c.cache, url)
+        }
+    }
+}
+
+// CacheLen returns the number of cached responses
+func (c *Client) CacheLen() int {
+    c.mu.Lock()
+    defer c.mu.Unlock()
+    return len(c.cache)
+}
+
+// cachedHTTPResponse builds a 304 response carrying the cached copy
+func cachedHTTPResponse(req *http.Request, cached *cachedResponse) *http.Response {
+    return &http.Response{
+        Status:        "304 Not Modified",
+        StatusCode:    http.StatusNotModified,
+        Proto:         "HTTP/1.1",
+        ProtoMajor:    1,
+        ProtoMinor:    1,
+        Header:        cached.header.Clone(),
+        Body:          io.NopCloser(bytes.NewReader(cached.body)),
+        ContentLength: int64(len(cached.body)),
+        Request:       req,
     }
-    
-    return resp, nil
 }
 
 func DecodeJSON(r io.Reader, v any) error {
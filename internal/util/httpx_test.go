@@ -0,0 +1,235 @@
+package util
+
+import (
+    "context"
+    "errors"
+    "io"
+    "net/http"
+    "net/http/cookiejar"
+    "net/http/httptest"
+    "net/url"
+    "testing"
+    "time"
+)
+
+func TestClientConditionalGet(t *testing.T) {
+    testCases := []struct {
+        name       string
+        validator  string // response header carrying the validator
+        value      string
+        condHeader string // request header expected on revalidation
+    }{
+        {name: "etag", validator: "ETag", value: `"v1"`, condHeader: "If-None-Match"},
+        {name: "last modified", validator: "Last-Modified", value: "Mon, 15 Jan 2024 14:30:00 GMT", condHeader: "If-Modified-Since"},
+    }
+
+    for _, tc := range testCases {
+        t.Run(tc.name, func(t *testing.T) {
+            requests := 0
+            srv := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+                requests++
+                if r.Header.Get(tc.condHeader) == tc.value {
+                    w.WriteHeader(http.StatusNotModified)
+                    return
+                }
+                w.Header().Set(tc.validator, tc.value)
+                w.Header().Set("Content-Type", "application/json")
+                io.WriteString(w, `{"success":true}`)
+            }))
+            defer srv.Close()
+
+            c := NewClient(srv.Client(), ClientOptions{})
+
+            resp, err := c.Get(context.Background(), srv.URL)
+            if err != nil {
+                t.Fatalf("first Get: %v", err)
+            }
+            body, _ := io.ReadAll(resp.Body)
+            resp.Body.Close()
+            if resp.StatusCode != http.StatusOK || string(body) != `{"success":true}` {
+                t.Fatalf("Unexpected first response %d %q", resp.StatusCode, body)
+            }
+
+            resp, err = c.Get(context.Background(), srv.URL)
+            if err != nil {
+                t.Fatalf("second Get: %v", err)
+            }
+            body, _ = io.ReadAll(resp.Body)
+            resp.Body.Close()
+            if resp.StatusCode != http.StatusNotModified {
+                t.Errorf("Expected 304 on revalidation, got %d", resp.StatusCode)
+            }
+            if string(body) != `{"success":true}` {
+                t.Errorf("Expected the cached body, got %q", body)
+            }
+            if ct := resp.Header.Get("Content-Type"); ct != "application/json" {
+                t.Errorf("Expected the cached Content-Type, got %q", ct)
+            }
+            if requests != 2 {
+                t.Errorf("Expected 2 requests, got %d", requests)
+            }
+        })
+    }
+}
+
+func TestClientWithoutValidatorsIsUnconditional(t *testing.T) {
+    srv := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+        if r.Header.Get("If-None-Match") != "" || r.Header.Get("If-Modified-Since") != "" {
+            t.Errorf("Unexpected conditional request headers: %v", r.Header)
+        }
+        io.WriteString(w, "hello")
+    }))
+    defer srv.Close()
+
+    c := NewClient(srv.Client(), ClientOptions{})
+    for i := 0; i < 2; i++ {
+        resp, err := c.Get(context.Background(), srv.URL)
+        if err != nil {
+            t.Fatalf("Get: %v", err)
+        }
+        body, _ := io.ReadAll(resp.Body)
+        resp.Body.Close()
+        if resp.StatusCode != http.StatusOK || string(body) != "hello" {
+            t.Errorf("Unexpected response %d %q", resp.StatusCode, body)
+        }
+    }
+}
+
+func TestClientOptionsHeaders(t *testing.T) {
+    testCases := []struct {
+        name      string
+        opts      ClientOptions
+        wantUA    string
+        wantExtra string
+    }{
+        {name: "defaults", opts: ClientOptions{}, wantUA: DefaultUserAgent},
+        {
+            name:      "custom",
+            opts:      ClientOptions{UserAgent: "custom-agent", Header: http.Header{"X-Api-Key": {"secret"}}},
+            wantUA:    "custom-agent",
+            wantExtra: "secret",
+        },
+    }
+
+    for _, tc := range testCases {
+        t.Run(tc.name, func(t *testing.T) {
+            var gotUA, gotExtra string
+            srv := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+                gotUA = r.Header.Get("User-Agent")
+                gotExtra = r.Header.Get("X-Api-Key")
+            }))
+            defer srv.Close()
+
+            resp, err := NewClient(srv.Client(), tc.opts).Get(context.Background(), srv.URL)
+            if err != nil {
+                t.Fatalf("Get: %v", err)
+            }
+            resp.Body.Close()
+
+            if gotUA != tc.wantUA {
+                t.Errorf("User-Agent = %q, want %q", gotUA, tc.wantUA)
+            }
+            if gotExtra != tc.wantExtra {
+                t.Errorf("X-Api-Key = %q, want %q", gotExtra, tc.wantExtra)
+            }
+        })
+    }
+}
+
+func TestClientBreakerStopsRequests(t *testing.T) {
+    requests := 0
+    srv := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+        requests++
+        http.Error(w, "down", http.StatusServiceUnavailable)
+    }))
+    defer srv.Close()
+
+    breakers := NewBreakers(BreakerOptions{Threshold: 2, Cooldown: time.Hour})
+    c := NewClient(srv.Client(), ClientOptions{Breakers: breakers})
+    c.backoff = time.Millisecond
+
+    for i := 0; i < 2; i++ {
+        if _, err := c.Get(context.Background(), srv.URL); err == nil || errors.Is(err, ErrCircuitOpen) {
+            t.Fatalf("Get %d: want a server error, got %v", i+1, err)
+        }
+    }
+    if requests != 2*maxAttempts {
+        t.Fatalf("Expected %d requests before the breaker opened, got %d", 2*maxAttempts, requests)
+    }
+
+    if _, err := c.Get(context.Background(), srv.URL); !errors.Is(err, ErrCircuitOpen) {
+        t.Errorf("Get with open breaker = %v, want ErrCircuitOpen", err)
+    }
+    if requests != 2*maxAttempts {
+        t.Errorf("Open breaker should not send requests, got %d", requests)
+    }
+}
+
+func TestClientRetryWaitHonoursContext(t *testing.T) {
+    srv := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+        http.Error(w, "down", http.StatusInternalServerError)
+    }))
+    defer srv.Close()
+
+    c := NewClient(srv.Client(), ClientOptions{})
+    c.backoff = time.Hour
+
+    ctx, cancel := context.WithTimeout(context.Background(), 50*time.Millisecond)
+    defer cancel()
+
+    start := time.Now()
+    _, err := c.Get(ctx, srv.URL)
+    if !errors.Is(err, context.DeadlineExceeded) {
+        t.Errorf("Get = %v, want context.DeadlineExceeded", err)
+    }
+    if elapsed := time.Since(start); elapsed > 5*time.Second {
+        t.Errorf("Get waited %v despite the context deadline", elapsed)
+    }
+}
+
+func TestClientSendRetriesFormAndKeepsCookies(t *testing.T) {
+    var bodies []string
+    var sessions []string
+    srv := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+        if r.URL.Path == "/login" {
+            http.SetCookie(w, &http.Cookie{Name: "session", Value: "abc", Path: "/"})
+            return
+        }
+
+        r.ParseForm()
+        bodies = append(bodies, r.PostForm.Encode())
+        if cookie, err := r.Cookie("session"); err == nil {
+            sessions = append(sessions, cookie.Value)
+        }
+        if len(bodies) == 1 {
+            http.Error(w, "busy", http.StatusServiceUnavailable)
+        }
+    }))
+    defer srv.Close()
+
+    jar, err := cookiejar.New(nil)
+    if err != nil {
+        t.Fatal(err)
+    }
+    c := NewClient(srv.Client(), ClientOptions{Jar: jar})
+    c.backoff = time.Millisecond
+
+    resp, err := c.Send(context.Background(), http.MethodGet, srv.URL+"/login", nil, nil)
+    if err != nil {
+        t.Fatalf("Send login: %v", err)
+    }
+    resp.Body.Close()
+
+    resp, err = c.Send(context.Background(), http.MethodPost, srv.URL+"/schedule", url.Values{"start": {"2024-01-15"}}, nil)
+    if err != nil {
+        t.Fatalf("Send schedule: %v", err)
+    }
+    resp.Body.Close()
+
+    if len(bodies) != 2 || bodies[0] != "start=2024-01-15" || bodies[1] != bodies[0] {
+        t.Errorf("Expected the form on both attempts, got %q", bodies)
+    }
+    if len(sessions) != 2 || sessions[0] != "abc" || sessions[1] != "abc" {
+        t.Errorf("Expected the session cookie on both attempts, got %q", sessions)
+    }
+}
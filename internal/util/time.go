@@ -1,6 +1,7 @@
 package util
 
 import (
+    "strings"
     "time"
 )
 
@@ -9,3 +10,23 @@ func MustLocation(name string) *time.Location {
     if err != nil { return time.FixedZone(name, -8*3600) }
     return loc
 }
+
+// ParseWeekday parses a full or three-letter day name ("monday", "Mon")
+func ParseWeekday(s string) (time.Weekday, bool) {
+    s = strings.ToLower(strings.TrimSpace(s))
+    if len(s) < 3 {
+        return 0, false
+    }
+    for d := time.Sunday; d <= time.Saturday; d++ {
+        name := strings.ToLower(d.String())
+        if s == name || s == name[:3] {
+            return d, true
+        }
+    }
+    return 0, false
+}
+
+// StartOfDay returns midnight of t's day in t's location
+func StartOfDay(t time.Time) time.Time {
+    return time.Date(t.Year(), t.Month(), t.Day(), 0, 0, 0, 0, t.Location())
+}
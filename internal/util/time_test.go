@@ -0,0 +1,31 @@
+package util
+
+import (
+    "testing"
+    "time"
+)
+
+func TestParseWeekday(t *testing.T) {
+    testCases := []struct {
+        input    string
+        expected time.Weekday
+        ok       bool
+    }{
+        {"monday", time.Monday, true},
+        {"Mon", time.Monday, true},
+        {" SATURDAY ", time.Saturday, true},
+        {"sun", time.Sunday, true},
+        {"mo", 0, false},
+        {"someday", 0, false},
+        {"", 0, false},
+    }
+
+    for _, tc := range testCases {
+        t.Run(tc.input, func(t *testing.T) {
+            day, ok := ParseWeekday(tc.input)
+            if ok != tc.ok || (ok && day != tc.expected) {
+                t.Errorf("ParseWeekday(%q) = (%v, %v), expected (%v, %v)", tc.input, day, ok, tc.expected, tc.ok)
+            }
+        })
+    }
+}